@@ -0,0 +1,171 @@
+// Package airlines holds the bundled airline registry. It resolves the many ways
+// providers spell an airline ("Delta", "Delta Air Lines", "DL", "DAL") to a single
+// record, and splits flight numbers into carrier code and number.
+package airlines
+
+import (
+	"FlightAPI/models"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed data/airlines.csv
+var embeddedAirlines []byte
+
+// flightNumberPattern matches a 3 letter ICAO or 2 character IATA designator
+// (never two digits) followed by up to 4 digits and an optional operational suffix.
+var flightNumberPattern = regexp.MustCompile(`^([A-Z]{3}|[A-Z][A-Z0-9]|[0-9][A-Z])\s*([0-9]{1,4}[A-Z]?)$`)
+
+// Registry is an in-memory, read-only index of airlines.
+// It is safe for concurrent use once loaded.
+type Registry struct {
+	airlines []models.Airline
+	byCode   map[string]int
+	byName   map[string]int
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+)
+
+// Default returns the registry built from the airline dataset embedded in the binary.
+// The dataset is parsed on the first call, so main calls it on startup to fail fast.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		registry, err := Load(bytes.NewReader(embeddedAirlines))
+		if err != nil {
+			// The dataset is compiled in, so this can only happen with a broken build.
+			log.Fatalf("Failed to load embedded airline data: %v", err)
+		}
+		defaultRegistry = registry
+	})
+	return defaultRegistry
+}
+
+// Load reads airlines from a CSV with the header iata,icao,name,alliance,country,aliases.
+// Aliases are separated by "|".
+func Load(r io.Reader) (*Registry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 6
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	registry := &Registry{
+		byCode: make(map[string]int),
+		byName: make(map[string]int),
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read airline: %w", err)
+		}
+
+		airline := models.Airline{
+			Code:     strings.ToUpper(record[0]),
+			ICAO:     strings.ToUpper(record[1]),
+			Name:     record[2],
+			Alliance: record[3],
+			Country:  record[4],
+		}
+
+		if _, exists := registry.byCode[airline.Code]; exists {
+			return nil, fmt.Errorf("duplicate airline code %s", airline.Code)
+		}
+		if _, exists := registry.byCode[airline.ICAO]; exists && airline.ICAO != "" {
+			return nil, fmt.Errorf("duplicate airline code %s", airline.ICAO)
+		}
+
+		registry.airlines = append(registry.airlines, airline)
+		index := len(registry.airlines) - 1
+		registry.byCode[airline.Code] = index
+		// Not every airline has an ICAO designator
+		if airline.ICAO != "" {
+			registry.byCode[airline.ICAO] = index
+		}
+		registry.byName[nameKey(airline.Name)] = index
+		for _, alias := range strings.Split(record[5], "|") {
+			if alias != "" {
+				registry.byName[nameKey(alias)] = index
+			}
+		}
+	}
+
+	return registry, nil
+}
+
+// All returns every airline in the registry, in dataset order.
+func (r *Registry) All() []models.Airline {
+	return append([]models.Airline(nil), r.airlines...)
+}
+
+// Lookup finds an airline by its IATA or ICAO designator.
+func (r *Registry) Lookup(code string) (models.Airline, bool) {
+	if i, ok := r.byCode[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return r.airlines[i], true
+	}
+	return models.Airline{}, false
+}
+
+// Resolve finds an airline from any of the ways providers refer to it:
+// IATA or ICAO designator, official name or a known alias.
+func (r *Registry) Resolve(value string) (models.Airline, bool) {
+	if airline, ok := r.Lookup(value); ok {
+		return airline, true
+	}
+	if i, ok := r.byName[nameKey(value)]; ok {
+		return r.airlines[i], true
+	}
+	return models.Airline{}, false
+}
+
+// SplitFlightNumber splits a flight number such as "DL123", "dl 0123" or "DAL123"
+// into the carrier designator and the number without leading zeros.
+// ICAO designators are translated to IATA when the airline is known.
+func (r *Registry) SplitFlightNumber(flightNumber string) (carrier string, number string, ok bool) {
+	matches := flightNumberPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(flightNumber)))
+	if matches == nil {
+		return "", "", false
+	}
+
+	carrier = matches[1]
+	if len(carrier) == 3 {
+		if airline, found := r.Lookup(carrier); found {
+			carrier = airline.Code
+		}
+	}
+
+	number = strings.TrimLeft(matches[2], "0")
+	if number == "" || !unicode.IsDigit(rune(number[0])) {
+		// Keep a single zero for flight "0" and suffixed numbers such as "0A"
+		number = "0" + number
+	}
+	return carrier, number, true
+}
+
+// nameKey reduces an airline name to lowercase letters and digits so that
+// "Delta Air Lines", "Delta Airlines" and "DELTA AIR-LINES" share a key.
+func nameKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package airlines
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	registry := Default()
+
+	tests := []struct {
+		value        string
+		expectFound  bool
+		expectedCode string
+	}{
+		{value: "Delta", expectFound: true, expectedCode: "DL"},
+		{value: "Delta Air Lines", expectFound: true, expectedCode: "DL"},
+		{value: "DELTA AIRLINES", expectFound: true, expectedCode: "DL"},
+		{value: "dl", expectFound: true, expectedCode: "DL"},
+		{value: "DAL", expectFound: true, expectedCode: "DL"},
+		{value: "Oceanic Airlines", expectFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			airline, ok := registry.Resolve(tt.value)
+			assert.Equal(t, tt.expectFound, ok)
+			assert.Equal(t, tt.expectedCode, airline.Code)
+		})
+	}
+}
+
+func TestSplitFlightNumber(t *testing.T) {
+	registry := Default()
+
+	tests := []struct {
+		flightNumber    string
+		expectOK        bool
+		expectedCarrier string
+		expectedNumber  string
+	}{
+		{flightNumber: "DL123", expectOK: true, expectedCarrier: "DL", expectedNumber: "123"},
+		{flightNumber: "dl 0123", expectOK: true, expectedCarrier: "DL", expectedNumber: "123"},
+		{flightNumber: "DLH400", expectOK: true, expectedCarrier: "LH", expectedNumber: "400"},
+		{flightNumber: "U21234", expectOK: true, expectedCarrier: "U2", expectedNumber: "1234"},
+		{flightNumber: "6E2001A", expectOK: true, expectedCarrier: "6E", expectedNumber: "2001A"},
+		{flightNumber: "12345", expectOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flightNumber, func(t *testing.T) {
+			carrier, number, ok := registry.SplitFlightNumber(tt.flightNumber)
+			assert.Equal(t, tt.expectOK, ok)
+			assert.Equal(t, tt.expectedCarrier, carrier)
+			assert.Equal(t, tt.expectedNumber, number)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	header := "iata,icao,name,alliance,country,aliases\n"
+
+	registry, err := Load(strings.NewReader(header + "DL,DAL,Delta Air Lines,SkyTeam,US,Delta\nXX,,No ICAO Air,,US,\n"))
+	require.NoError(t, err)
+	airline, ok := registry.Lookup("XX")
+	assert.True(t, ok)
+	assert.Equal(t, "No ICAO Air", airline.Name)
+	// An airline without an ICAO designator can't be found by an empty code
+	_, ok = registry.Lookup("")
+	assert.False(t, ok)
+
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{name: "duplicate IATA code", csv: "DL,DAL,Delta Air Lines,SkyTeam,US,\nDL,DLA,Other,,US,\n"},
+		{name: "duplicate ICAO code", csv: "DL,DAL,Delta Air Lines,SkyTeam,US,\nXX,DAL,Other,,US,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(header + tt.csv))
+			assert.ErrorContains(t, err, "duplicate airline code")
+		})
+	}
+}
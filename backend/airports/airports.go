@@ -0,0 +1,269 @@
+// Package airports holds the bundled airport reference data. It is used to look
+// up airports by code, to power the autocomplete endpoint and to enrich the airport
+// data we receive from providers, which is often incomplete or inconsistent.
+package airports
+
+import (
+	"FlightAPI/models"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/airports.csv
+var embeddedAirports []byte
+
+//go:embed data/metros.csv
+var embeddedMetros []byte
+
+// Database is an in-memory, read-only index of airports.
+// It is safe for concurrent use once loaded.
+type Database struct {
+	airports []models.Airport
+	byIATA   map[string]int
+	byICAO   map[string]int
+	metros   map[string]Metro
+}
+
+var (
+	defaultOnce sync.Once
+	defaultDB   *Database
+)
+
+// Default returns the database built from the airport dataset embedded in the binary.
+// The dataset is parsed on the first call, so main calls it on startup to fail fast.
+func Default() *Database {
+	defaultOnce.Do(func() {
+		db, err := Load(bytes.NewReader(embeddedAirports))
+		if err == nil {
+			err = db.LoadMetros(bytes.NewReader(embeddedMetros))
+		}
+		if err != nil {
+			// The dataset is compiled in, so this can only happen with a broken build.
+			log.Fatalf("Failed to load embedded airport data: %v", err)
+		}
+		defaultDB = db
+	})
+	return defaultDB
+}
+
+// Load reads airports from a CSV with the header
+// iata,icao,name,city,country,latitude,longitude,timezone.
+func Load(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 8
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	db := &Database{
+		byIATA: make(map[string]int),
+		byICAO: make(map[string]int),
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read airport: %w", err)
+		}
+
+		lat, err := strconv.ParseFloat(record[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude for %s: %w", record[0], err)
+		}
+		lon, err := strconv.ParseFloat(record[6], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude for %s: %w", record[0], err)
+		}
+
+		airport := models.Airport{
+			Code:      strings.ToUpper(record[0]),
+			ICAO:      strings.ToUpper(record[1]),
+			Name:      record[2],
+			City:      record[3],
+			Country:   record[4],
+			Latitude:  lat,
+			Longitude: lon,
+			Timezone:  record[7],
+		}
+
+		if _, exists := db.byIATA[airport.Code]; exists {
+			return nil, fmt.Errorf("duplicate airport code %s", airport.Code)
+		}
+
+		db.airports = append(db.airports, airport)
+		db.byIATA[airport.Code] = len(db.airports) - 1
+		if airport.ICAO != "" {
+			db.byICAO[airport.ICAO] = len(db.airports) - 1
+		}
+	}
+
+	return db, nil
+}
+
+// Len returns the number of airports in the database.
+func (db *Database) Len() int {
+	return len(db.airports)
+}
+
+// Lookup finds an airport by its IATA (3 letter) or ICAO (4 letter) code.
+func (db *Database) Lookup(code string) (models.Airport, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if i, ok := db.byIATA[code]; ok {
+		return db.airports[i], true
+	}
+	if i, ok := db.byICAO[code]; ok {
+		return db.airports[i], true
+	}
+	return models.Airport{}, false
+}
+
+// Enrich replaces the provider's airport data with our reference record when the code is known.
+// Unknown airports are returned as received, with the code normalized.
+// The second return value reports whether the airport was found.
+func (db *Database) Enrich(airport models.Airport) (models.Airport, bool) {
+	known, ok := db.Lookup(airport.Code)
+	if !ok {
+		airport.Code = strings.ToUpper(strings.TrimSpace(airport.Code))
+		return airport, false
+	}
+	return known, true
+}
+
+// Search returns up to limit airports matching the query, best matches first.
+// Codes and city or name prefixes rank above substring matches, and small typos
+// in the city or name are tolerated as a last resort.
+func (db *Database) Search(query string, limit int) []models.Airport {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return nil
+	}
+
+	type match struct {
+		index int
+		score int
+	}
+
+	var matches []match
+	for i, airport := range db.airports {
+		if score, ok := matchScore(airport, query); ok {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+
+	// Sort by score, then by code so results are stable
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return db.airports[matches[i].index].Code < db.airports[matches[j].index].Code
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]models.Airport, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, db.airports[m.index])
+	}
+	return results
+}
+
+// matchScore ranks how well an airport matches the query. Lower is better.
+func matchScore(airport models.Airport, query string) (int, bool) {
+	code := strings.ToLower(airport.Code)
+	icao := strings.ToLower(airport.ICAO)
+	city := strings.ToLower(airport.City)
+	name := strings.ToLower(airport.Name)
+
+	switch {
+	case code == query || icao == query:
+		return 0, true
+	case strings.HasPrefix(code, query) || strings.HasPrefix(icao, query):
+		return 1, true
+	case strings.HasPrefix(city, query):
+		return 2, true
+	case hasWordPrefix(name, query):
+		return 3, true
+	case strings.Contains(city, query) || strings.Contains(name, query):
+		return 4, true
+	}
+
+	// Fuzzy matching is only useful once the user typed enough characters
+	if len(query) < 4 {
+		return 0, false
+	}
+	maxEdits := 1
+	if len(query) > 6 {
+		maxEdits = 2
+	}
+
+	best := maxEdits + 1
+	queryLen := len([]rune(query))
+	candidates := append([]string{city}, strings.Fields(name)...)
+	for _, candidate := range candidates {
+		// Compare against the whole word and against prefixes around the query length,
+		// so partial input with a missing or extra character still matches
+		runes := []rune(candidate)
+		for _, n := range []int{len(runes), queryLen - 1, queryLen, queryLen + 1} {
+			if n <= 0 || n > len(runes) {
+				continue
+			}
+			if d := levenshtein(query, string(runes[:n])); d < best {
+				best = d
+			}
+		}
+	}
+	if best > maxEdits {
+		return 0, false
+	}
+	return 5 + best, true
+}
+
+// hasWordPrefix reports whether any word in s starts with prefix.
+func hasWordPrefix(s, prefix string) bool {
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '/'
+	}) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
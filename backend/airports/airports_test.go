@@ -0,0 +1,121 @@
+package airports
+
+import (
+	"FlightAPI/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	db := Default()
+
+	tests := []struct {
+		name         string
+		code         string
+		expectFound  bool
+		expectedIATA string
+	}{
+		{name: "IATA code", code: "JNB", expectFound: true, expectedIATA: "JNB"},
+		{name: "lowercase IATA code", code: "atl", expectFound: true, expectedIATA: "ATL"},
+		{name: "ICAO code", code: "KJFK", expectFound: true, expectedIATA: "JFK"},
+		{name: "unknown code", code: "ZZZ", expectFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			airport, ok := db.Lookup(tt.code)
+			assert.Equal(t, tt.expectFound, ok)
+			if tt.expectFound {
+				assert.Equal(t, tt.expectedIATA, airport.Code)
+				assert.NotEmpty(t, airport.Timezone)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	db := Default()
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedFirst string
+	}{
+		{name: "exact code ranks first", query: "lhr", expectedFirst: "LHR"},
+		{name: "city prefix", query: "johan", expectedFirst: "JNB"},
+		{name: "name word prefix", query: "schiphol", expectedFirst: "AMS"},
+		{name: "typo in city", query: "atlnta", expectedFirst: "ATL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := db.Search(tt.query, 5)
+			if assert.NotEmpty(t, results) {
+				assert.Equal(t, tt.expectedFirst, results[0].Code)
+			}
+		})
+	}
+
+	assert.Len(t, db.Search("london", 2), 2)
+	assert.Empty(t, db.Search("", 10))
+}
+
+func TestEnrich(t *testing.T) {
+	db := Default()
+
+	enriched, ok := db.Enrich(models.Airport{Code: "jnb", Name: "Joburg"})
+	assert.True(t, ok)
+	assert.Equal(t, "JNB", enriched.Code)
+	assert.Equal(t, "O. R. Tambo International Airport", enriched.Name)
+	assert.Equal(t, "Africa/Johannesburg", enriched.Timezone)
+
+	unknown, ok := db.Enrich(models.Airport{Code: " xyz ", Name: "Somewhere"})
+	assert.False(t, ok)
+	assert.Equal(t, "XYZ", unknown.Code)
+	assert.Equal(t, "Somewhere", unknown.Name)
+}
+
+func TestDistance(t *testing.T) {
+	jfk, _ := Default().Lookup("JFK")
+	lhr, _ := Default().Lookup("LHR")
+
+	// JFK to LHR is roughly 5540 km along the great circle
+	assert.InDelta(t, 5540, Distance(jfk.Latitude, jfk.Longitude, lhr.Latitude, lhr.Longitude), 20)
+	assert.Zero(t, Distance(jfk.Latitude, jfk.Longitude, jfk.Latitude, jfk.Longitude))
+}
+
+func TestExpand(t *testing.T) {
+	db := Default()
+
+	tests := []struct {
+		name      string
+		location  string
+		radiusKm  float64
+		expected  []string
+		expectErr bool
+	}{
+		{name: "airport code", location: "jnb", expected: []string{"JNB"}},
+		{name: "unknown code is kept", location: "XYZ", expected: []string{"XYZ"}},
+		{name: "metro code", location: "NYC", expected: []string{"JFK", "LGA", "EWR"}},
+		{name: "airport with radius", location: "DCA", radiusKm: 60, expected: []string{"DCA", "IAD", "BWI"}},
+		{name: "coordinates with radius", location: "48.8566,2.3522", radiusKm: 40, expected: []string{"CDG", "ORY"}},
+		{name: "coordinates without radius", location: "48.8566,2.3522", expectErr: true},
+		{name: "unknown code with radius", location: "XYZ", radiusKm: 50, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, err := db.Expand(tt.location, tt.radiusKm)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, codes, len(tt.expected))
+			for _, code := range tt.expected {
+				assert.True(t, codes[code], "expected %s in %v", code, codes)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package airports
+
+import (
+	"FlightAPI/models"
+	"math"
+	"sort"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// NearbyAirport is an airport together with its distance from the search point.
+type NearbyAirport struct {
+	models.Airport
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// Distance returns the great-circle distance in kilometres between two coordinates,
+// using the haversine formula.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Near returns the airports within radiusKm of the given coordinates, closest first.
+func (db *Database) Near(lat, lon, radiusKm float64) []NearbyAirport {
+	var results []NearbyAirport
+	for _, airport := range db.airports {
+		d := Distance(lat, lon, airport.Latitude, airport.Longitude)
+		if d <= radiusKm {
+			results = append(results, NearbyAirport{Airport: airport, DistanceKm: math.Round(d*10) / 10})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].DistanceKm < results[j].DistanceKm
+	})
+	return results
+}
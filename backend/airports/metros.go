@@ -0,0 +1,127 @@
+package airports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Metro is a metropolitan area code that stands for several airports, e.g. NYC for JFK, LGA and EWR.
+type Metro struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Airports []string `json:"airports"`
+}
+
+// LoadMetros reads metropolitan areas from a CSV with the header code,name,airports.
+// Airport codes are separated by "|" and must already be in the database.
+func (db *Database) LoadMetros(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+
+	if db.metros == nil {
+		db.metros = make(map[string]Metro)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read metro: %w", err)
+		}
+
+		metro := Metro{Code: strings.ToUpper(record[0]), Name: record[1]}
+		if _, clash := db.byIATA[metro.Code]; clash {
+			return fmt.Errorf("metro code %s clashes with an airport code", metro.Code)
+		}
+
+		for _, code := range strings.Split(record[2], "|") {
+			code = strings.ToUpper(code)
+			if _, ok := db.byIATA[code]; !ok {
+				return fmt.Errorf("metro %s references unknown airport %s", metro.Code, code)
+			}
+			metro.Airports = append(metro.Airports, code)
+		}
+		db.metros[metro.Code] = metro
+	}
+
+	return nil
+}
+
+// Metro finds a metropolitan area by its code.
+func (db *Database) Metro(code string) (Metro, bool) {
+	metro, ok := db.metros[strings.ToUpper(strings.TrimSpace(code))]
+	return metro, ok
+}
+
+// Expand returns the set of airport codes a search location stands for.
+// The location is an airport code, a metropolitan area code or "lat,lon" coordinates.
+// A positive radius adds every airport within that many kilometres of the location.
+// Unknown codes without a radius are kept as-is so searches on them behave as before.
+func (db *Database) Expand(location string, radiusKm float64) (map[string]bool, error) {
+	location = strings.ToUpper(strings.TrimSpace(location))
+	codes := make(map[string]bool)
+
+	// Coordinates only make sense with a radius around them
+	if lat, lon, ok := parseCoordinates(location); ok {
+		if radiusKm <= 0 {
+			return nil, fmt.Errorf("a radius is required to search around coordinates")
+		}
+		for _, nearby := range db.Near(lat, lon, radiusKm) {
+			codes[nearby.Code] = true
+		}
+		return codes, nil
+	}
+
+	// Collect the airports the code stands for
+	var centers []string
+	if metro, ok := db.Metro(location); ok {
+		centers = metro.Airports
+	} else {
+		centers = []string{location}
+	}
+
+	for _, code := range centers {
+		codes[code] = true
+		if radiusKm <= 0 {
+			continue
+		}
+
+		airport, ok := db.Lookup(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown airport %s", code)
+		}
+		for _, nearby := range db.Near(airport.Latitude, airport.Longitude, radiusKm) {
+			codes[nearby.Code] = true
+		}
+	}
+
+	return codes, nil
+}
+
+// parseCoordinates parses a "lat,lon" pair in decimal degrees.
+func parseCoordinates(value string) (float64, float64, bool) {
+	latText, lonText, found := strings.Cut(value, ",")
+	if !found {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonText), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
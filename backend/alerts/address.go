@@ -0,0 +1,68 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrNotPublic is returned for webhooks on addresses that aren't on the public internet,
+// such as loopback, private networks or cloud metadata endpoints. Calling them would let
+// any user reach services inside our network.
+var ErrNotPublic = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckWebhookURL checks that a webhook URL is an absolute http or https URL whose host
+// only resolves to public addresses. The addresses are checked again when the webhook is
+// called, since DNS can change in between.
+func CheckWebhookURL(ctx context.Context, raw string) error {
+	webhook, err := url.Parse(raw)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Hostname() == "" {
+		return errors.New("webhookUrl must be an absolute http or https URL")
+	}
+
+	host := webhook.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublic(addr) {
+			return fmt.Errorf("%w: %s", ErrNotPublic, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("webhookUrl host %s can't be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrNotPublic, host, addr)
+		}
+	}
+	return nil
+}
+
+// isPublic reports whether an address is routable on the public internet.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// dialPublicOnly is the net.Dialer Control of the webhook client. It refuses connections
+// to addresses that aren't public, whatever the webhook host resolved to.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isPublic(addr) {
+		return fmt.Errorf("%w: %s", ErrNotPublic, host)
+	}
+	return nil
+}
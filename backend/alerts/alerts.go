@@ -0,0 +1,146 @@
+// Package alerts evaluates the users' price alerts against freshly crawled flights
+// and delivers the matches to the alert webhooks from a queue.
+package alerts
+
+import (
+	"FlightAPI/airports"
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Evaluate checks every alert against the flights stored by a crawl run and queues the
+// matches for Work to deliver. A fare is only notified again once it gets cheaper than the
+// last price sent for it.
+func Evaluate(ctx context.Context, rdb *redis.Client, flights []models.Flight) {
+	alerts, err := store.ListAlerts(ctx, rdb, "")
+	if err != nil {
+		log.Printf("Error listing alerts: %v", err)
+		return
+	}
+	if len(alerts) == 0 || len(flights) == 0 {
+		return
+	}
+
+	log.Printf("Evaluating %d alerts against %d flights", len(alerts), len(flights))
+
+	// First prices are shared by every alert, so only read each flight history once
+	firstPrices := make(map[string]map[models.CabinClass]money.USD)
+
+	for _, alert := range alerts {
+		origins, err := airports.Default().Expand(alert.Origin, 0)
+		if err != nil {
+			log.Printf("Skipping alert %s with invalid origin: %v", alert.ID, err)
+			continue
+		}
+		destinations, err := airports.Default().Expand(alert.Destination, 0)
+		if err != nil {
+			log.Printf("Skipping alert %s with invalid destination: %v", alert.ID, err)
+			continue
+		}
+
+		for _, flight := range flights {
+			if !matchesFlight(alert, origins, destinations, flight) {
+				continue
+			}
+
+			firstPrice := flight.PriceUSD
+			if alert.DropPercent > 0 {
+				firstPrice, err = firstPriceOf(ctx, rdb, firstPrices, flight)
+				if err != nil {
+					log.Printf("Error reading price history for flight %s: %v", flight.ID, err)
+					continue
+				}
+			}
+
+			if !isTriggered(alert, flight.PriceUSD, firstPrice) {
+				continue
+			}
+
+			lastPrice, notified, err := store.LastNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class)
+			if err != nil {
+				log.Printf("Error reading notified price for alert %s: %v", alert.ID, err)
+				continue
+			}
+			if notified && flight.PriceUSD >= lastPrice {
+				continue
+			}
+
+			// Webhooks are called by the alert workers, so slow receivers don't hold up the crawl
+			match := models.AlertMatch{AlertID: alert.ID, Flight: flight, FirstPriceUSD: firstPrice, MatchedAt: time.Now().UTC()}
+			if err := store.QueueAlertMatch(ctx, rdb, match); err != nil {
+				log.Printf("Error queueing match of alert %s: %v", alert.ID, err)
+			}
+		}
+	}
+}
+
+// matchesFlight reports whether a flight is on the alert route, dates and class.
+func matchesFlight(alert models.Alert, origins, destinations map[string]bool, flight models.Flight) bool {
+	if !origins[strings.ToUpper(flight.DepartureAirport.Code)] ||
+		!destinations[strings.ToUpper(flight.ArrivalAirport.Code)] {
+		return false
+	}
+
+	if alert.Class != "" && !strings.EqualFold(string(alert.Class), string(flight.Class)) {
+		return false
+	}
+
+	departure, err := time.Parse(time.RFC3339, flight.DepartureTime)
+	if err != nil {
+		return false
+	}
+	date := departure.Format("2006-01-02")
+	if alert.DateFrom != "" && date < alert.DateFrom {
+		return false
+	}
+	if alert.DateTo != "" && date > alert.DateTo {
+		return false
+	}
+
+	return true
+}
+
+// isTriggered reports whether a price satisfies the alert target price or percentage drop.
+// Prices are compared in cents, so a price equal to the target always triggers.
+func isTriggered(alert models.Alert, price, firstPrice money.USD) bool {
+	if alert.TargetPriceUSD > 0 && price <= alert.TargetPriceUSD {
+		return true
+	}
+	// price <= firstPrice * (1 - dropPercent/100), scaled by 100 to keep the cents whole
+	if alert.DropPercent > 0 && firstPrice > 0 && float64(price)*100 <= float64(firstPrice)*(100-alert.DropPercent) {
+		return true
+	}
+	return false
+}
+
+// firstPriceOf returns the first price recorded for the flight fare, caching histories by flight.
+func firstPriceOf(ctx context.Context, rdb *redis.Client, cache map[string]map[models.CabinClass]money.USD, flight models.Flight) (money.USD, error) {
+	byClass, ok := cache[flight.ID]
+	if !ok {
+		observations, err := store.PriceHistory(ctx, rdb, flight.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		// Observations are sorted oldest first, so keep the first one per class
+		byClass = make(map[models.CabinClass]money.USD)
+		for _, observation := range observations {
+			if _, seen := byClass[observation.Class]; !seen {
+				byClass[observation.Class] = observation.PriceUSD
+			}
+		}
+		cache[flight.ID] = byClass
+	}
+
+	if price, ok := byClass[flight.Class]; ok {
+		return price, nil
+	}
+	return flight.PriceUSD, nil
+}
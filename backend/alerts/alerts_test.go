@@ -0,0 +1,253 @@
+package alerts
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTriggered(t *testing.T) {
+	tests := []struct {
+		name       string
+		alert      models.Alert
+		price      money.USD
+		firstPrice money.USD
+		expected   bool
+	}{
+		{name: "below target", alert: models.Alert{TargetPriceUSD: 50000}, price: 45000, firstPrice: 60000, expected: true},
+		{name: "above target", alert: models.Alert{TargetPriceUSD: 50000}, price: 55000, firstPrice: 60000, expected: false},
+		{name: "at target", alert: models.Alert{TargetPriceUSD: 30}, price: 10 + 20, firstPrice: 60000, expected: true},
+		{name: "dropped enough", alert: models.Alert{DropPercent: 10}, price: 54000, firstPrice: 60000, expected: true},
+		{name: "dropped exactly", alert: models.Alert{DropPercent: 7}, price: 93, firstPrice: 100, expected: true},
+		{name: "not dropped enough", alert: models.Alert{DropPercent: 10}, price: 56000, firstPrice: 60000, expected: false},
+		{name: "either threshold", alert: models.Alert{TargetPriceUSD: 30000, DropPercent: 10}, price: 50000, firstPrice: 60000, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isTriggered(tt.alert, tt.price, tt.firstPrice))
+		})
+	}
+}
+
+// allowLoopbackWebhooks lets the webhooks of a test call the httptest servers on loopback.
+func allowLoopbackWebhooks(t *testing.T) {
+	client := webhookClient
+	webhookClient = &http.Client{Timeout: client.Timeout}
+	t.Cleanup(func() { webhookClient = client })
+}
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + Sign("secret", r.Header.Get(TimestampHeader), body)
+		assert.Equal(t, expected, r.Header.Get(SignatureHeader))
+		assert.Equal(t, "price_drop", r.Header.Get(EventHeader))
+
+		// Fail the first call to exercise the retry
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	flight := models.Flight{ID: "DL123-2025-04-28", Class: "Economy", PriceUSD: 45000}
+
+	delivery := Deliver(context.Background(), alert, flight, 60000)
+
+	assert.True(t, delivery.Success)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.StatusCode)
+	assert.Empty(t, delivery.Error)
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	delivery := Deliver(context.Background(), alert, models.Flight{ID: "DL123-2025-04-28"}, 0)
+
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, http.StatusGone, delivery.StatusCode)
+}
+
+func TestWorkerDeliversQueuedMatchOnce(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	assert.NoError(t, store.SaveAlert(ctx, rdb, alert))
+
+	// Two crawls matched the same fare before the worker got to it
+	flight := models.Flight{ID: "DL123-2025-04-28", Class: "Economy", PriceUSD: 45000}
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, store.QueueAlertMatch(ctx, rdb, models.AlertMatch{AlertID: alert.ID, Flight: flight, FirstPriceUSD: 60000}))
+	}
+	assert.NoError(t, deliverNext(ctx, rdb))
+	assert.NoError(t, deliverNext(ctx, rdb))
+
+	assert.Equal(t, int32(1), calls.Load())
+	deliveries, err := store.ListDeliveries(ctx, rdb, alert.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.True(t, deliveries[0].Success)
+	}
+	price, notified, err := store.LastNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class)
+	assert.NoError(t, err)
+	assert.True(t, notified)
+	assert.Equal(t, money.USD(45000), price)
+}
+
+func TestConcurrentWorkersDeliverMatchOnce(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	// A slow webhook leaves the other workers time to take the same fare
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	assert.NoError(t, store.SaveAlert(ctx, rdb, alert))
+
+	flight := models.Flight{ID: "DL123-2025-04-28", Class: "Economy", PriceUSD: 45000}
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, store.QueueAlertMatch(ctx, rdb, models.AlertMatch{AlertID: alert.ID, Flight: flight, FirstPriceUSD: 60000}))
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, deliverNext(ctx, rdb))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWorkerRedeliversAfterFailure(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	var status atomic.Int32
+	status.Store(http.StatusGone)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	assert.NoError(t, store.SaveAlert(ctx, rdb, alert))
+	flight := models.Flight{ID: "DL123-2025-04-28", Class: "Economy", PriceUSD: 45000}
+	_, err := store.ClaimNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class, 50000)
+	assert.NoError(t, err)
+
+	// A failed delivery gives the claimed price back
+	assert.NoError(t, store.QueueAlertMatch(ctx, rdb, models.AlertMatch{AlertID: alert.ID, Flight: flight, FirstPriceUSD: 60000}))
+	assert.NoError(t, deliverNext(ctx, rdb))
+	price, notified, err := store.LastNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class)
+	assert.NoError(t, err)
+	assert.True(t, notified)
+	assert.Equal(t, money.USD(50000), price)
+
+	// So the next match at that price is delivered
+	status.Store(http.StatusOK)
+	assert.NoError(t, store.QueueAlertMatch(ctx, rdb, models.AlertMatch{AlertID: alert.ID, Flight: flight, FirstPriceUSD: 60000}))
+	assert.NoError(t, deliverNext(ctx, rdb))
+	price, _, err = store.LastNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class)
+	assert.NoError(t, err)
+	assert.Equal(t, money.USD(45000), price)
+	deliveries, err := store.ListDeliveries(ctx, rdb, alert.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.True(t, deliveries[0].Success)
+		assert.False(t, deliveries[1].Success)
+	}
+}
+
+func TestDeliverRefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The server listens on loopback, as a host rebound after validation would resolve
+	alert := models.Alert{ID: "alert1", WebhookURL: server.URL, WebhookSecret: "secret"}
+	delivery := Deliver(context.Background(), alert, models.Flight{ID: "DL123-2025-04-28"}, 0)
+
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Contains(t, delivery.Error, ErrNotPublic.Error())
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	for _, raw := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+		"http://10.1.2.3/hook",
+		"http://172.16.0.1/hook",
+		"http://192.168.1.10/hook",
+		"http://100.64.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+	} {
+		assert.ErrorIs(t, CheckWebhookURL(context.Background(), raw), ErrNotPublic, raw)
+	}
+
+	for _, raw := range []string{"ftp://93.184.216.34/hook", "/hooks/flights", "https://"} {
+		err := CheckWebhookURL(context.Background(), raw)
+		assert.Error(t, err, raw)
+		assert.NotErrorIs(t, err, ErrNotPublic, raw)
+	}
+
+	assert.NoError(t, CheckWebhookURL(context.Background(), "https://93.184.216.34/hooks/flights"))
+	assert.NoError(t, CheckWebhookURL(context.Background(), "http://[2606:2800:220:1::1]:8443/hook"))
+}
@@ -0,0 +1,176 @@
+package alerts
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxAttempts is how many times a webhook is called before the delivery is marked failed.
+	maxAttempts = 3
+	// initialBackoff is the wait before the first retry; it doubles on every attempt.
+	initialBackoff = time.Second
+)
+
+// Headers sent with every webhook call. Receivers verify the signature by computing
+// HMAC-SHA256 over "<timestamp>.<body>" with the alert secret.
+const (
+	EventHeader     = "X-FlightAPI-Event"
+	DeliveryHeader  = "X-FlightAPI-Delivery"
+	TimestampHeader = "X-FlightAPI-Timestamp"
+	SignatureHeader = "X-FlightAPI-Signature"
+)
+
+// webhookClient only connects to public addresses. It checks the address it dials rather
+// than the URL host, so DNS rebinding and redirects can't reach the internal network.
+// It ignores proxy settings, which would make it dial the proxy instead.
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: dialPublicOnly}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// Payload is the JSON body posted to the webhook when an alert matches.
+type Payload struct {
+	Event          string        `json:"event"`
+	DeliveryID     string        `json:"deliveryId"`
+	AlertID        string        `json:"alertId"`
+	AlertName      string        `json:"alertName,omitempty"`
+	Flight         models.Flight `json:"flight"`
+	PriceUSD       money.USD     `json:"priceUSD"`
+	FirstPriceUSD  money.USD     `json:"firstPriceUSD"`
+	TargetPriceUSD money.USD     `json:"targetPriceUSD,omitempty"`
+	DropPercent    float64       `json:"dropPercent,omitempty"`
+	TriggeredAt    time.Time     `json:"triggeredAt"`
+}
+
+// NewSecret returns a random secret used to sign the webhook payloads of an alert.
+func NewSecret() string {
+	b := make([]byte, 32)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" using the secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts an alert match to the alert webhook, retrying with exponential backoff
+// on network errors, 429 and 5xx responses. It returns the delivery record to log.
+func Deliver(ctx context.Context, alert models.Alert, flight models.Flight, firstPrice money.USD) models.AlertDelivery {
+	delivery := models.AlertDelivery{
+		ID:        store.NewID(),
+		AlertID:   alert.ID,
+		FlightID:  flight.ID,
+		Class:     flight.Class,
+		PriceUSD:  flight.PriceUSD,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	body, err := json.Marshal(Payload{
+		Event:          "price_drop",
+		DeliveryID:     delivery.ID,
+		AlertID:        alert.ID,
+		AlertName:      alert.Name,
+		Flight:         flight,
+		PriceUSD:       flight.PriceUSD,
+		FirstPriceUSD:  firstPrice,
+		TargetPriceUSD: alert.TargetPriceUSD,
+		DropPercent:    alert.DropPercent,
+		TriggeredAt:    delivery.CreatedAt,
+	})
+	if err != nil {
+		delivery.Error = fmt.Sprintf("marshal error: %v", err)
+		return delivery
+	}
+
+	backoff := initialBackoff
+	for delivery.Attempts < maxAttempts {
+		delivery.Attempts++
+
+		statusCode, err := post(ctx, alert, delivery.ID, body)
+		delivery.StatusCode = statusCode
+		if err == nil && statusCode >= 200 && statusCode < 300 {
+			delivery.Success = true
+			delivery.Error = ""
+			log.Printf("Delivered alert %s for flight %s to webhook", alert.ID, flight.ID)
+			return delivery
+		}
+
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Error = fmt.Sprintf("webhook responded with status %d", statusCode)
+		}
+
+		// Client errors other than rate limiting and refused addresses will not succeed on retry
+		if err == nil && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+			break
+		}
+		if errors.Is(err, ErrNotPublic) {
+			break
+		}
+		if delivery.Attempts == maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			delivery.Error = ctx.Err().Error()
+			return delivery
+		}
+	}
+
+	log.Printf("Failed to deliver alert %s for flight %s after %d attempts: %s", alert.ID, flight.ID, delivery.Attempts, delivery.Error)
+	return delivery
+}
+
+// post sends one signed webhook request and returns the response status.
+func post(ctx context.Context, alert models.Alert, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, alert.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "price_drop")
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(alert.WebhookSecret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,90 @@
+package alerts
+
+import (
+	"FlightAPI/store"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// pollTimeout is how long a worker waits for a queued match before checking whether it
+// should stop.
+const pollTimeout = 5 * time.Second
+
+// deliveryTimeout bounds one delivery with all its retries, so a dead webhook only holds
+// up the worker calling it.
+const deliveryTimeout = time.Minute
+
+// Work delivers the queued alert matches with a pool of workers until ctx is done. Every
+// replica may run it: each match is taken by one worker only.
+func Work(ctx context.Context, rdb *redis.Client, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if err := deliverNext(ctx, rdb); err != nil && ctx.Err() == nil {
+					log.Printf("Error delivering alert match: %v", err)
+					// Don't spin while Redis is unavailable
+					select {
+					case <-time.After(time.Second):
+					case <-ctx.Done():
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliverNext waits for the next queued match and calls its webhook, unless the alert was
+// deleted or the fare was notified at this price or lower in the meantime. The price is
+// claimed before the call and given back if the delivery fails.
+func deliverNext(ctx context.Context, rdb *redis.Client) error {
+	match, err := store.NextAlertMatch(ctx, rdb, pollTimeout)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	alert, err := store.GetAlert(ctx, rdb, match.AlertID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Several crawls may queue the same fare before its first delivery went through, and
+	// other workers may be taking those matches right now
+	flight := match.Flight
+	restore, err := store.ClaimNotifiedPrice(ctx, rdb, alert.ID, flight.ID, flight.Class, flight.PriceUSD)
+	if errors.Is(err, store.ErrAlreadyNotified) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	deliveryCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	delivery := Deliver(deliveryCtx, alert, flight, match.FirstPriceUSD)
+	cancel()
+
+	if err := store.AppendDelivery(ctx, rdb, delivery); err != nil {
+		log.Printf("Error saving delivery for alert %s: %v", alert.ID, err)
+	}
+	if !delivery.Success {
+		// The fare can be notified again at this price by a later match
+		if err := restore(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Error restoring notified price for alert %s: %v", alert.ID, err)
+		}
+	}
+	return nil
+}
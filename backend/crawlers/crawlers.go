@@ -0,0 +1,185 @@
+// Package crawlers fetches flights from the providers and stores them in Redis.
+// Every run is recorded in the crawl history, and a provider is never crawled twice at once.
+package crawlers
+
+import (
+	"FlightAPI/alerts"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// crawlTimeout bounds fetching the payload of a crawl run. The run lock outlives it
+// slightly so that a slow run is cancelled before another one can start; storing the
+// records may go on until lockMargin before the lock expires.
+const (
+	crawlTimeout = 5 * time.Minute
+	lockTimeout  = crawlTimeout + time.Minute
+	lockMargin   = 10 * time.Second
+)
+
+var (
+	// ErrUnknownProvider is returned when a crawl is requested for a provider that doesn't exist.
+	ErrUnknownProvider = errors.New("unknown provider")
+	// ErrCrawlInProgress is returned when the provider is already being crawled.
+	ErrCrawlInProgress = errors.New("a crawl of this provider is already in progress")
+	// errUnchanged is returned by a crawlFunc when the provider sent the same data as the last run.
+	errUnchanged = errors.New("payload unchanged")
+)
+
+// Options describe why a crawl runs.
+type Options struct {
+	Trigger     string // models.TriggerSchedule or models.TriggerManual
+	TriggeredBy string // User who started a manual run
+	Force       bool   // Fetch and parse the payload even if it didn't change since the last run
+}
+
+// crawlFunc fetches the flights of a provider, stores them and counts the records on the run.
+// It returns errUnchanged when the payload is the same as the last run, unless the run is forced.
+type crawlFunc func(ctx context.Context, rdb *redis.Client, run *models.CrawlRun) ([]models.Flight, error)
+
+// providers are the crawlers by provider name.
+var providers = map[string]crawlFunc{
+	mockyProvider: CallMockyAPI,
+}
+
+// Providers returns the names of the providers that can be crawled, sorted.
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knownProvider reports whether records can come from provider: a crawler or a file import.
+func knownProvider(provider string) bool {
+	_, ok := providers[provider]
+	return ok || provider == ImportProvider || provider == ScheduleProvider
+}
+
+// Run crawls a provider and waits for the run to finish. The returned run holds the
+// outcome; the error is only set when the run could not be started.
+func Run(ctx context.Context, rdb *redis.Client, provider string, opts Options) (models.CrawlRun, error) {
+	if _, ok := providers[provider]; !ok {
+		return models.CrawlRun{}, fmt.Errorf("%w %q", ErrUnknownProvider, provider)
+	}
+
+	run, err := begin(ctx, rdb, provider, opts)
+	if err != nil {
+		return models.CrawlRun{}, err
+	}
+	execute(ctx, rdb, &run)
+	return run, nil
+}
+
+// Start crawls a provider in the background and returns the run as soon as it is recorded.
+func Start(ctx context.Context, rdb *redis.Client, provider string, opts Options) (models.CrawlRun, error) {
+	if _, ok := providers[provider]; !ok {
+		return models.CrawlRun{}, fmt.Errorf("%w %q", ErrUnknownProvider, provider)
+	}
+
+	run, err := begin(ctx, rdb, provider, opts)
+	if err != nil {
+		return models.CrawlRun{}, err
+	}
+
+	// The run must not end with the request that started it
+	started := run
+	go execute(context.Background(), rdb, &started)
+	return run, nil
+}
+
+// begin takes the lock of a known provider and records the run in the crawl history.
+func begin(ctx context.Context, rdb *redis.Client, provider string, opts Options) (models.CrawlRun, error) {
+	run := models.CrawlRun{
+		ID:          store.NewID(),
+		Provider:    provider,
+		Status:      models.CrawlRunning,
+		Trigger:     opts.Trigger,
+		TriggeredBy: opts.TriggeredBy,
+		Forced:      opts.Force,
+		StartedAt:   time.Now().UTC(),
+	}
+
+	acquired, err := store.AcquireCrawlLock(ctx, rdb, provider, run.ID, lockTimeout)
+	if err != nil {
+		return models.CrawlRun{}, fmt.Errorf("acquire crawl lock: %w", err)
+	}
+	if !acquired {
+		return models.CrawlRun{}, ErrCrawlInProgress
+	}
+
+	if err := store.AddCrawlRun(ctx, rdb, run); err != nil {
+		_ = store.ReleaseCrawlLock(ctx, rdb, provider, run.ID)
+		return models.CrawlRun{}, fmt.Errorf("record crawl run: %w", err)
+	}
+	return run, nil
+}
+
+// storeContext returns the context the records of a run are stored with. It isn't cancelled
+// with ctx, so a fetch timing out or a client going away doesn't stop a batch halfway, but
+// it ends before the lock of the run expires so that a run still writing can't overlap the
+// next one.
+func storeContext(ctx context.Context, run *models.CrawlRun) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(context.WithoutCancel(ctx), run.StartedAt.Add(lockTimeout-lockMargin))
+}
+
+// execute crawls the provider of a started run, then stores the crawl report, evaluates
+// the price alerts and records the outcome of the run before releasing the lock.
+func execute(ctx context.Context, rdb *redis.Client, run *models.CrawlRun) {
+	crawlCtx, cancel := context.WithTimeout(ctx, crawlTimeout)
+	defer cancel()
+	storeCtx, cancelStore := storeContext(ctx, run)
+	defer cancelStore()
+
+	log.Printf("Crawl %s of %s started (%s)", run.ID, run.Provider, run.Trigger)
+
+	flights, err := providers[run.Provider](crawlCtx, rdb, run)
+	if errors.Is(err, errUnchanged) {
+		// Nothing to store, report or alert on
+		run.Status = models.CrawlUnchanged
+	} else if err != nil {
+		run.Status = models.CrawlFailed
+		run.Error = err.Error()
+	} else {
+		run.Status = models.CrawlSucceeded
+
+		// Compare with the previous crawl and keep the diff as the crawl report
+		report, err := recordCrawlReport(storeCtx, rdb, run.Provider, run.ID, run.StartedAt, flights)
+		if err != nil {
+			log.Printf("Error recording report of crawl %s: %v", run.ID, err)
+		} else {
+			log.Printf("Crawl %s: %d added, %d removed, %d price changes, %d status changes, %d schedule changes",
+				run.ID, report.Summary.Added, report.Summary.Removed, report.Summary.PriceChanged,
+				report.Summary.StatusChanged, report.Summary.ScheduleChanged)
+		}
+
+		// Queue the matches of the price alerts with the fresh data for the alert workers
+		alerts.Evaluate(storeCtx, rdb, flights)
+	}
+
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+	log.Printf("Crawl %s of %s %s: %d read, %d inserted, %d updated, %d rejected",
+		run.ID, run.Provider, run.Status, run.Read, run.Inserted, run.Updated, run.Rejected)
+	if run.Error != "" {
+		log.Printf("Crawl %s failed: %s", run.ID, run.Error)
+	}
+
+	// The crawl context may have expired, but the outcome must still be recorded
+	if err := store.SaveCrawlRun(context.Background(), rdb, *run); err != nil {
+		log.Printf("Error saving crawl run %s: %v", run.ID, err)
+	}
+	if err := store.ReleaseCrawlLock(context.Background(), rdb, run.Provider, run.ID); err != nil {
+		log.Printf("Error releasing crawl lock of %s: %v", run.Provider, err)
+	}
+}
@@ -0,0 +1,104 @@
+package crawlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// recordCrawlReport compares the fares of a crawl run with the previous run of the provider,
+// stores the resulting report and makes this run the new reference for the next one.
+func recordCrawlReport(ctx context.Context, rdb *redis.Client, provider, crawlID string, startedAt time.Time, flights []models.Flight) (models.CrawlReport, error) {
+	previousID, previous, err := store.LastSnapshot(ctx, rdb, provider)
+	if err != nil {
+		return models.CrawlReport{}, fmt.Errorf("load previous snapshot: %w", err)
+	}
+
+	diff := diffFlights(previous, flights)
+	report := models.CrawlReport{
+		ID:         crawlID,
+		Provider:   provider,
+		PreviousID: previousID,
+		StartedAt:  startedAt.UTC(),
+		FinishedAt: time.Now().UTC(),
+		Fares:      len(flights),
+		Summary:    diff.Count(),
+		Diff:       diff,
+	}
+
+	if err := store.SaveCrawlReport(ctx, rdb, report); err != nil {
+		return models.CrawlReport{}, fmt.Errorf("save crawl report: %w", err)
+	}
+	if err := store.ReplaceSnapshot(ctx, rdb, provider, crawlID, flights); err != nil {
+		return models.CrawlReport{}, fmt.Errorf("save snapshot: %w", err)
+	}
+	return report, nil
+}
+
+// priceChanged reports whether the provider changed the price of a fare. A new exchange
+// rate changes PriceUSD but is not a price change.
+func priceChanged(previous, current models.Flight) bool {
+	return previous.Price != current.Price
+}
+
+// diffFlights compares the fares of a crawl with the previous snapshot, keyed by store.FareKey.
+// Fares missing from the crawl are removed; the lists are sorted by flight ID and class.
+func diffFlights(previous map[string]models.Flight, current []models.Flight) models.CrawlDiff {
+	diff := models.CrawlDiff{
+		Added:           []models.FareChange{},
+		Removed:         []models.FareChange{},
+		PriceChanged:    []models.FareChange{},
+		StatusChanged:   []models.FareChange{},
+		ScheduleChanged: []models.FareChange{},
+	}
+
+	seen := make(map[string]bool, len(current))
+	for i := range current {
+		after := current[i]
+		fare := store.FareKey(after.ID, after.Class)
+		if seen[fare] {
+			// A provider listing a fare twice is not a change
+			continue
+		}
+		seen[fare] = true
+
+		before, ok := previous[fare]
+		if !ok {
+			diff.Added = append(diff.Added, models.FareChange{FlightID: after.ID, Class: after.Class, After: &after})
+			continue
+		}
+
+		change := models.FareChange{FlightID: after.ID, Class: after.Class, Before: &before, After: &after}
+		if priceChanged(before, after) {
+			diff.PriceChanged = append(diff.PriceChanged, change)
+		}
+		if before.Status != after.Status {
+			diff.StatusChanged = append(diff.StatusChanged, change)
+		}
+		if scheduleChanged(before, after) {
+			diff.ScheduleChanged = append(diff.ScheduleChanged, change)
+		}
+	}
+
+	for fare, flight := range previous {
+		if !seen[fare] {
+			before := flight
+			diff.Removed = append(diff.Removed, models.FareChange{FlightID: before.ID, Class: before.Class, Before: &before})
+		}
+	}
+
+	for _, changes := range [][]models.FareChange{diff.Added, diff.Removed, diff.PriceChanged, diff.StatusChanged, diff.ScheduleChanged} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].FlightID != changes[j].FlightID {
+				return changes[i].FlightID < changes[j].FlightID
+			}
+			return changes[i].Class < changes[j].Class
+		})
+	}
+	return diff
+}
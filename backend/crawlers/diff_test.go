@@ -0,0 +1,71 @@
+package crawlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFlights(t *testing.T) {
+	fare := func(id string, class models.CabinClass, cents int64, status models.FlightStatus, departure string) models.Flight {
+		return models.Flight{ID: id, Class: class, Price: money.New(cents, "USD"), PriceUSD: money.USD(cents), Status: status, DepartureTime: departure}
+	}
+	snapshot := func(flights ...models.Flight) map[string]models.Flight {
+		fares := make(map[string]models.Flight)
+		for _, flight := range flights {
+			fares[store.FareKey(flight.ID, flight.Class)] = flight
+		}
+		return fares
+	}
+
+	previous := snapshot(
+		fare("DL123-2025-04-28", "Economy", 30000, models.StatusScheduled, "2025-04-28T10:00:00Z"),
+		fare("DL123-2025-04-28", "Business", 90000, models.StatusScheduled, "2025-04-28T10:00:00Z"),
+		fare("BA117-2025-04-28", "Economy", 45000, models.StatusScheduled, "2025-04-28T18:00:00Z"),
+	)
+	current := []models.Flight{
+		fare("DL123-2025-04-28", "Economy", 28000, models.StatusDelayed, "2025-04-28T10:00:00Z"),
+		fare("DL123-2025-04-28", "Business", 90000, models.StatusScheduled, "2025-04-28T11:30:00Z"),
+		fare("AF22-2025-04-29", "Economy", 52000, models.StatusScheduled, "2025-04-29T08:00:00Z"),
+		// Listed twice by the provider
+		fare("AF22-2025-04-29", "Economy", 52000, models.StatusScheduled, "2025-04-29T08:00:00Z"),
+	}
+
+	diff := diffFlights(previous, current)
+
+	assert.Equal(t, models.DiffCount{Added: 1, Removed: 1, PriceChanged: 1, StatusChanged: 1, ScheduleChanged: 1}, diff.Count())
+
+	assert.Equal(t, "AF22-2025-04-29", diff.Added[0].FlightID)
+	assert.Nil(t, diff.Added[0].Before)
+
+	assert.Equal(t, "BA117-2025-04-28", diff.Removed[0].FlightID)
+	assert.Nil(t, diff.Removed[0].After)
+
+	assert.Equal(t, models.CabinEconomy, diff.PriceChanged[0].Class)
+	assert.Equal(t, money.New(30000, "USD"), diff.PriceChanged[0].Before.Price)
+	assert.Equal(t, money.New(28000, "USD"), diff.PriceChanged[0].After.Price)
+
+	assert.Equal(t, models.StatusDelayed, diff.StatusChanged[0].After.Status)
+	assert.Equal(t, models.CabinBusiness, diff.ScheduleChanged[0].Class)
+}
+
+func TestDiffFlightsIgnoresExchangeRates(t *testing.T) {
+	before := models.Flight{ID: "AF22-2025-04-29", Class: "Economy", Price: money.New(45000, "EUR"), PriceUSD: 46802}
+	after := before
+	after.PriceUSD = 48913
+
+	diff := diffFlights(map[string]models.Flight{store.FareKey(before.ID, before.Class): before}, []models.Flight{after})
+
+	assert.Empty(t, diff.PriceChanged, "only the dollar value moved with the exchange rate")
+}
+
+func TestDiffFlightsFirstCrawl(t *testing.T) {
+	diff := diffFlights(nil, []models.Flight{{ID: "DL123-2025-04-28", Class: "Economy"}})
+
+	assert.Len(t, diff.Added, 1)
+	assert.Empty(t, diff.Removed)
+	assert.NotNil(t, diff.Removed, "empty lists are serialized as [] rather than null")
+}
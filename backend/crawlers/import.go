@@ -0,0 +1,192 @@
+package crawlers
+
+import (
+	"FlightAPI/alerts"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ImportProvider is the provider recorded for flights imported from files.
+const ImportProvider = "import"
+
+// ScheduleProvider is the provider recorded for flights imported from schedule files,
+// which carry no fares.
+const ScheduleProvider = "schedule"
+
+// ImportOptions describe an import.
+type ImportOptions struct {
+	Source      string // File name, recorded on the run
+	TriggeredBy string // User who imported the file
+	DryRun      bool   // Report what would change without storing anything
+	Schedule    bool   // Records are schedules without fares, e.g. from SSIM files
+}
+
+// ImportResult is what an import changed, or would change for a dry run.
+// Imports are partial, so fares missing from the file are never reported as removed.
+type ImportResult struct {
+	DryRun   bool              `json:"dryRun"`
+	Run      *models.CrawlRun  `json:"run,omitempty"` // Not recorded for dry runs
+	Read     int               `json:"read"`
+	Rejected []ImportRejection `json:"rejected"`
+	Summary  models.DiffCount  `json:"summary"`
+	Diff     models.CrawlDiff  `json:"diff"`
+}
+
+// ImportRejection is a record of the file that failed decoding or validation.
+type ImportRejection struct {
+	Record int    `json:"record"` // Position in the file, starting at 1
+	Reason string `json:"reason"`
+}
+
+// Import ingests records read from a file like crawled ones. Rejected records are
+// dead-lettered, and the import is recorded in the crawl history with its report.
+// Storing doesn't stop when ctx is cancelled, only when the run lock is about to expire,
+// which fails the run.
+// Schedules only update the schedule of the flights, under ScheduleProvider: they never
+// change a price or notify the price alerts.
+// A dry run only decodes and validates the records and compares them with the stored fares.
+func Import(ctx context.Context, rdb *redis.Client, records []json.RawMessage, opts ImportOptions) (ImportResult, error) {
+	provider := ImportProvider
+	if opts.Schedule {
+		provider = ScheduleProvider
+	}
+	if opts.DryRun {
+		return dryRunImport(ctx, rdb, provider, records)
+	}
+
+	run, err := begin(ctx, rdb, provider, Options{Trigger: models.TriggerImport, TriggeredBy: opts.TriggeredBy})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	run.Source = opts.Source
+	run.Status = models.CrawlSucceeded
+
+	// Keep storing if the client that uploaded the file goes away, until the lock expires
+	storeCtx, cancel := storeContext(ctx, &run)
+	defer cancel()
+
+	result := ImportResult{Run: &run, Read: len(records), Rejected: []ImportRejection{}}
+	in := newIngestion(storeCtx, rdb, provider, time.Now())
+	ingest := in.ingestFlight
+	if opts.Schedule {
+		ingest = in.ingestSchedule
+	}
+	previous := make(map[string]models.Flight)
+	var flights []models.Flight
+
+	for i, raw := range records {
+		flight, before, err := ingest(storeCtx, raw)
+		if storeCtx.Err() != nil {
+			// The records left aren't at fault: the run fails and the file can be imported again
+			run.Status = models.CrawlFailed
+			run.Error = fmt.Sprintf("import stopped after %d of %d records: %v", i, len(records), storeCtx.Err())
+			break
+		}
+		run.Read++
+		if err != nil {
+			run.Rejected++
+			result.Rejected = append(result.Rejected, ImportRejection{Record: i + 1, Reason: err.Error()})
+			deadLetter(storeCtx, rdb, provider, run.ID, raw, err)
+			continue
+		}
+
+		if len(flight.Warnings) > 0 {
+			run.Warned++
+		}
+		if before != nil {
+			run.Updated++
+			previous[store.FareKey(before.ID, before.Class)] = *before
+		} else {
+			run.Inserted++
+		}
+		flights = append(flights, flight)
+	}
+	run.Violations = in.violations
+	in.flushCounters(storeCtx)
+
+	result.Diff = diffFlights(previous, flights)
+	result.Summary = result.Diff.Count()
+
+	finishedAt := time.Now().UTC()
+	report := models.CrawlReport{
+		ID:         run.ID,
+		Provider:   provider,
+		StartedAt:  run.StartedAt,
+		FinishedAt: finishedAt,
+		Fares:      len(flights),
+		Summary:    result.Summary,
+		Diff:       result.Diff,
+	}
+	if err := store.SaveCrawlReport(storeCtx, rdb, report); err != nil {
+		log.Printf("Error recording report of import %s: %v", run.ID, err)
+	}
+
+	// Notify the users whose price alerts match the imported fares
+	if !opts.Schedule {
+		alerts.Evaluate(storeCtx, rdb, flights)
+	}
+
+	run.FinishedAt = &finishedAt
+	log.Printf("Import %s of %s %s: %d read, %d inserted, %d updated, %d rejected",
+		run.ID, run.Source, run.Status, run.Read, run.Inserted, run.Updated, run.Rejected)
+	if run.Error != "" {
+		log.Printf("Import %s failed: %s", run.ID, run.Error)
+	}
+	if err := store.SaveCrawlRun(context.Background(), rdb, run); err != nil {
+		log.Printf("Error saving crawl run %s: %v", run.ID, err)
+	}
+	if err := store.ReleaseCrawlLock(context.Background(), rdb, provider, run.ID); err != nil {
+		log.Printf("Error releasing crawl lock of %s: %v", provider, err)
+	}
+	return result, nil
+}
+
+// dryRunImport reports what importing the records would change without storing anything.
+func dryRunImport(ctx context.Context, rdb *redis.Client, provider string, records []json.RawMessage) (ImportResult, error) {
+	result := ImportResult{DryRun: true, Read: len(records), Rejected: []ImportRejection{}}
+	in := newIngestion(ctx, rdb, provider, time.Now())
+	previous := make(map[string]models.Flight)
+	var flights []models.Flight
+
+	for i, raw := range records {
+		flight, err := in.prepareFlight(raw)
+		if err != nil {
+			result.Rejected = append(result.Rejected, ImportRejection{Record: i + 1, Reason: err.Error()})
+			continue
+		}
+
+		before, seen, err := store.GetFlightState(ctx, rdb, flight.ID, flight.Class)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("fetch state of flight %s: %w", flight.ID, err)
+		}
+		if seen {
+			previous[store.FareKey(before.ID, before.Class)] = before
+			// A schedule keeps the fare and only updates its schedule
+			if provider == ScheduleProvider {
+				scheduled := flight
+				flight = before
+				models.FlightOf(scheduled).ApplyTo(&flight)
+			}
+		}
+
+		// Project the status the flight would move to, as the ingestion would
+		current, known, err := store.CurrentStatus(ctx, rdb, flight.ID)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("fetch status of flight %s: %w", flight.ID, err)
+		}
+		flight.Status = nextStatus(flight, current, known)
+
+		flights = append(flights, flight)
+	}
+
+	result.Diff = diffFlights(previous, flights)
+	result.Summary = result.Diff.Count()
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package crawlers
+
+import (
+	"FlightAPI/importer"
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+// ssimLeg builds a SSIM flight leg record of DL123 from Atlanta to London on Monday 26 April 2027,
+// leaving at 22:30 and arriving at 11:40 the next day, sold in economy.
+func ssimLeg(departureTime string) string {
+	line := []byte(strings.Repeat(" ", 200))
+	for position, value := range map[int]string{
+		1: "3", 3: "DL", 6: "0123", 10: "01", 12: "01", 14: "J", 15: "26APR27", 22: "26APR27", 29: "1",
+		37: "ATL", 40: departureTime, 44: departureTime, 48: "-0400", 55: "LHR", 58: "1140", 62: "1140",
+		66: "+0100", 73: "339", 76: "Y", 193: "01",
+	} {
+		copy(line[position-1:], value)
+	}
+	return string(line)
+}
+
+func TestImportScheduleKeepsPrices(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	priced := json.RawMessage(`{"flightNumber":"DL123","airline":"Delta","departureAirport":{"code":"ATL"},"arrivalAirport":{"code":"LHR"},
+		"departureTime":"2027-04-26T22:30:00-04:00","arrivalTime":"2027-04-27T11:40:00+01:00","class":"Economy","status":"Scheduled",
+		"duration":"8h10m","priceAmount":50000,"currency":"USD"}`)
+	_, err := Import(ctx, rdb, []json.RawMessage{priced}, ImportOptions{Source: "fares.json"})
+	require.NoError(t, err)
+
+	// A schedule has no price, which must not look like a fare dropping to zero
+	require.NoError(t, store.SaveAlert(ctx, rdb, models.Alert{
+		ID: "alert", Owner: "alice", Origin: "ATL", Destination: "LHR", TargetPriceUSD: 100000, DropPercent: 10,
+		WebhookURL: "https://example.com/hook", CreatedAt: time.Now().UTC(),
+	}))
+
+	records, err := importer.Read(strings.NewReader(ssimLeg("2300")), importer.SSIM, importer.Mapping{})
+	require.NoError(t, err)
+	result, err := Import(ctx, rdb, records, ImportOptions{Source: "schedule.ssim", Schedule: true})
+	require.NoError(t, err)
+	assert.Empty(t, result.Rejected)
+	assert.Equal(t, ScheduleProvider, result.Run.Provider)
+	assert.Equal(t, 1, result.Run.Updated)
+	assert.Zero(t, result.Summary.PriceChanged)
+
+	const flightID = "DL123-2027-04-26"
+	state, seen, err := store.GetFlightState(ctx, rdb, flightID, models.CabinEconomy)
+	require.NoError(t, err)
+	require.True(t, seen)
+	assert.Equal(t, money.USD(50000), state.PriceUSD)
+	assert.Equal(t, "2027-04-26T23:00:00-04:00", state.DepartureTime)
+
+	history, err := store.PriceHistory(ctx, rdb, flightID)
+	require.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, money.USD(50000), history[0].PriceUSD)
+	}
+
+	// The date list still has the priced fare as its latest copy, with the new schedule
+	flights, err := store.FlightsByDate(ctx, rdb, "2027-04-26")
+	require.NoError(t, err)
+	if assert.Len(t, flights, 1) {
+		assert.Equal(t, money.USD(50000), flights[0].PriceUSD)
+	}
+	flight, found, err := store.GetFlight(ctx, rdb, flightID)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "2027-04-26T23:00:00-04:00", flight.DepartureTime)
+
+	_, err = store.NextAlertMatch(ctx, rdb, 10*time.Millisecond)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestImportScheduleListsNewFlightsUnpriced(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	records, err := importer.Read(strings.NewReader(ssimLeg("2230")), importer.SSIM, importer.Mapping{})
+	require.NoError(t, err)
+	result, err := Import(ctx, rdb, records, ImportOptions{Source: "schedule.ssim", Schedule: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Run.Inserted)
+
+	flights, err := store.FlightsByDate(ctx, rdb, "2027-04-26")
+	require.NoError(t, err)
+	assert.Len(t, flights, 1)
+
+	// The first priced crawl of the fare is a new fare, not a drop from zero
+	_, seen, err := store.GetFlightState(ctx, rdb, "DL123-2027-04-26", models.CabinEconomy)
+	require.NoError(t, err)
+	assert.False(t, seen)
+	history, err := store.PriceHistory(ctx, rdb, "DL123-2027-04-26")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
@@ -0,0 +1,294 @@
+package crawlers
+
+import (
+	"FlightAPI/alerts"
+	"FlightAPI/currency"
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"FlightAPI/validation"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ingestion stores the records of one crawl run or replay and counts the validation
+// rules they break.
+type ingestion struct {
+	rdb        *redis.Client
+	provider   string
+	crawledAt  time.Time
+	rules      validation.RuleSet
+	rates      *currency.Table
+	violations map[string]int
+}
+
+// newIngestion prepares the ingestion of records from a provider observed at crawledAt.
+// The validation rules and exchange rates are loaded once so every record of a run is
+// handled the same way.
+func newIngestion(ctx context.Context, rdb *redis.Client, provider string, crawledAt time.Time) *ingestion {
+	rules, err := validation.Load(ctx, rdb)
+	if err != nil {
+		log.Printf("Error loading validation rules, using the defaults: %v", err)
+		rules = validation.Defaults()
+	}
+	rates, err := currency.LoadTable(ctx, rdb)
+	if err != nil {
+		log.Printf("Error loading exchange rates, using the defaults: %v", err)
+		rates = currency.NewTable(currency.DefaultRates())
+	}
+	return &ingestion{rdb: rdb, provider: provider, crawledAt: crawledAt, rules: rules, rates: rates, violations: make(map[string]int)}
+}
+
+// flushCounters adds the violations counted so far to the rule counters.
+func (in *ingestion) flushCounters(ctx context.Context) {
+	if err := store.IncrementRuleCounters(ctx, in.rdb, in.violations); err != nil {
+		log.Printf("Error updating validation counters: %v", err)
+	}
+}
+
+// prepareFlight decodes one provider record, normalizes it and checks the validation rules.
+// Nothing is stored; an error means the record must be rejected.
+func (in *ingestion) prepareFlight(raw json.RawMessage) (models.Flight, error) {
+	var flight models.Flight
+	if err := json.Unmarshal(raw, &flight); err != nil {
+		return models.Flight{}, fmt.Errorf("decode error: %w", err)
+	}
+
+	// Replace provider airport data with our reference records
+	enrichAirports(&flight)
+
+	// Normalize the airline name and split the flight number into carrier and number
+	normalizeAirline(&flight)
+
+	// Store every spelling of a cabin as the same fare class
+	normalizeClass(&flight)
+
+	// Price the fare in minor units of its currency and in US dollars
+	if err := in.normalizePrice(&flight); err != nil {
+		return models.Flight{}, err
+	}
+
+	// Identify the flight across crawls
+	flight.ID = models.FlightID(flight)
+
+	// Check the data-quality rules before anything is stored
+	violations, err := in.rules.Apply(&flight)
+	for _, violation := range violations {
+		in.violations[violation.Rule]++
+	}
+	if err != nil {
+		return models.Flight{}, err
+	}
+	return flight, nil
+}
+
+// normalizePrice checks the currency of a fare and sets its value in US dollars at the rate
+// of the crawl day, which price history and alerts are based on. Records with a priceUSD and
+// no currency were priced in US dollars when they were decoded, and so are records with a
+// priceAmount and no currency, as providers sent them before prices had a currency.
+func (in *ingestion) normalizePrice(flight *models.Flight) error {
+	flight.ExchangeRate = nil
+	if flight.Price.Currency == "" {
+		flight.Price.Currency = currency.USD
+	}
+
+	if flight.Price.IsZero() && flight.PriceUSD != 0 && !strings.EqualFold(flight.Price.Currency, currency.USD) {
+		return fmt.Errorf("priceUSD given with currency %s, the price must be a priceAmount", flight.Price.Currency)
+	}
+
+	code, err := currency.Normalize(flight.Price.Currency)
+	if err != nil {
+		return err
+	}
+	flight.Price.Currency = code
+
+	usd, _, err := in.rates.Convert(flight.Price, currency.USD, in.crawledAt)
+	if err != nil {
+		return err
+	}
+	flight.PriceUSD = money.USD(usd.Amount)
+	return nil
+}
+
+// ingestFlight prepares one provider record and stores it: the flight by date, its status
+// timeline, price history and latest state, then publishes what changed.
+// It returns the stored flight and the previous state of the fare, nil if it is new.
+// The flight by date, its status change and its schedule are written in one transaction
+// before anything else, so an error means the record was rejected and nothing was stored.
+func (in *ingestion) ingestFlight(ctx context.Context, raw json.RawMessage) (models.Flight, *models.Flight, error) {
+	rdb, crawledAt := in.rdb, in.crawledAt
+
+	flight, err := in.prepareFlight(raw)
+	if err != nil {
+		return models.Flight{}, nil, err
+	}
+
+	// Keep the previous state of the fare to detect what changed
+	previous, seen, err := store.GetFlightState(ctx, rdb, flight.ID, flight.Class)
+	if err != nil {
+		log.Printf("Error fetching previous state of flight %s: %v", flight.ID, err)
+	}
+
+	// Move the flight through its status lifecycle
+	statusChange, err := applyStatus(ctx, rdb, &flight, in.provider, crawledAt)
+	if err != nil {
+		log.Printf("Error updating status of flight %s: %v", flight.ID, err)
+	}
+
+	if err := saveSchedule(ctx, rdb, flight, statusChange, true); err != nil {
+		return models.Flight{}, nil, err
+	}
+
+	// Append the fare to the flight price history
+	if err := store.RecordPrice(ctx, rdb, flight, crawledAt); err != nil {
+		log.Printf("Error recording price for flight %s: %v", flight.ID, err)
+	}
+
+	// Remember this state for the next crawl
+	if err := store.SaveFlightState(ctx, rdb, flight); err != nil {
+		log.Printf("Error saving state of flight %s: %v", flight.ID, err)
+	}
+
+	// Start the seat inventory of the fare from the seats the provider has left
+	if err := store.SeedInventory(ctx, rdb, flight); err != nil {
+		log.Printf("Error saving inventory of flight %s: %v", flight.ID, err)
+	}
+
+	// Tell the streaming clients what changed
+	publishChanges(ctx, rdb, flight, previous, seen, statusChange, crawledAt)
+
+	if !seen {
+		return flight, nil, nil
+	}
+	return flight, &previous, nil
+}
+
+// ingestSchedule prepares a schedule-only record, such as a SSIM flight leg, and stores
+// the schedule, status and aircraft of its flight. Schedules carry no fares: a fare already
+// stored keeps its price, and a new one is listed by date without a price until a provider
+// prices it. The price history, fare states and seat inventory are left to priced records.
+// It returns the flight as stored and the previous state of the fare, nil if it is new.
+func (in *ingestion) ingestSchedule(ctx context.Context, raw json.RawMessage) (models.Flight, *models.Flight, error) {
+	rdb, crawledAt := in.rdb, in.crawledAt
+
+	scheduled, err := in.prepareFlight(raw)
+	if err != nil {
+		return models.Flight{}, nil, err
+	}
+
+	previous, seen, err := store.GetFlightState(ctx, rdb, scheduled.ID, scheduled.Class)
+	if err != nil {
+		return models.Flight{}, nil, fmt.Errorf("error fetching state of flight %s: %w", scheduled.ID, err)
+	}
+
+	// Keep the fare of a known flight and only update its schedule
+	flight := scheduled
+	if seen {
+		flight = previous
+		models.FlightOf(scheduled).ApplyTo(&flight)
+	}
+
+	statusChange, err := applyStatus(ctx, rdb, &flight, in.provider, crawledAt)
+	if err != nil {
+		log.Printf("Error updating status of flight %s: %v", flight.ID, err)
+	}
+
+	if err := saveSchedule(ctx, rdb, flight, statusChange, !seen); err != nil {
+		return models.Flight{}, nil, err
+	}
+
+	// The fare state keeps the crawled price, with the new schedule to diff the next crawl against
+	if seen {
+		if err := store.SaveFlightState(ctx, rdb, flight); err != nil {
+			log.Printf("Error saving state of flight %s: %v", flight.ID, err)
+		}
+	}
+
+	publishChanges(ctx, rdb, flight, previous, seen, statusChange, crawledAt)
+
+	if !seen {
+		return flight, nil, nil
+	}
+	return flight, &previous, nil
+}
+
+// saveSchedule saves the status change of a flight and its schedule, status and aircraft,
+// kept once for every fare of the flight, and the fare by date when listed is set. Writing
+// them in one transaction keeps a rejected record from leaving a status change behind,
+// which a replay would record again.
+func saveSchedule(ctx context.Context, rdb *redis.Client, flight models.Flight, statusChange *models.StatusChange, listed bool) error {
+	pipe := rdb.TxPipeline()
+	if statusChange != nil {
+		if err := store.RecordStatusChange(ctx, pipe, flight, *statusChange); err != nil {
+			return fmt.Errorf("error saving status of flight %s: %w", flight.ID, err)
+		}
+	}
+	if err := store.SaveFlightInfo(ctx, pipe, models.FlightOf(flight)); err != nil {
+		return fmt.Errorf("error saving flight %s: %w", flight.ID, err)
+	}
+	if listed {
+		if err := saveFlightByDate(ctx, pipe, flight); err != nil {
+			return fmt.Errorf("error saving flight %s: %w", flight.ID, err)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("error saving flight %s: %w", flight.ID, err)
+	}
+	return nil
+}
+
+// deadLetter keeps a rejected record with the reason it was rejected.
+func deadLetter(ctx context.Context, rdb *redis.Client, provider, crawlID string, raw json.RawMessage, reason error) {
+	letter := models.DeadLetter{
+		ID:        store.NewID(),
+		Provider:  provider,
+		CrawlID:   crawlID,
+		Reason:    reason.Error(),
+		Raw:       raw,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := store.AddDeadLetter(ctx, rdb, letter); err != nil {
+		log.Printf("Error saving dead letter for crawl %s: %v", crawlID, err)
+	}
+}
+
+// Replay runs a dead letter through the ingestion again, e.g. after a parser fix.
+// A record that is accepted this time is removed from the dead letters and, unless it
+// is a schedule, checked against the price alerts; otherwise the dead letter is updated with the new reason.
+func Replay(ctx context.Context, rdb *redis.Client, letter models.DeadLetter) (models.Flight, error) {
+	if !knownProvider(letter.Provider) {
+		return models.Flight{}, fmt.Errorf("%w %q", ErrUnknownProvider, letter.Provider)
+	}
+
+	now := time.Now().UTC()
+	in := newIngestion(ctx, rdb, letter.Provider, now)
+	ingest := in.ingestFlight
+	if letter.Provider == ScheduleProvider {
+		ingest = in.ingestSchedule
+	}
+	flight, _, err := ingest(ctx, letter.Raw)
+	in.flushCounters(ctx)
+	if err != nil {
+		letter.Reason = err.Error()
+		letter.Replays++
+		letter.LastReplay = &now
+		if saveErr := store.SaveDeadLetter(ctx, rdb, letter); saveErr != nil {
+			log.Printf("Error updating dead letter %s: %v", letter.ID, saveErr)
+		}
+		return models.Flight{}, err
+	}
+
+	if err := store.DeleteDeadLetter(ctx, rdb, letter.ID); err != nil {
+		log.Printf("Error deleting replayed dead letter %s: %v", letter.ID, err)
+	}
+	if letter.Provider != ScheduleProvider {
+		alerts.Evaluate(ctx, rdb, []models.Flight{flight})
+	}
+	return flight, nil
+}
@@ -0,0 +1,102 @@
+package crawlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	letter := models.DeadLetter{
+		ID: "letter", Provider: ImportProvider, CrawlID: "crawl", Reason: "missing departure time",
+		Raw: json.RawMessage(`{"flightNumber":"DL123","airline":"Delta","departureAirport":{"code":"ATL"},"arrivalAirport":{"code":"LHR"},
+			"arrivalTime":"2027-04-27T11:40:00+01:00","class":"Economy","status":"Scheduled","duration":"8h10m","priceAmount":50000,"currency":"USD"}`),
+		CreatedAt: time.Now().UTC().Add(-time.Hour),
+	}
+	require.NoError(t, store.AddDeadLetter(ctx, rdb, letter))
+
+	// Replaying a record that is still broken keeps the letter with the new reason
+	_, err := Replay(ctx, rdb, letter)
+	require.Error(t, err)
+	stored, err := store.GetDeadLetter(ctx, rdb, letter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Replays)
+	assert.NotNil(t, stored.LastReplay)
+	assert.NotEmpty(t, stored.Reason)
+	assert.NotEqual(t, "missing departure time", stored.Reason)
+
+	// Once fixed, the record is stored and the letter removed
+	stored.Raw = json.RawMessage(`{"flightNumber":"DL123","airline":"Delta","departureAirport":{"code":"ATL"},"arrivalAirport":{"code":"LHR"},
+		"departureTime":"2027-04-26T22:30:00-04:00","arrivalTime":"2027-04-27T11:40:00+01:00","class":"Economy","status":"Scheduled",
+		"duration":"8h10m","priceAmount":50000,"currency":"USD"}`)
+	flight, err := Replay(ctx, rdb, stored)
+	require.NoError(t, err)
+	assert.Equal(t, "DL123", flight.FlightNumber)
+	_, err = store.GetDeadLetter(ctx, rdb, letter.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	listed, err := store.ListDeadLetters(ctx, rdb, "", "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	state, seen, err := store.GetFlightState(ctx, rdb, flight.ID, models.CabinEconomy)
+	require.NoError(t, err)
+	assert.True(t, seen)
+	assert.Equal(t, flight.Price, state.Price)
+}
+
+func TestReplayUnknownProvider(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	letter := models.DeadLetter{ID: "letter", Provider: "gone", Raw: json.RawMessage(`{}`), CreatedAt: time.Now().UTC()}
+	require.NoError(t, store.AddDeadLetter(ctx, rdb, letter))
+
+	_, err := Replay(ctx, rdb, letter)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	stored, err := store.GetDeadLetter(ctx, rdb, letter.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.Replays)
+}
+
+func TestNormalizePrice(t *testing.T) {
+	rdb := newTestRedis(t)
+	in := newIngestion(context.Background(), rdb, ImportProvider, time.Now())
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected money.Money
+		priceUSD money.USD
+		wantErr  bool
+	}{
+		{name: "amount and currency", raw: `{"priceAmount":50000,"currency":"usd"}`, expected: money.New(50000, "USD"), priceUSD: 50000},
+		{name: "amount without currency is in US dollars", raw: `{"priceAmount":50000}`, expected: money.New(50000, "USD"), priceUSD: 50000},
+		{name: "priceUSD only", raw: `{"priceUSD":500}`, expected: money.New(50000, "USD"), priceUSD: 50000},
+		{name: "priceUSD with another currency", raw: `{"priceUSD":50000,"currency":"EUR"}`, wantErr: true},
+		{name: "unknown currency", raw: `{"priceAmount":50000,"currency":"XXX"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var flight models.Flight
+			require.NoError(t, json.Unmarshal([]byte(tt.raw), &flight))
+			err := in.normalizePrice(&flight)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, flight.Price)
+			assert.Equal(t, tt.priceUSD, flight.PriceUSD)
+		})
+	}
+}
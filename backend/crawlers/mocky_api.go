@@ -1,24 +1,46 @@
 package crawlers
 
 import (
+	"FlightAPI/airlines"
+	"FlightAPI/airports"
+	"FlightAPI/events"
 	"FlightAPI/models"
+	"FlightAPI/store"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"io"
 	"log"
 	"net/http"
 	"time"
 )
 
-// callMockyAPI fetches data from the Mocky API and saves it to Redis.
+// mockyProvider is the source recorded for data coming from the Mocky API.
+const mockyProvider = "mocky"
+
+// mockyURL is the Mocky API URL: This shouldn't be hardcoded in production code
+// We're using http instead of https because our testing TSL certificates are self-signed.
+var mockyURL = "http://run.mocky.io/v3/60991ebd-1a38-4b8c-9e29-6466adb66fc6"
+
+// maxPayloadSize bounds the payload read in memory to compute its checksum.
+const maxPayloadSize = 64 << 20
+
+// CallMockyAPI fetches data from the Mocky API and saves it to Redis, counting the records on the run.
 // It uses a context to manage the request lifecycle and a Redis client to store the data.
 // If the request takes too long, it will be canceled.
-func CallMockyAPI(ctx context.Context, rdb *redis.Client) error {
-	// Mocky API URL: This shouldn't be hardcoded in production code
-	// We're using http instead of https because our testing TSL certificates are self-signed.
-	//
-	url := "http://run.mocky.io/v3/60991ebd-1a38-4b8c-9e29-6466adb66fc6"
+// Unless the run is forced, the request is conditional on the validators of the last payload,
+// and a payload with the same checksum is not parsed again; errUnchanged is returned instead.
+// It returns the flights that were stored.
+func CallMockyAPI(ctx context.Context, rdb *redis.Client, run *models.CrawlRun) ([]models.Flight, error) {
+	validators, err := store.PayloadValidators(ctx, rdb, mockyProvider)
+	if err != nil {
+		// Without validators the payload is simply fetched in full
+		log.Printf("Error loading payload validators of %s: %v", mockyProvider, err)
+	}
 
 	// Create a new HTTP client
 	client := &http.Client{}
@@ -26,81 +48,326 @@ func CallMockyAPI(ctx context.Context, rdb *redis.Client) error {
 	log.Println("Calling Mocky API...")
 
 	// Create a new GET request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", mockyURL, nil)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	// Set the request header
 	req.Header.Set("Content-Type", "application/json")
+	if !run.Forced {
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
 
 	// Send the request
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("call Mocky API: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	decoder := json.NewDecoder(resp.Body)
+	if resp.StatusCode == http.StatusNotModified {
+		log.Println("Mocky API payload not modified")
+		run.Checksum = validators.Checksum
+		return nil, errUnchanged
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Mocky API returned %s", resp.Status)
+	}
+
+	// Read the whole body to compare its checksum with the last run before parsing it
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read Mocky API response: %w", err)
+	}
+	if len(body) > maxPayloadSize {
+		return nil, fmt.Errorf("Mocky API response larger than %d bytes", maxPayloadSize)
+	}
+
+	sum := sha256.Sum256(body)
+	run.Checksum = hex.EncodeToString(sum[:])
+	if !run.Forced && run.Checksum == validators.Checksum {
+		log.Println("Mocky API payload unchanged since the last run")
+		return nil, errUnchanged
+	}
 
 	// Parse and insert data into the database
-	return parseAndInsert(ctx, rdb, decoder)
+	flights, err := parseAndInsert(ctx, rdb, json.NewDecoder(bytes.NewReader(body)), run)
+	if err != nil {
+		// Keep the old validators so the payload is parsed again next time
+		return flights, err
+	}
+
+	validators = models.PayloadValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Checksum:     run.Checksum,
+	}
+	if err := store.SavePayloadValidators(ctx, rdb, mockyProvider, validators); err != nil {
+		log.Printf("Error saving payload validators of %s: %v", mockyProvider, err)
+	}
+	return flights, nil
 }
 
 // We created a separate function with its own context to ensure that the parsing and insertion
 // if the parent context (The request) dies but the parsing is still in progress, it will not be interrupted.
-func parseAndInsert(ctx context.Context, rdb *redis.Client, decoder *json.Decoder) error {
+// Storing stops before the run lock expires though, failing the run, so it can't overlap the next run.
+// It returns the flights that were stored.
+func parseAndInsert(ctx context.Context, rdb *redis.Client, decoder *json.Decoder, run *models.CrawlRun) ([]models.Flight, error) {
 	log.Println("Parsing and saving flights from MockyAPI into Redis...")
-	// Create a cancellable context to ensure parsing and insertion complete
-	parseCtx, cancel := context.WithCancel(context.Background())
+	// Create a context that outlives the request but not the run lock
+	parseCtx, cancel := storeContext(ctx, run)
 	defer cancel()
 
+	// Every price and status seen during this run is recorded with the same observation time
+	crawledAt := time.Now()
+	var stored []models.Flight
+
+	in := newIngestion(parseCtx, rdb, mockyProvider, crawledAt)
+	defer func() {
+		run.Violations = in.violations
+		in.flushCounters(parseCtx)
+	}()
+
 	_, err := decoder.Token()
 	if err != nil {
-		log.Fatal("Failed to read start object %v", err)
+		return nil, fmt.Errorf("read start object: %w", err)
 	}
 
 	for decoder.More() {
 		tok, err := decoder.Token()
 		if err != nil {
-			log.Fatalf("Failed to read key: %v", err)
+			return stored, fmt.Errorf("read key: %w", err)
+		}
+
+		key, ok := tok.(string)
+		if !ok || key != "flights" {
+			// Skip the value of any other key
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return stored, fmt.Errorf("skip %v: %w", tok, err)
+			}
+			continue
 		}
 
-		if key, ok := tok.(string); ok && key == "flights" {
-			// Step 3: Read `[`
-			_, err := decoder.Token()
+		// Step 3: Read `[`
+		_, err = decoder.Token()
+		if err != nil {
+			return stored, fmt.Errorf("read flights array start: %w", err)
+		}
+
+		// Step 4: Stream array items
+		for decoder.More() {
+			if err := parseCtx.Err(); err != nil {
+				return stored, fmt.Errorf("store flights: %w", err)
+			}
+			run.Read++
+
+			// Keep the record as sent so it can be dead-lettered if it is rejected
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				// A syntax error leaves the decoder in a broken state
+				run.Rejected++
+				return stored, fmt.Errorf("decode flight: %w", err)
+			}
+
+			flight, previous, err := in.ingestFlight(parseCtx, raw)
+			if err != nil && parseCtx.Err() != nil {
+				// The record isn't at fault and would fail to be dead-lettered too
+				return stored, fmt.Errorf("store flights: %w", parseCtx.Err())
+			}
 			if err != nil {
-				log.Fatalf("Failed to read flights array start: %v", err)
+				log.Printf("Rejected flight record: %v", err)
+				run.Rejected++
+				deadLetter(parseCtx, rdb, mockyProvider, run.ID, raw, err)
+				continue
 			}
 
-			// Step 4: Stream array items
-			for decoder.More() {
-				var flight models.Flight
-				if err := decoder.Decode(&flight); err != nil {
-					log.Printf("Decode error: %v", err)
-					continue
-				}
-
-				// Save flight to Redis
-				err := saveFlightByDate(parseCtx, rdb, flight)
-				if err != nil {
-					log.Printf("Error saving flight: %v", err)
-					continue
-				}
+			if len(flight.Warnings) > 0 {
+				run.Warned++
+			}
+			if previous != nil {
+				run.Updated++
+			} else {
+				run.Inserted++
 			}
+			stored = append(stored, flight)
+		}
+
+		// Step 5: Read `]`
+		_, _ = decoder.Token()
+		break
+	}
+
+	return stored, nil
+}
 
-			// Step 5: Read `]`
-			_, _ = decoder.Token()
-			break
+// enrichAirports swaps the departure and arrival airports for our reference records.
+// Providers often send partial or misspelled airport data, so the bundled dataset wins.
+func enrichAirports(flight *models.Flight) {
+	db := airports.Default()
+
+	departure, ok := db.Enrich(flight.DepartureAirport)
+	if !ok {
+		log.Printf("Unknown departure airport %q on flight %s", flight.DepartureAirport.Code, flight.FlightNumber)
+	}
+	flight.DepartureAirport = departure
+
+	arrival, ok := db.Enrich(flight.ArrivalAirport)
+	if !ok {
+		log.Printf("Unknown arrival airport %q on flight %s", flight.ArrivalAirport.Code, flight.FlightNumber)
+	}
+	flight.ArrivalAirport = arrival
+}
+
+// normalizeClass maps the provider's cabin to one of our cabin classes so that "ECONOMY",
+// "coach" and "Y" fares are stored as the same fare. Unknown cabins are kept as sent and
+// caught by the known_class rule.
+func normalizeClass(flight *models.Flight) {
+	if flight.Class == "" {
+		return
+	}
+	class, ok := models.ParseCabinClass(string(flight.Class))
+	if !ok {
+		log.Printf("Unknown cabin class %q on flight %s", flight.Class, flight.FlightNumber)
+		return
+	}
+	flight.Class = class
+}
+
+// normalizeAirline resolves the provider's airline to our registry record so that
+// "Delta", "Delta Air Lines" and "DL" are stored the same way.
+// The airline field is tried first, then the carrier code from the flight number.
+func normalizeAirline(flight *models.Flight) {
+	registry := airlines.Default()
+
+	carrier, number, ok := registry.SplitFlightNumber(flight.FlightNumber)
+	if ok {
+		flight.CarrierCode = carrier
+		flight.Number = number
+		flight.FlightNumber = carrier + number
+	} else {
+		log.Printf("Could not split flight number %q", flight.FlightNumber)
+	}
+
+	airline, found := registry.Resolve(flight.Airline)
+	if !found && flight.CarrierCode != "" {
+		airline, found = registry.Lookup(flight.CarrierCode)
+	}
+	if !found {
+		log.Printf("Unknown airline %q on flight %s", flight.Airline, flight.FlightNumber)
+		return
+	}
+
+	if flight.CarrierCode != "" && flight.CarrierCode != airline.Code {
+		// Codeshares are marketed under another carrier's flight number, so this is not an error
+		log.Printf("Airline %s does not match carrier code %s on flight %s", airline.Code, flight.CarrierCode, flight.FlightNumber)
+	}
+	flight.Airline = airline.Name
+	if flight.CarrierCode == "" {
+		flight.CarrierCode = airline.Code
+	}
+}
+
+// applyStatus validates the status reported by the provider against the flight lifecycle.
+// Unknown statuses and forbidden transitions (e.g. Landed back to Scheduled) keep the last
+// known status. It returns the change to record in the flight timeline, or nil when the
+// status did not change; nothing is stored.
+func applyStatus(ctx context.Context, rdb *redis.Client, flight *models.Flight, source string, observedAt time.Time) (*models.StatusChange, error) {
+	current, known, err := store.CurrentStatus(ctx, rdb, flight.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reported := nextStatus(*flight, current, known)
+	flight.Status = reported
+	if known && current == reported {
+		return nil, nil
+	}
+
+	return &models.StatusChange{
+		From:      current,
+		To:        reported,
+		ChangedAt: observedAt.UTC(),
+		Source:    source,
+	}, nil
+}
+
+// nextStatus returns the status a flight moves to given the one reported by the provider
+// and the last known one, if any.
+func nextStatus(flight models.Flight, current models.FlightStatus, known bool) models.FlightStatus {
+	reported, ok := models.ParseFlightStatus(string(flight.Status))
+	if !ok {
+		log.Printf("Unknown status %q on flight %s", flight.Status, flight.ID)
+		reported = current
+		if !known {
+			reported = models.StatusScheduled
 		}
 	}
 
-	return nil
+	if known && !models.CanTransition(current, reported) {
+		log.Printf("Ignoring invalid status transition %s -> %s on flight %s", current, reported, flight.ID)
+		reported = current
+	}
+	return reported
+}
+
+// publishChanges compares a flight fare with its state from the previous crawl
+// and publishes the matching events for the streaming endpoints.
+func publishChanges(ctx context.Context, rdb *redis.Client, flight, previous models.Flight, seen bool, statusChange *models.StatusChange, at time.Time) {
+	base := models.FlightEvent{FlightID: flight.ID, Flight: flight, OccurredAt: at.UTC()}
+
+	var changes []models.FlightEvent
+	if !seen {
+		created := base
+		created.Type = models.EventFlightCreated
+		changes = append(changes, created)
+	} else {
+		if scheduleChanged(previous, flight) {
+			updated := base
+			updated.Type = models.EventFlightUpdated
+			changes = append(changes, updated)
+		}
+		if priceChanged(previous, flight) {
+			priceChanged := base
+			priceChanged.Type = models.EventFlightPriceChanged
+			priceChanged.PreviousPriceUSD = previous.PriceUSD
+			changes = append(changes, priceChanged)
+		}
+	}
+
+	// The first status of a flight is part of its creation, not a change
+	if statusChange != nil && statusChange.From != "" {
+		statusChanged := base
+		statusChanged.Type = models.EventFlightStatusChanged
+		statusChanged.PreviousStatus = statusChange.From
+		changes = append(changes, statusChanged)
+	}
+
+	for i := range changes {
+		if err := events.Publish(ctx, rdb, &changes[i]); err != nil {
+			log.Printf("Error publishing %s event for flight %s: %v", changes[i].Type, flight.ID, err)
+		}
+	}
 }
 
-// saveFlightByDate saves a flight to Redis by its departure date. The sooner the flight, the more recent it is.
-func saveFlightByDate(ctx context.Context, rdb *redis.Client, flight models.Flight) error {
+// scheduleChanged reports whether the schedule or route of a flight changed between crawls.
+func scheduleChanged(previous, current models.Flight) bool {
+	return previous.DepartureTime != current.DepartureTime ||
+		previous.ArrivalTime != current.ArrivalTime ||
+		previous.Duration != current.Duration ||
+		previous.DepartureAirport.Code != current.DepartureAirport.Code ||
+		previous.ArrivalAirport.Code != current.ArrivalAirport.Code ||
+		previous.Airline != current.Airline
+}
+
+// saveFlightByDate saves a flight to Redis by its departure date on a transaction. The sooner the flight, the more recent it is.
+func saveFlightByDate(ctx context.Context, pipe redis.Pipeliner, flight models.Flight) error {
 	// Parse and format the date
 	t, err := time.Parse(time.RFC3339, flight.DepartureTime)
 	if err != nil {
@@ -116,5 +383,6 @@ func saveFlightByDate(ctx context.Context, rdb *redis.Client, flight models.Flig
 	}
 
 	// LPUSH for most recent first
-	return rdb.LPush(ctx, redisKey, data).Err()
+	pipe.LPush(ctx, redisKey, data)
+	return nil
 }
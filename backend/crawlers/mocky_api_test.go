@@ -0,0 +1,148 @@
+package crawlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockyPayload = `{"flights":[{"flightNumber":"DL123","airline":"Delta","departureAirport":{"code":"ATL"},"arrivalAirport":{"code":"LHR"},
+	"departureTime":"2027-04-26T22:30:00-04:00","arrivalTime":"2027-04-27T11:40:00+01:00","class":"Economy","status":"Scheduled",
+	"duration":"8h10m","priceAmount":50000,"currency":"USD"}]}`
+
+// mockyServer serves a payload with validators and answers conditional requests the way
+// the Mocky API does, keeping the headers of the last request.
+type mockyServer struct {
+	mu           sync.Mutex
+	body         string
+	etag         string
+	lastModified string
+	honor        bool // Reply 304 when the validators match
+	headers      http.Header
+}
+
+func (s *mockyServer) set(body, etag string, honor bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.honor = body, etag, honor
+}
+
+func (s *mockyServer) lastHeaders() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.headers
+}
+
+func (s *mockyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers = r.Header.Clone()
+	if s.honor && r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Header().Set("Last-Modified", s.lastModified)
+	w.Write([]byte(s.body))
+}
+
+func newMockyServer(t *testing.T) *mockyServer {
+	server := &mockyServer{lastModified: "Mon, 19 Oct 2026 10:00:00 GMT"}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	previous := mockyURL
+	mockyURL = httpServer.URL
+	t.Cleanup(func() { mockyURL = previous })
+	return server
+}
+
+func mockyRun(forced bool) *models.CrawlRun {
+	return &models.CrawlRun{ID: store.NewID(), Provider: mockyProvider, Forced: forced, StartedAt: time.Now()}
+}
+
+func TestCallMockyAPIConditionalRequests(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+	server := newMockyServer(t)
+	server.set(mockyPayload, `"v1"`, true)
+
+	// Without validators the payload is fetched in full and its validators saved
+	run := mockyRun(false)
+	flights, err := CallMockyAPI(ctx, rdb, run)
+	require.NoError(t, err)
+	assert.Len(t, flights, 1)
+	assert.Empty(t, server.lastHeaders().Get("If-None-Match"))
+	assert.Empty(t, server.lastHeaders().Get("If-Modified-Since"))
+	validators, err := store.PayloadValidators(ctx, rdb, mockyProvider)
+	require.NoError(t, err)
+	assert.Equal(t, models.PayloadValidators{ETag: `"v1"`, LastModified: server.lastModified, Checksum: run.Checksum}, validators)
+	checksum := run.Checksum
+
+	// The next request is conditional and a 304 keeps the last checksum
+	run = mockyRun(false)
+	_, err = CallMockyAPI(ctx, rdb, run)
+	assert.ErrorIs(t, err, errUnchanged)
+	assert.Equal(t, `"v1"`, server.lastHeaders().Get("If-None-Match"))
+	assert.Equal(t, server.lastModified, server.lastHeaders().Get("If-Modified-Since"))
+	assert.Equal(t, checksum, run.Checksum)
+	assert.Zero(t, run.Read)
+
+	// A provider ignoring the validators and sending the same payload isn't parsed again
+	server.set(mockyPayload, `"v2"`, false)
+	run = mockyRun(false)
+	_, err = CallMockyAPI(ctx, rdb, run)
+	assert.ErrorIs(t, err, errUnchanged)
+	assert.Equal(t, checksum, run.Checksum)
+	assert.Zero(t, run.Read)
+
+	// A forced run sends no validators and parses the payload anyway
+	server.set(mockyPayload, `"v1"`, true)
+	run = mockyRun(true)
+	flights, err = CallMockyAPI(ctx, rdb, run)
+	require.NoError(t, err)
+	assert.Len(t, flights, 1)
+	assert.Empty(t, server.lastHeaders().Get("If-None-Match"))
+	assert.Empty(t, server.lastHeaders().Get("If-Modified-Since"))
+	assert.Equal(t, 1, run.Read)
+	assert.Equal(t, 1, run.Updated)
+}
+
+func TestCallMockyAPIKeepsValidatorsOnParseError(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+	server := newMockyServer(t)
+	server.set(mockyPayload, `"v1"`, true)
+
+	_, err := CallMockyAPI(ctx, rdb, mockyRun(false))
+	require.NoError(t, err)
+	saved, err := store.PayloadValidators(ctx, rdb, mockyProvider)
+	require.NoError(t, err)
+
+	// A truncated payload fails the run and leaves the old validators in place
+	server.set(mockyPayload[:len(mockyPayload)-20], `"v2"`, true)
+	run := mockyRun(false)
+	_, err = CallMockyAPI(ctx, rdb, run)
+	require.Error(t, err)
+	assert.NotEqual(t, saved.Checksum, run.Checksum)
+	validators, err := store.PayloadValidators(ctx, rdb, mockyProvider)
+	require.NoError(t, err)
+	assert.Equal(t, saved, validators)
+
+	// So the next run fetches and parses the fixed payload
+	server.set(strings.Replace(mockyPayload, "50000", "45000", 1), `"v2"`, true)
+	run = mockyRun(false)
+	flights, err := CallMockyAPI(ctx, rdb, run)
+	require.NoError(t, err)
+	assert.Len(t, flights, 1)
+	assert.Equal(t, `"v1"`, server.lastHeaders().Get("If-None-Match"))
+}
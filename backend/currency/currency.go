@@ -0,0 +1,136 @@
+// Package currency holds the currencies prices can be stored in and the exchange-rate
+// table used to convert between them. Rates are quoted against the US dollar and apply
+// from their effective date, so a price is converted with the rate of its day.
+package currency
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/currencies.csv
+var embeddedCurrencies []byte
+
+// USD is the currency exchange rates are quoted against.
+const USD = "USD"
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrNoRate          = errors.New("no exchange rate")
+)
+
+// Registry is an in-memory, read-only index of currencies.
+// It is safe for concurrent use once loaded.
+type Registry struct {
+	currencies []models.Currency
+	byCode     map[string]int
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+)
+
+// Default returns the registry built from the currency list embedded in the binary.
+// The list is parsed on the first call, so main calls it on startup to fail fast.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		registry, err := Load(bytes.NewReader(embeddedCurrencies))
+		if err != nil {
+			// The list is compiled in, so this can only happen with a broken build.
+			log.Fatalf("Failed to load embedded currency data: %v", err)
+		}
+		defaultRegistry = registry
+	})
+	return defaultRegistry
+}
+
+// Load reads currencies from a CSV with the header code,name,minor_units.
+func Load(r io.Reader) (*Registry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	registry := &Registry{byCode: make(map[string]int)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read currency: %w", err)
+		}
+
+		units, err := strconv.Atoi(record[2])
+		if err != nil || units < 0 || units > 4 {
+			return nil, fmt.Errorf("invalid minor units for %s: %q", record[0], record[2])
+		}
+
+		currency := models.Currency{Code: strings.ToUpper(record[0]), Name: record[1], MinorUnits: units}
+		registry.currencies = append(registry.currencies, currency)
+	}
+
+	sort.Slice(registry.currencies, func(i, j int) bool {
+		return registry.currencies[i].Code < registry.currencies[j].Code
+	})
+	for i, currency := range registry.currencies {
+		registry.byCode[currency.Code] = i
+	}
+	return registry, nil
+}
+
+// Lookup returns the currency with the given ISO 4217 code, case-insensitively.
+func (r *Registry) Lookup(code string) (models.Currency, bool) {
+	i, ok := r.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return models.Currency{}, false
+	}
+	return r.currencies[i], true
+}
+
+// Currencies returns every currency, sorted by code.
+func (r *Registry) Currencies() []models.Currency {
+	return append([]models.Currency(nil), r.currencies...)
+}
+
+// Normalize returns the upper-case code of a known currency, or ErrUnknownCurrency.
+func Normalize(code string) (string, error) {
+	currency, ok := Default().Lookup(code)
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return currency.Code, nil
+}
+
+// Format writes an amount in major units of its currency, e.g. "450.00 EUR" or "1875 JPY".
+// Amounts of unknown currencies are written in minor units.
+func Format(amount money.Money) string {
+	currency, ok := Default().Lookup(amount.Currency)
+	if !ok || currency.MinorUnits == 0 {
+		return fmt.Sprintf("%d %s", amount.Amount, amount.Currency)
+	}
+
+	value := amount.Amount
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	scale := int64(math.Pow10(currency.MinorUnits))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, value/scale, currency.MinorUnits, value%scale, currency.Code)
+}
@@ -0,0 +1,259 @@
+package currency
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed data/rates.csv
+var embeddedRates []byte
+
+// dateLayout is the layout of exchange-rate effective dates.
+const dateLayout = "2006-01-02"
+
+var (
+	defaultRatesOnce sync.Once
+	defaultRates     []models.ExchangeRate
+)
+
+// DefaultRates returns the exchange rates embedded in the binary. They are used for
+// the currencies and dates the admins did not load a rate for.
+func DefaultRates() []models.ExchangeRate {
+	defaultRatesOnce.Do(func() {
+		rates, err := ParseRates(bytes.NewReader(embeddedRates))
+		if err != nil {
+			// The table is compiled in, so this can only happen with a broken build.
+			log.Fatalf("Failed to load embedded exchange rates: %v", err)
+		}
+		defaultRates = rates
+	})
+	return append([]models.ExchangeRate(nil), defaultRates...)
+}
+
+// ParseRates reads exchange rates from a CSV with the header currency,effective_date,per_usd,
+// e.g. "EUR,2025-01-01,0.9615" for 1 USD = 0.9615 EUR from January 1st.
+func ParseRates(r io.Reader) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	var rates []models.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read exchange rate: %w", err)
+		}
+
+		perUSD, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s on %s: %q", record[0], record[1], record[2])
+		}
+		rate, err := ValidateRate(models.ExchangeRate{Currency: record[0], EffectiveDate: record[1], PerUSD: perUSD})
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// ValidateRate checks an exchange rate and returns it with its currency code normalized.
+func ValidateRate(rate models.ExchangeRate) (models.ExchangeRate, error) {
+	code, err := Normalize(rate.Currency)
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	if code == USD {
+		return models.ExchangeRate{}, fmt.Errorf("rates are quoted against %s, it has no rate of its own", USD)
+	}
+	date, err := time.Parse(dateLayout, strings.TrimSpace(rate.EffectiveDate))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("invalid effective date for %s: %q", code, rate.EffectiveDate)
+	}
+	if rate.PerUSD <= 0 || math.IsInf(rate.PerUSD, 0) || math.IsNaN(rate.PerUSD) {
+		return models.ExchangeRate{}, fmt.Errorf("rate for %s on %s must be positive", code, rate.EffectiveDate)
+	}
+	return models.ExchangeRate{Currency: code, EffectiveDate: date.Format(dateLayout), PerUSD: rate.PerUSD}, nil
+}
+
+// Table is an exchange-rate table with the rates of each currency sorted by effective date.
+// It is safe for concurrent use once built.
+type Table struct {
+	byCurrency map[string][]models.ExchangeRate
+}
+
+// NewTable builds a table from validated rates. A rate replaces an earlier one with the
+// same currency and effective date, so loaded rates can override the defaults.
+func NewTable(rates []models.ExchangeRate) *Table {
+	byDate := make(map[string]map[string]models.ExchangeRate)
+	for _, rate := range rates {
+		if byDate[rate.Currency] == nil {
+			byDate[rate.Currency] = make(map[string]models.ExchangeRate)
+		}
+		byDate[rate.Currency][rate.EffectiveDate] = rate
+	}
+
+	table := &Table{byCurrency: make(map[string][]models.ExchangeRate, len(byDate))}
+	for code, dates := range byDate {
+		list := make([]models.ExchangeRate, 0, len(dates))
+		for _, rate := range dates {
+			list = append(list, rate)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].EffectiveDate < list[j].EffectiveDate })
+		table.byCurrency[code] = list
+	}
+	return table
+}
+
+// LoadTable returns the embedded rates overridden by the rates loaded by the admins.
+func LoadTable(ctx context.Context, rdb *redis.Client) (*Table, error) {
+	loaded, err := store.ExchangeRates(ctx, rdb)
+	if err != nil {
+		return nil, err
+	}
+	return NewTable(append(DefaultRates(), loaded...)), nil
+}
+
+// Rates returns every rate of the table, sorted by currency and effective date.
+func (t *Table) Rates() []models.ExchangeRate {
+	codes := make([]string, 0, len(t.byCurrency))
+	for code := range t.byCurrency {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	rates := []models.ExchangeRate{}
+	for _, code := range codes {
+		rates = append(rates, t.byCurrency[code]...)
+	}
+	return rates
+}
+
+// Rate returns the rate of a currency in effect at the given time, or ErrNoRate if the
+// table has no rate for the currency on or before that day.
+func (t *Table) Rate(code string, at time.Time) (models.ExchangeRate, error) {
+	if code == USD {
+		return models.ExchangeRate{Currency: USD, PerUSD: 1}, nil
+	}
+
+	day := at.UTC().Format(dateLayout)
+	rates := t.byCurrency[code]
+	// The first rate that takes effect after the day; the one before it applies
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].EffectiveDate > day })
+	if i == 0 {
+		return models.ExchangeRate{}, fmt.Errorf("%w for %s on %s", ErrNoRate, code, day)
+	}
+	return rates[i-1], nil
+}
+
+// Convert converts an amount to another currency using the rates in effect at the given
+// time, and returns the conversion it applied. The result is rounded to the nearest minor
+// unit; it is the only rounding on the way.
+func (t *Table) Convert(amount money.Money, to string, at time.Time) (money.Money, models.Conversion, error) {
+	source, ok := Default().Lookup(amount.Currency)
+	if !ok {
+		return money.Money{}, models.Conversion{}, fmt.Errorf("%w %q", ErrUnknownCurrency, amount.Currency)
+	}
+	target, ok := Default().Lookup(to)
+	if !ok {
+		return money.Money{}, models.Conversion{}, fmt.Errorf("%w %q", ErrUnknownCurrency, to)
+	}
+
+	fromRate, err := t.Rate(source.Code, at)
+	if err != nil {
+		return money.Money{}, models.Conversion{}, err
+	}
+	toRate, err := t.Rate(target.Code, at)
+	if err != nil {
+		return money.Money{}, models.Conversion{}, err
+	}
+
+	conversion := models.Conversion{
+		From:          source.Code,
+		To:            target.Code,
+		Rate:          toRate.PerUSD / fromRate.PerUSD,
+		EffectiveDate: fromRate.EffectiveDate,
+		Amount:        amount.Amount,
+	}
+	if toRate.EffectiveDate > conversion.EffectiveDate {
+		conversion.EffectiveDate = toRate.EffectiveDate
+	}
+	if source.Code == target.Code {
+		return money.New(amount.Amount, target.Code), conversion, nil
+	}
+
+	// amount / 10^source units / fromRate * toRate * 10^target units, with exact rationals
+	value := new(big.Rat).SetInt64(amount.Amount)
+	value.Mul(value, rat(toRate.PerUSD))
+	value.Quo(value, rat(fromRate.PerUSD))
+	value.Mul(value, new(big.Rat).SetFrac(pow10(target.MinorUnits), pow10(source.MinorUnits)))
+	return money.New(roundRat(value), target.Code), conversion, nil
+}
+
+// ConvertFlight converts the price of a flight to another currency at the rates in effect
+// at the given time and states the conversion on the flight. PriceUSD is left as stored.
+func (t *Table) ConvertFlight(flight *models.Flight, to string, at time.Time) error {
+	converted, conversion, err := t.Convert(flight.Price, to, at)
+	if err != nil {
+		return err
+	}
+	flight.Price, flight.ExchangeRate = converted, &conversion
+	return nil
+}
+
+// ConvertObservation converts an observed price to another currency at the rates in effect
+// on the day it was observed.
+func (t *Table) ConvertObservation(observation *models.PriceObservation, to string) error {
+	converted, conversion, err := t.Convert(observation.Price, to, observation.ObservedAt)
+	if err != nil {
+		return err
+	}
+	observation.Price, observation.ExchangeRate = converted, &conversion
+	return nil
+}
+
+// rat returns the exact value of a rate as loaded, e.g. 0.9615 rather than its float64 approximation.
+func rat(rate float64) *big.Rat {
+	value, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	return value
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundRat rounds half away from zero to an integer.
+func roundRat(value *big.Rat) int64 {
+	negative := value.Sign() < 0
+	abs := new(big.Rat).Abs(value)
+	abs.Add(abs, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(abs.Num(), abs.Denom()).Int64()
+	if negative {
+		return -rounded
+	}
+	return rounded
+}
@@ -0,0 +1,120 @@
+package currency
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRate(t *testing.T) {
+	table := NewTable([]models.ExchangeRate{
+		{Currency: "EUR", EffectiveDate: "2025-01-01", PerUSD: 0.96},
+		{Currency: "EUR", EffectiveDate: "2025-03-01", PerUSD: 0.92},
+	})
+
+	tests := []struct {
+		name         string
+		at           time.Time
+		expectedRate float64
+		expectErr    bool
+	}{
+		{name: "before the first rate", at: time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC), expectErr: true},
+		{name: "on the effective date", at: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), expectedRate: 0.96},
+		{name: "until the next rate", at: time.Date(2025, 2, 28, 23, 59, 0, 0, time.UTC), expectedRate: 0.96},
+		{name: "latest rate", at: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), expectedRate: 0.92},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := table.Rate("EUR", tt.at)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrNoRate)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRate, rate.PerUSD)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	table := NewTable([]models.ExchangeRate{
+		{Currency: "EUR", EffectiveDate: "2025-01-01", PerUSD: 0.8},
+		{Currency: "JPY", EffectiveDate: "2025-02-01", PerUSD: 150},
+		{Currency: "KWD", EffectiveDate: "2025-01-01", PerUSD: 0.3},
+	})
+	at := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		amount         int64
+		from, to       string
+		expectedAmount int64
+	}{
+		{name: "same currency", amount: 12345, from: "USD", to: "USD", expectedAmount: 12345},
+		{name: "to euros", amount: 10000, from: "USD", to: "EUR", expectedAmount: 8000},
+		{name: "to dollars", amount: 8000, from: "EUR", to: "USD", expectedAmount: 10000},
+		{name: "cross rate without minor units", amount: 1000, from: "EUR", to: "JPY", expectedAmount: 1875},
+		{name: "three minor units", amount: 150, from: "USD", to: "KWD", expectedAmount: 450},
+		{name: "lower case codes", amount: 100, from: "usd", to: "eur", expectedAmount: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, conversion, err := table.Convert(money.New(tt.amount, tt.from), tt.to, at)
+			require.NoError(t, err)
+			assert.Equal(t, money.New(tt.expectedAmount, strings.ToUpper(tt.to)), amount)
+			assert.Equal(t, tt.amount, conversion.Amount)
+			assert.Equal(t, strings.ToUpper(tt.to), conversion.To)
+		})
+	}
+
+	_, conversion, err := table.Convert(money.New(1000, "EUR"), "JPY", at)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02-01", conversion.EffectiveDate, "the most recent rate used is stated")
+
+	_, _, err = table.Convert(money.New(100, "USD"), "XYZ", at)
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+	_, _, err = table.Convert(money.New(100, "USD"), "GBP", at)
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(strings.NewReader("currency,effective_date,per_usd\neur,2025-01-01,0.96\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []models.ExchangeRate{{Currency: "EUR", EffectiveDate: "2025-01-01", PerUSD: 0.96}}, rates)
+
+	for _, row := range []string{"XYZ,2025-01-01,1", "USD,2025-01-01,1", "EUR,01/01/2025,0.96", "EUR,2025-01-01,0", "EUR,2025-01-01,abc"} {
+		_, err := ParseRates(strings.NewReader("currency,effective_date,per_usd\n" + row + "\n"))
+		assert.Error(t, err, row)
+	}
+}
+
+func TestNewTableReplacesRatesOfTheSameDay(t *testing.T) {
+	table := NewTable(append(DefaultRates(), models.ExchangeRate{Currency: "EUR", EffectiveDate: "2025-01-01", PerUSD: 0.5}))
+
+	rate, err := table.Rate("EUR", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, rate.PerUSD)
+}
+
+func TestConvertUsesTheRatesAsLoaded(t *testing.T) {
+	// 0.9615 has no exact float64 representation; 10000 USD cents must still give 9615 EUR cents
+	table := NewTable([]models.ExchangeRate{{Currency: "EUR", EffectiveDate: "2025-01-01", PerUSD: 0.9615}})
+
+	amount, _, err := table.Convert(money.New(10000, "USD"), "EUR", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, money.New(9615, "EUR"), amount)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "450.00 EUR", Format(money.New(45000, "EUR")))
+	assert.Equal(t, "-0.05 USD", Format(money.New(-5, "USD")))
+	assert.Equal(t, "1875 JPY", Format(money.New(1875, "JPY")))
+	assert.Equal(t, "1.250 KWD", Format(money.New(1250, "KWD")))
+}
@@ -0,0 +1,149 @@
+// Package events carries flight change events from the crawler to the streaming endpoints.
+// Events are appended to a capped Redis stream, which gives them an ID and lets clients
+// resume after a disconnect, then published on a Redis channel so every replica sees them.
+package events
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// StreamKey is the Redis stream keeping recent events for resume.
+	StreamKey = "events"
+	// Channel is the Redis pub/sub channel live events are published on.
+	Channel = "events:live"
+	// maxStreamLength caps the stream; clients resuming from older events miss them.
+	maxStreamLength = 10000
+)
+
+// Publish stores an event in the stream and broadcasts it to every subscriber.
+// The event ID is set from the stream entry.
+func Publish(ctx context.Context, rdb *redis.Client, event *models.FlightEvent) error {
+	event.ID = ""
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamKey,
+		MaxLen: maxStreamLength,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("append to stream: %w", err)
+	}
+
+	event.ID = id
+	data, err = json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.Publish(ctx, Channel, data).Err()
+}
+
+// Since returns the events stored after the given event ID, oldest first.
+func Since(ctx context.Context, rdb *redis.Client, lastID string) ([]models.FlightEvent, error) {
+	if _, _, ok := parseID(lastID); !ok {
+		return nil, fmt.Errorf("invalid event ID %q", lastID)
+	}
+
+	messages, err := rdb.XRange(ctx, StreamKey, "("+lastID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.FlightEvent, 0, len(messages))
+	for _, message := range messages {
+		raw, ok := message.Values["event"].(string)
+		if !ok {
+			continue
+		}
+		var event models.FlightEvent
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			return nil, fmt.Errorf("unmarshal event %s: %w", message.ID, err)
+		}
+		event.ID = message.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Decode parses an event received on the pub/sub channel.
+func Decode(payload string) (models.FlightEvent, error) {
+	var event models.FlightEvent
+	err := json.Unmarshal([]byte(payload), &event)
+	return event, err
+}
+
+// IsAfter reports whether event ID a comes after event ID b. An empty b is before everything.
+func IsAfter(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	aMs, aSeq, okA := parseID(a)
+	bMs, bSeq, okB := parseID(b)
+	if !okA || !okB {
+		return a > b
+	}
+	return aMs > bMs || (aMs == bMs && aSeq > bSeq)
+}
+
+// parseID splits a Redis stream ID "<ms>-<seq>".
+func parseID(id string) (uint64, uint64, bool) {
+	msText, seqText, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// Filter selects the events a client is interested in. Empty fields match every event.
+type Filter struct {
+	FlightID     string          // Flight identity, e.g. DL123-2025-04-28
+	Origins      map[string]bool // Departure airport codes
+	Destinations map[string]bool // Arrival airport codes
+	Date         string          // Departure date prefix, e.g. 2025-04-28
+	FlightNumber string
+}
+
+// Matches reports whether an event passes the filter.
+func (f Filter) Matches(event models.FlightEvent) bool {
+	return f.MatchesFlight(event.Flight)
+}
+
+// MatchesFlight reports whether a flight passes the filter.
+func (f Filter) MatchesFlight(flight models.Flight) bool {
+	if f.FlightID != "" && !strings.EqualFold(f.FlightID, flight.ID) {
+		return false
+	}
+	if len(f.Origins) > 0 && !f.Origins[strings.ToUpper(flight.DepartureAirport.Code)] {
+		return false
+	}
+	if len(f.Destinations) > 0 && !f.Destinations[strings.ToUpper(flight.ArrivalAirport.Code)] {
+		return false
+	}
+	if f.Date != "" && !strings.HasPrefix(flight.DepartureTime, f.Date) {
+		return false
+	}
+	if f.FlightNumber != "" && !strings.EqualFold(strings.ReplaceAll(f.FlightNumber, " ", ""), flight.FlightNumber) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"FlightAPI/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAfter(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "1714300000000-0", b: "", expected: true},
+		{a: "1714300000000-1", b: "1714300000000-0", expected: true},
+		{a: "1714300000001-0", b: "1714300000000-5", expected: true},
+		{a: "1714300000000-0", b: "1714300000000-0", expected: false},
+		// Numeric comparison, not lexical: 9 < 10
+		{a: "9-0", b: "10-0", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" after "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsAfter(tt.a, tt.b))
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	event := models.FlightEvent{Flight: models.Flight{
+		FlightNumber:     "DL123",
+		DepartureAirport: models.Airport{Code: "JNB"},
+		ArrivalAirport:   models.Airport{Code: "ATL"},
+		DepartureTime:    "2025-04-28T10:00:00Z",
+	}}
+
+	assert.True(t, Filter{}.Matches(event))
+	assert.True(t, Filter{Origins: map[string]bool{"JNB": true}, Date: "2025-04-28"}.Matches(event))
+	assert.True(t, Filter{FlightNumber: "dl 123"}.Matches(event))
+	assert.False(t, Filter{Destinations: map[string]bool{"JFK": true}}.Matches(event))
+	assert.False(t, Filter{Date: "2025-04-29"}.Matches(event))
+}
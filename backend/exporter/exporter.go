@@ -0,0 +1,246 @@
+// Package exporter writes flights in the formats API responses can be negotiated in:
+// JSON, CSV, NDJSON and XML. CSV columns are named like the importer fields, so an
+// export can be imported again.
+package exporter
+
+import (
+	"FlightAPI/models"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is the format flights are written in.
+type Format string
+
+const (
+	JSON   Format = "json"
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XML    Format = "xml"
+)
+
+// flushEvery is how many NDJSON lines are written between two flushes of a streamed response.
+const flushEvery = 100
+
+// mediaTypes maps the media types of an Accept header to the format they ask for.
+var mediaTypes = map[string]Format{
+	"application/json":     JSON,
+	"text/csv":             CSV,
+	"application/x-ndjson": NDJSON,
+	"application/ndjson":   NDJSON,
+	"application/jsonl":    NDJSON,
+	"application/xml":      XML,
+	"text/xml":             XML,
+}
+
+// Columns are the CSV columns, in the order they are written. New columns go at the end
+// so spreadsheets reading them by position keep working.
+var Columns = []string{
+	"id", "flightNumber", "carrierCode", "number", "airline", "departureAirport",
+	"arrivalAirport", "departureTime", "arrivalTime", "duration", "status", "aircraft",
+	"class", "fareBasis", "priceUSD", "priceAmount", "currency", "seatsRemaining",
+	"checkedBags", "checkedWeightKg", "refundable", "refundFee", "changeable", "changeFee",
+}
+
+// ParseFormat returns the format with the given name, as in a format= query parameter.
+func ParseFormat(value string) (Format, bool) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case JSON, CSV, NDJSON, XML:
+		return format, true
+	case "jsonl":
+		return NDJSON, true
+	}
+	return "", false
+}
+
+// Negotiate returns the format an Accept header prefers, honouring quality values.
+// An empty header and wildcards get JSON. It returns false if no format is acceptable.
+func Negotiate(accept string) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, true
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType, quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	for _, r := range ranges {
+		if format, ok := mediaTypes[r.mediaType]; ok {
+			return format, true
+		}
+		switch r.mediaType {
+		case "*/*", "application/*":
+			return JSON, true
+		case "text/*":
+			return CSV, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the media type of a format, for the Content-Type header.
+func ContentType(format Format) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	case XML:
+		return "application/xml; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// WriteFares writes flight records, one per fare, in a format.
+func WriteFares(w io.Writer, format Format, flights []models.Flight) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, flights)
+	case NDJSON:
+		return writeNDJSON(w, len(flights), func(i int) any { return flights[i] })
+	case XML:
+		fares := xmlFares{Fares: make([]xmlFare, len(flights))}
+		for i, flight := range flights {
+			fares.Fares[i] = xmlFare{xmlScheduleOf(models.FlightOf(flight)), xmlFareOf(flight.Fare())}
+		}
+		return writeXML(w, fares)
+	}
+	return json.NewEncoder(w).Encode(flights)
+}
+
+// WriteFlights writes flights with their fares grouped under them in a format. CSV has no
+// nesting, so it has one row per fare like WriteFares.
+func WriteFlights(w io.Writer, format Format, flights []models.FlightFares) error {
+	switch format {
+	case CSV:
+		var fares []models.Flight
+		for _, flight := range flights {
+			fares = append(fares, flight.Flights()...)
+		}
+		return writeCSV(w, fares)
+	case NDJSON:
+		return writeNDJSON(w, len(flights), func(i int) any { return flights[i] })
+	case XML:
+		grouped := xmlFlights{Flights: make([]xmlFlight, len(flights))}
+		for i, flight := range flights {
+			grouped.Flights[i] = xmlFlight{xmlSchedule: xmlScheduleOf(flight), Fares: make([]xmlFareDetails, len(flight.Fares))}
+			for j, fare := range flight.Fares {
+				grouped.Flights[i].Fares[j] = xmlFareOf(fare)
+			}
+		}
+		return writeXML(w, grouped)
+	}
+	return json.NewEncoder(w).Encode(flights)
+}
+
+func writeCSV(w io.Writer, flights []models.Flight) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(Columns); err != nil {
+		return err
+	}
+	for _, flight := range flights {
+		if err := out.Write(csvRow(flight)); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvRow returns the cells of a fare in the order of Columns. Details the provider didn't
+// give are left empty. Text from providers goes through csvText; times and numbers are ours.
+func csvRow(flight models.Flight) []string {
+	row := []string{
+		csvText(flight.ID), csvText(flight.FlightNumber), csvText(flight.CarrierCode), csvText(flight.Number),
+		csvText(flight.Airline), csvText(flight.DepartureAirport.Code), csvText(flight.ArrivalAirport.Code),
+		flight.DepartureTime, flight.ArrivalTime, flight.Duration, csvText(string(flight.Status)),
+		csvText(flight.Aircraft), csvText(string(flight.Class)), csvText(flight.FareBasis), flight.PriceUSD.String(),
+		strconv.FormatInt(flight.Price.Amount, 10), csvText(flight.Price.Currency), "", "", "", "", "", "", "",
+	}
+	if flight.SeatsRemaining != nil {
+		row[17] = strconv.Itoa(*flight.SeatsRemaining)
+	}
+	if flight.Baggage != nil {
+		row[18] = strconv.Itoa(flight.Baggage.CheckedBags)
+		if flight.Baggage.CheckedWeightKg != 0 {
+			row[19] = strconv.Itoa(flight.Baggage.CheckedWeightKg)
+		}
+	}
+	if rules := flight.FareRules; rules != nil {
+		row[20] = strconv.FormatBool(rules.Refundable)
+		row[21] = formatFee(rules.RefundFee)
+		row[22] = strconv.FormatBool(rules.Changeable)
+		row[23] = formatFee(rules.ChangeFee)
+	}
+	return row
+}
+
+// csvText keeps spreadsheets from running a text cell as a formula by prefixing a quote
+// when it starts like one, e.g. an airline named "=HYPERLINK(...)".
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatFee(fee *int64) string {
+	if fee == nil {
+		return ""
+	}
+	return strconv.FormatInt(*fee, 10)
+}
+
+// writeNDJSON writes count records, one JSON object per line, flushing as it goes when
+// w is a streamed response.
+func writeNDJSON(w io.Writer, count int, record func(i int) any) error {
+	flusher, _ := w.(interface{ Flush() })
+	encoder := json.NewEncoder(w)
+	for i := 0; i < count; i++ {
+		if err := encoder.Encode(record(i)); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%flushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+func writeXML(w io.Writer, value any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("encode xml: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,194 @@
+package exporter
+
+import (
+	"FlightAPI/importer"
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFlights() []models.Flight {
+	seats := 4
+	fee := int64(5000)
+	return []models.Flight{
+		{
+			ID: "DL123-2025-04-28", FlightNumber: "DL123", CarrierCode: "DL", Number: "123", Airline: "Delta",
+			DepartureAirport: models.Airport{Code: "ATL"}, ArrivalAirport: models.Airport{Code: "JFK"},
+			DepartureTime: "2025-04-28T10:00:00-04:00", ArrivalTime: "2025-04-28T12:15:00-04:00",
+			Duration: "2h15m", Status: models.StatusScheduled, Class: models.CabinEconomy, FareBasis: "YLOWUS",
+			SeatsRemaining: &seats, Baggage: &models.Baggage{CheckedBags: 1},
+			FareRules: &models.FareRules{Refundable: false, Changeable: true, ChangeFee: &fee},
+			PriceUSD:  25050, Price: money.New(25050, "USD"),
+		},
+		{
+			ID: "DL123-2025-04-28", FlightNumber: "DL123", CarrierCode: "DL", Number: "123", Airline: "Delta, Inc.",
+			DepartureAirport: models.Airport{Code: "ATL"}, ArrivalAirport: models.Airport{Code: "JFK"},
+			DepartureTime: "2025-04-28T10:00:00-04:00", ArrivalTime: "2025-04-28T12:15:00-04:00",
+			Duration: "2h15m", Status: models.StatusScheduled, Class: models.CabinBusiness,
+			PriceUSD: 90000, Price: money.New(90000, "USD"),
+			Warnings: []models.QualityWarning{{Rule: "price-outlier", Message: "Price is 3x the route median"}},
+		},
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		format Format
+		ok     bool
+	}{
+		{"", JSON, true},
+		{"*/*", JSON, true},
+		{"application/json", JSON, true},
+		{"text/csv", CSV, true},
+		{"text/csv; charset=utf-8", CSV, true},
+		{"application/x-ndjson", NDJSON, true},
+		{"application/xml", XML, true},
+		{"text/xml", XML, true},
+		{"text/*", CSV, true},
+		{"application/json;q=0.5, text/csv", CSV, true},
+		{"text/csv;q=0.2, application/xml;q=0.9, */*;q=0.1", XML, true},
+		{"text/csv;q=0, application/json", JSON, true},
+		{"image/png", "", false},
+		{"text/csv;q=0", "", false},
+	}
+	for _, tt := range tests {
+		format, ok := Negotiate(tt.accept)
+		assert.Equal(t, tt.ok, ok, tt.accept)
+		assert.Equal(t, tt.format, format, tt.accept)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, ok := ParseFormat("CSV")
+	assert.True(t, ok)
+	assert.Equal(t, CSV, format)
+
+	format, ok = ParseFormat("jsonl")
+	assert.True(t, ok)
+	assert.Equal(t, NDJSON, format)
+
+	_, ok = ParseFormat("xlsx")
+	assert.False(t, ok)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, CSV, testFlights()))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, Columns, rows[0])
+		assert.Equal(t, []string{
+			"DL123-2025-04-28", "DL123", "DL", "123", "Delta", "ATL", "JFK", "2025-04-28T10:00:00-04:00",
+			"2025-04-28T12:15:00-04:00", "2h15m", "Scheduled", "", "Economy", "YLOWUS", "250.5", "25050", "USD",
+			"4", "1", "", "false", "", "true", "5000",
+		}, rows[1])
+		// Details the provider didn't give are left empty, and commas are quoted
+		assert.Equal(t, "Delta, Inc.", rows[2][4])
+		assert.Equal(t, []string{"", "", "", "", "", "", ""}, rows[2][17:])
+	}
+}
+
+func TestWriteCSVNeutralisesFormulas(t *testing.T) {
+	flight := testFlights()[0]
+	flight.Airline = "=HYPERLINK(\"http://example.com\",\"Delta\")"
+	flight.FlightNumber = "+DL123"
+	flight.Aircraft = "-1+1"
+	flight.FareBasis = "@SUM(A1:A2)"
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, CSV, []models.Flight{flight}))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "'=HYPERLINK(\"http://example.com\",\"Delta\")", rows[1][4])
+		assert.Equal(t, "'+DL123", rows[1][1])
+		assert.Equal(t, "'-1+1", rows[1][11])
+		assert.Equal(t, "'@SUM(A1:A2)", rows[1][13])
+		// Times with a negative offset are left as they are
+		assert.Equal(t, "2025-04-28T10:00:00-04:00", rows[1][7])
+	}
+}
+
+func TestWriteCSVCanBeImported(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, CSV, testFlights()))
+
+	records, err := importer.Read(&out, importer.CSV, importer.Mapping{})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		var flight models.Flight
+		assert.NoError(t, json.Unmarshal(records[0], &flight))
+		assert.Equal(t, "DL123", flight.FlightNumber)
+		assert.Equal(t, money.USD(25050), flight.PriceUSD)
+		assert.Equal(t, 1, flight.Baggage.CheckedBags)
+		assert.True(t, flight.FareRules.Changeable)
+	}
+}
+
+func TestWriteCSVGroupedHasOneRowPerFare(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFlights(&out, CSV, models.GroupFares(testFlights())))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, CSV, nil))
+	assert.Equal(t, strings.Join(Columns, ",")+"\n", out.String())
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, NDJSON, testFlights()))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		var flight models.Flight
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &flight))
+		assert.Equal(t, models.CabinBusiness, flight.Class)
+		assert.Equal(t, money.New(90000, "USD"), flight.Price)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFares(&out, XML, testFlights()))
+
+	text := out.String()
+	assert.True(t, strings.HasPrefix(text, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, text, "<flights>")
+	assert.Contains(t, text, `<departureAirport code="ATL"></departureAirport>`)
+	assert.Contains(t, text, "<priceUSD>250.5</priceUSD>")
+	assert.Contains(t, text, "<checkedBags>1</checkedBags>")
+	assert.Contains(t, text, "<changeFee>5000</changeFee>")
+	assert.Contains(t, text, "<airline>Delta, Inc.</airline>")
+	assert.Equal(t, 2, strings.Count(text, "<flight>"))
+	// Only fares with warnings have a warnings element
+	assert.Equal(t, 1, strings.Count(text, "<warnings>"))
+}
+
+func TestWriteXMLGrouped(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteFlights(&out, XML, models.GroupFares(testFlights())))
+
+	text := out.String()
+	assert.Equal(t, 1, strings.Count(text, "<flight>"))
+	assert.Equal(t, 2, strings.Count(text, "<fare>"))
+	assert.Contains(t, text, "<class>Business</class>")
+	assert.Contains(t, text, `<warnings>
+          <warning rule="price-outlier">Price is 3x the route median</warning>
+        </warnings>`)
+}
@@ -0,0 +1,145 @@
+package exporter
+
+import (
+	"FlightAPI/models"
+	"encoding/xml"
+)
+
+// The XML documents follow the JSON field names. Their structs are kept apart from the
+// models so the JSON encoding of the API stays the only concern of the models.
+
+type xmlFlights struct {
+	XMLName xml.Name    `xml:"flights"`
+	Flights []xmlFlight `xml:"flight"`
+}
+
+// xmlFlight is a flight with its fares, as written by WriteFlights.
+type xmlFlight struct {
+	xmlSchedule
+	Fares []xmlFareDetails `xml:"fares>fare"`
+}
+
+type xmlFares struct {
+	XMLName xml.Name  `xml:"flights"`
+	Fares   []xmlFare `xml:"flight"`
+}
+
+// xmlFare is one fare record, as written by WriteFares.
+type xmlFare struct {
+	xmlSchedule
+	xmlFareDetails
+}
+
+type xmlSchedule struct {
+	ID               string     `xml:"id"`
+	FlightNumber     string     `xml:"flightNumber"`
+	CarrierCode      string     `xml:"carrierCode,omitempty"`
+	Number           string     `xml:"number,omitempty"`
+	Airline          string     `xml:"airline"`
+	DepartureAirport xmlAirport `xml:"departureAirport"`
+	ArrivalAirport   xmlAirport `xml:"arrivalAirport"`
+	DepartureTime    string     `xml:"departureTime"`
+	ArrivalTime      string     `xml:"arrivalTime"`
+	Duration         string     `xml:"duration"`
+	Aircraft         string     `xml:"aircraft,omitempty"`
+	Status           string     `xml:"status"`
+}
+
+type xmlAirport struct {
+	Code     string `xml:"code,attr"`
+	Name     string `xml:"name,attr,omitempty"`
+	City     string `xml:"city,attr,omitempty"`
+	Country  string `xml:"country,attr,omitempty"`
+	Timezone string `xml:"timezone,attr,omitempty"`
+}
+
+type xmlFareDetails struct {
+	Class          string         `xml:"class"`
+	FareBasis      string         `xml:"fareBasis,omitempty"`
+	Baggage        *xmlBaggage    `xml:"baggage,omitempty"`
+	FareRules      *xmlFareRules  `xml:"fareRules,omitempty"`
+	SeatsRemaining *int           `xml:"seatsRemaining,omitempty"`
+	PriceUSD       string         `xml:"priceUSD"`
+	PriceAmount    int64          `xml:"priceAmount"`
+	Currency       string         `xml:"currency,omitempty"`
+	ExchangeRate   *xmlConversion `xml:"exchangeRate,omitempty"`
+	Warnings       *xmlWarnings   `xml:"warnings,omitempty"`
+}
+
+type xmlBaggage struct {
+	CheckedBags     int `xml:"checkedBags"`
+	CheckedWeightKg int `xml:"checkedWeightKg,omitempty"`
+}
+
+type xmlFareRules struct {
+	Refundable bool   `xml:"refundable"`
+	RefundFee  *int64 `xml:"refundFee,omitempty"`
+	Changeable bool   `xml:"changeable"`
+	ChangeFee  *int64 `xml:"changeFee,omitempty"`
+}
+
+// xmlWarnings wraps the warnings of a fare, so fares without any have no warnings element.
+type xmlWarnings struct {
+	Warnings []xmlWarning `xml:"warning"`
+}
+
+type xmlWarning struct {
+	Rule    string `xml:"rule,attr"`
+	Message string `xml:",chardata"`
+}
+
+type xmlConversion struct {
+	From          string  `xml:"from,attr"`
+	To            string  `xml:"to,attr"`
+	Rate          float64 `xml:"rate,attr"`
+	EffectiveDate string  `xml:"effectiveDate,attr"`
+	Amount        int64   `xml:"amount,attr"`
+}
+
+func xmlScheduleOf(flight models.FlightFares) xmlSchedule {
+	return xmlSchedule{
+		ID:               flight.ID,
+		FlightNumber:     flight.FlightNumber,
+		CarrierCode:      flight.CarrierCode,
+		Number:           flight.Number,
+		Airline:          flight.Airline,
+		DepartureAirport: xmlAirportOf(flight.DepartureAirport),
+		ArrivalAirport:   xmlAirportOf(flight.ArrivalAirport),
+		DepartureTime:    flight.DepartureTime,
+		ArrivalTime:      flight.ArrivalTime,
+		Duration:         flight.Duration,
+		Aircraft:         flight.Aircraft,
+		Status:           string(flight.Status),
+	}
+}
+
+func xmlAirportOf(airport models.Airport) xmlAirport {
+	return xmlAirport{Code: airport.Code, Name: airport.Name, City: airport.City, Country: airport.Country, Timezone: airport.Timezone}
+}
+
+func xmlFareOf(fare models.Fare) xmlFareDetails {
+	details := xmlFareDetails{
+		Class:          string(fare.Class),
+		FareBasis:      fare.FareBasis,
+		SeatsRemaining: fare.SeatsRemaining,
+		PriceUSD:       fare.PriceUSD.String(),
+		PriceAmount:    fare.Price.Amount,
+		Currency:       fare.Price.Currency,
+	}
+	if baggage := fare.Baggage; baggage != nil {
+		details.Baggage = &xmlBaggage{CheckedBags: baggage.CheckedBags, CheckedWeightKg: baggage.CheckedWeightKg}
+	}
+	if rules := fare.FareRules; rules != nil {
+		details.FareRules = &xmlFareRules{Refundable: rules.Refundable, RefundFee: rules.RefundFee, Changeable: rules.Changeable, ChangeFee: rules.ChangeFee}
+	}
+	if len(fare.Warnings) > 0 {
+		details.Warnings = &xmlWarnings{}
+		for _, warning := range fare.Warnings {
+			details.Warnings.Warnings = append(details.Warnings.Warnings, xmlWarning{Rule: warning.Rule, Message: warning.Message})
+		}
+	}
+	if rate := fare.ExchangeRate; rate != nil {
+		details.ExchangeRate = &xmlConversion{From: rate.From, To: rate.To, Rate: rate.Rate, EffectiveDate: rate.EffectiveDate, Amount: rate.Amount}
+	}
+	return details
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// CancelBooking cancels one of the current user's bookings and gives its seats back to the
+// inventory. Confirmed bookings can be cancelled too, e.g. when the payment is refunded.
+func CancelBooking(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	booking, ok := ownBooking(ctx, rdb, ctx.Param("locator"))
+	if !ok {
+		return
+	}
+
+	cancelled, err := store.CancelBooking(ctx.Request.Context(), rdb, booking, time.Now())
+	if errors.Is(err, store.ErrBookingClosed) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Booking is " + string(cancelled.Status), "booking": cancelled})
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling booking %s: %v", booking.Locator, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		return
+	}
+
+	log.Printf("Cancelled booking %s of %d segments", booking.Locator, len(booking.Segments))
+	ctx.JSON(http.StatusOK, cancelled)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ConfirmBooking confirms one of the current user's pending bookings, e.g. once payment went
+// through, so the seats of all its holds are sold. A booking whose holds expired is
+// cancelled instead.
+func ConfirmBooking(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	booking, ok := ownBooking(ctx, rdb, ctx.Param("locator"))
+	if !ok {
+		return
+	}
+
+	confirmed, err := store.ConfirmBooking(ctx.Request.Context(), rdb, booking, time.Now())
+	switch {
+	case errors.Is(err, store.ErrBookingClosed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Booking is " + string(confirmed.Status), "booking": confirmed})
+		return
+	case errors.Is(err, store.ErrHoldNotActive):
+		log.Printf("Cancelled booking %s on confirmation: %v", booking.Locator, err)
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Booking expired before it was confirmed", "booking": confirmed})
+		return
+	case err != nil:
+		log.Printf("Error confirming booking %s: %v", booking.Locator, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm booking"})
+		return
+	}
+
+	log.Printf("Confirmed booking %s of %d segments", booking.Locator, len(booking.Segments))
+	ctx.JSON(http.StatusOK, confirmed)
+}
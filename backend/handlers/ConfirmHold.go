@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ConfirmHold confirms one of the current user's active holds, e.g. once payment went
+// through, so its seats are sold. Holds past their expiry can't be confirmed.
+func ConfirmHold(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	hold, ok := ownHold(ctx, rdb, ctx.Param("id"))
+	if !ok {
+		return
+	}
+
+	confirmed, err := store.ConfirmHold(ctx.Request.Context(), rdb, hold.ID, time.Now())
+	// The seats of a booked hold follow its booking
+	if errors.Is(err, store.ErrHoldBooked) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Hold belongs to booking " + confirmed.Booking + ", confirm the booking instead", "hold": confirmed})
+		return
+	}
+	if errors.Is(err, store.ErrHoldNotActive) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Hold is " + string(confirmed.Status), "hold": confirmed})
+		return
+	}
+	if err != nil {
+		log.Printf("Error confirming hold %s: %v", hold.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm hold"})
+		return
+	}
+
+	log.Printf("Confirmed hold %s of %d %s seats on %s", hold.ID, hold.Seats, hold.Class, hold.FlightID)
+	ctx.JSON(http.StatusOK, confirmed)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"FlightAPI/airports"
+	"FlightAPI/alerts"
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type createAlertRequest struct {
+	Name           string    `json:"name"`
+	Origin         string    `json:"origin" binding:"required"`
+	Destination    string    `json:"destination" binding:"required"`
+	DateFrom       string    `json:"dateFrom"`
+	DateTo         string    `json:"dateTo"`
+	Class          string    `json:"class"`
+	TargetPriceUSD money.USD `json:"targetPriceUSD"`
+	DropPercent    float64   `json:"dropPercent"`
+	WebhookURL     string    `json:"webhookUrl" binding:"required"`
+}
+
+// CreateAlert registers a price alert for the current user.
+// The response contains the webhook secret used to sign deliveries; it is not shown again.
+func CreateAlert(ctx *gin.Context) {
+	var req createAlertRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := validateAlertRequest(ctx.Request.Context(), req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	// Alerts without a class match every cabin
+	class, _ := models.ParseCabinClass(req.Class)
+
+	alert := models.Alert{
+		ID:             store.NewID(),
+		Owner:          ctx.GetString("username"),
+		Name:           req.Name,
+		Origin:         strings.ToUpper(req.Origin),
+		Destination:    strings.ToUpper(req.Destination),
+		DateFrom:       req.DateFrom,
+		DateTo:         req.DateTo,
+		Class:          class,
+		TargetPriceUSD: req.TargetPriceUSD,
+		DropPercent:    req.DropPercent,
+		WebhookURL:     req.WebhookURL,
+		WebhookSecret:  alerts.NewSecret(),
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := store.SaveAlert(ctx.Request.Context(), rdb, alert); err != nil {
+		log.Printf("Error saving alert: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save alert"})
+		return
+	}
+
+	log.Printf("Created alert %s for %s", alert.ID, alert.Owner)
+	ctx.JSON(http.StatusCreated, alert)
+}
+
+// validateAlertRequest checks the route, dates, thresholds and webhook URL of a new alert.
+func validateAlertRequest(ctx context.Context, req createAlertRequest) error {
+	if _, err := airports.Default().Expand(req.Origin, 0); err != nil {
+		return fmt.Errorf("invalid origin")
+	}
+	if _, err := airports.Default().Expand(req.Destination, 0); err != nil {
+		return fmt.Errorf("invalid destination")
+	}
+
+	for _, date := range []string{req.DateFrom, req.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("dates must use the YYYY-MM-DD format")
+		}
+	}
+	if req.DateFrom != "" && req.DateTo != "" && req.DateFrom > req.DateTo {
+		return fmt.Errorf("dateFrom must not be after dateTo")
+	}
+
+	if _, ok := models.ParseCabinClass(req.Class); req.Class != "" && !ok {
+		return fmt.Errorf("class must be economy, premium economy, business or first")
+	}
+
+	if req.TargetPriceUSD < 0 || req.DropPercent < 0 || req.DropPercent >= 100 {
+		return fmt.Errorf("targetPriceUSD must be positive and dropPercent between 0 and 100")
+	}
+	if req.TargetPriceUSD == 0 && req.DropPercent == 0 {
+		return fmt.Errorf("either targetPriceUSD or dropPercent is required")
+	}
+
+	// Webhooks are called from inside our network, so they must not point back into it
+	return alerts.CheckWebhookURL(ctx, req.WebhookURL)
+}
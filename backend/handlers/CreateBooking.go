@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxNameLength bounds passenger first and last names, as printed on a ticket.
+const maxNameLength = 64
+
+type createBookingRequest struct {
+	HoldIDs    []string           `json:"holdIds" binding:"required"`
+	Passengers []models.Passenger `json:"passengers" binding:"required"`
+	Contact    models.Contact     `json:"contact" binding:"required"`
+}
+
+// CreateBooking books the current user's active holds for named passengers under a new
+// record locator. Every hold must have one seat per passenger. The booking stays pending
+// until it is confirmed, and is cancelled if its holds expire first.
+func CreateBooking(ctx *gin.Context) {
+	var req createBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if len(req.HoldIDs) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "holdIds must list at least one hold"})
+		return
+	}
+	if len(req.Passengers) == 0 || len(req.Passengers) > maxHoldSeats {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passengers must list between 1 and %d passengers", maxHoldSeats)})
+		return
+	}
+	for i, passenger := range req.Passengers {
+		passenger.FirstName = strings.TrimSpace(passenger.FirstName)
+		passenger.LastName = strings.TrimSpace(passenger.LastName)
+		if passenger.FirstName == "" || passenger.LastName == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passenger %d needs a first and last name", i+1)})
+			return
+		}
+		if len(passenger.FirstName) > maxNameLength || len(passenger.LastName) > maxNameLength {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passenger names can't be longer than %d characters", maxNameLength)})
+			return
+		}
+		req.Passengers[i] = passenger
+	}
+	address, err := mail.ParseAddress(req.Contact.Email)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "contact.email must be a valid email address"})
+		return
+	}
+	req.Contact.Email = address.Address
+	req.Contact.Phone = strings.TrimSpace(req.Contact.Phone)
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	now := time.Now().UTC()
+	booking := models.Booking{
+		Owner:      ctx.GetString("username"),
+		Status:     models.BookingPending,
+		Passengers: req.Passengers,
+		Contact:    req.Contact,
+		CreatedAt:  now,
+	}
+
+	seen := map[string]bool{}
+	var flightIDs []string
+	for _, holdID := range req.HoldIDs {
+		if seen[holdID] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Hold " + holdID + " is listed twice"})
+			return
+		}
+		seen[holdID] = true
+
+		hold, ok := ownHold(ctx, rdb, holdID)
+		if !ok {
+			return
+		}
+		if hold.Status != models.HoldActive || !hold.ExpiresAt.After(now) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Hold " + hold.ID + " is no longer held", "hold": hold})
+			return
+		}
+		if hold.Booking != "" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Hold " + hold.ID + " already belongs to booking " + hold.Booking})
+			return
+		}
+		if hold.Seats != len(req.Passengers) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Hold %s has %d seats for %d passengers", hold.ID, hold.Seats, len(req.Passengers))})
+			return
+		}
+
+		booking.Segments = append(booking.Segments, models.Segment{HoldID: hold.ID, FlightID: hold.FlightID, Class: hold.Class, Seats: hold.Seats})
+		flightIDs = append(flightIDs, hold.FlightID)
+		if booking.ExpiresAt.IsZero() || hold.ExpiresAt.Before(booking.ExpiresAt) {
+			booking.ExpiresAt = hold.ExpiresAt
+		}
+	}
+
+	// Describe each segment with the schedule of its flight, when the crawler has seen it
+	infos, err := store.FlightInfos(ctx.Request.Context(), rdb, flightIDs)
+	if err != nil {
+		log.Printf("Error fetching flights of booking: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+	for i, segment := range booking.Segments {
+		info, ok := infos[segment.FlightID]
+		if !ok {
+			continue
+		}
+		segment.FlightNumber = info.FlightNumber
+		segment.DepartureAirport = info.DepartureAirport.Code
+		segment.ArrivalAirport = info.ArrivalAirport.Code
+		segment.DepartureTime = info.DepartureTime
+		segment.ArrivalTime = info.ArrivalTime
+		booking.Segments[i] = segment
+	}
+	sort.SliceStable(booking.Segments, func(i, j int) bool { return departsBefore(booking.Segments[i], booking.Segments[j]) })
+
+	created, err := store.CreateBooking(ctx.Request.Context(), rdb, booking)
+	switch {
+	case errors.Is(err, store.ErrHoldNotActive):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A hold is no longer held"})
+		return
+	case errors.Is(err, store.ErrHoldBooked):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A hold already belongs to a booking"})
+		return
+	case err != nil:
+		log.Printf("Error creating booking for %s: %v", booking.Owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
+		return
+	}
+
+	log.Printf("Booking %s of %d segments for %d passengers by %s", created.Locator, len(created.Segments), len(created.Passengers), created.Owner)
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// departsBefore reports whether segment a departs before b. Segments of flights the crawler
+// hasn't seen sort last.
+func departsBefore(a, b models.Segment) bool {
+	departureA, err := time.Parse(time.RFC3339, a.DepartureTime)
+	if err != nil {
+		return false
+	}
+	departureB, err := time.Parse(time.RFC3339, b.DepartureTime)
+	if err != nil {
+		return true
+	}
+	return departureA.Before(departureB)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// CreateCalendarFeed gives the current user a calendar feed URL to subscribe to from a
+// calendar app. The feed shows the flights of their bookings and watchlist and follows
+// their schedule and status. Creating a feed again revokes the previous URL.
+func CreateCalendarFeed(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	owner := ctx.GetString("username")
+	token, err := store.CreateCalendarFeed(ctx.Request.Context(), rdb, owner)
+	if err != nil {
+		log.Printf("Error creating calendar feed for %s: %v", owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	log.Printf("Created calendar feed for %s", owner)
+	ctx.JSON(http.StatusCreated, gin.H{"url": calendarFeedURL(ctx, token)})
+}
+
+// calendarFeedURL returns the absolute URL of a calendar feed, as the client reached the API.
+func calendarFeedURL(ctx *gin.Context, token string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host + "/calendar/" + token + ".ics"
+}
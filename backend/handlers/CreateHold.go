@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// maxHoldSeats is how many seats one hold can take, as on a booking.
+	maxHoldSeats = 9
+	// defaultHoldTTL is how long a hold lasts when the request doesn't say.
+	defaultHoldTTL = 15 * time.Minute
+	// minHoldTTL and maxHoldTTL bound the ttlSeconds of a hold request.
+	minHoldTTL = time.Minute
+	maxHoldTTL = time.Hour
+)
+
+type createHoldRequest struct {
+	FlightID   string `json:"flightId" binding:"required"`
+	Class      string `json:"class" binding:"required"`
+	Seats      int    `json:"seats"`
+	TTLSeconds int    `json:"ttlSeconds"`
+}
+
+// CreateHold takes seats of a flight fare from the inventory for the current user until
+// the hold expires. Holds that are not confirmed in time give their seats back.
+func CreateHold(ctx *gin.Context) {
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	class, ok := models.ParseCabinClass(req.Class)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "class must be economy, premium economy, business or first"})
+		return
+	}
+	if req.Seats == 0 {
+		req.Seats = 1
+	}
+	if req.Seats < 0 || req.Seats > maxHoldSeats {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("seats must be between 1 and %d", maxHoldSeats)})
+		return
+	}
+	ttl := defaultHoldTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl < minHoldTTL || ttl > maxHoldTTL {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ttlSeconds must be between %.0f and %.0f", minHoldTTL.Seconds(), maxHoldTTL.Seconds())})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	flightID := strings.ToUpper(req.FlightID)
+	now := time.Now().UTC()
+
+	// Seats of a flight that already left can't be held
+	infos, err := store.FlightInfos(ctx.Request.Context(), rdb, []string{flightID})
+	if err != nil {
+		log.Printf("Error fetching flight %s: %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flight from Redis"})
+		return
+	}
+	if info, ok := infos[flightID]; ok {
+		if departure, err := time.Parse(time.RFC3339, info.DepartureTime); err == nil && !departure.After(now) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Flight has already departed"})
+			return
+		}
+	}
+
+	hold := models.Hold{
+		ID:        store.NewID(),
+		Owner:     ctx.GetString("username"),
+		FlightID:  flightID,
+		Class:     class,
+		Seats:     req.Seats,
+		Status:    models.HoldActive,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	left, err := store.CreateHold(ctx.Request.Context(), rdb, hold)
+	switch {
+	case errors.Is(err, store.ErrNoInventory):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No seat inventory for this fare"})
+		return
+	case errors.Is(err, store.ErrNotEnoughSeats):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Not enough seats available"})
+		return
+	case err != nil:
+		log.Printf("Error creating hold on %s %s: %v", flightID, class, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create hold"})
+		return
+	}
+
+	log.Printf("Hold %s of %d %s seats on %s for %s, %d left", hold.ID, hold.Seats, class, flightID, hold.Owner, left)
+	ctx.JSON(http.StatusCreated, hold)
+}
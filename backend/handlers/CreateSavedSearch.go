@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// maxSavedSearches is how many searches one user can save.
+	maxSavedSearches = 50
+	// maxSavedNameLength bounds the name of a saved search.
+	maxSavedNameLength = 64
+)
+
+type createSavedSearchRequest struct {
+	Name  string            `json:"name" binding:"required"`
+	Query map[string]string `json:"query" binding:"required"`
+}
+
+// CreateSavedSearch saves a named flight search for the current user, to run again with
+// RunSavedSearch. The query takes the filters of /api/flights/search.
+func CreateSavedSearch(ctx *gin.Context) {
+	var req createSavedSearchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxSavedNameLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name must be between 1 and %d characters", maxSavedNameLength)})
+		return
+	}
+	query := url.Values{}
+	for param, value := range req.Query {
+		if !slices.Contains(searchParams, param) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown search parameter %q", param)})
+			return
+		}
+		query.Set(param, value)
+	}
+	if _, err := parseSearchCriteria(query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	owner := ctx.GetString("username")
+	searches, err := store.ListSearches(ctx.Request.Context(), rdb, owner)
+	if err != nil {
+		log.Printf("Error listing saved searches: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved searches from Redis"})
+		return
+	}
+	if len(searches) >= maxSavedSearches {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can save at most %d searches", maxSavedSearches)})
+		return
+	}
+
+	search := models.SavedSearch{
+		ID:        store.NewID(),
+		Owner:     owner,
+		Name:      req.Name,
+		Query:     req.Query,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := store.SaveSearch(ctx.Request.Context(), rdb, search); err != nil {
+		log.Printf("Error saving search: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	log.Printf("Saved search %s for %s", search.ID, search.Owner)
+	ctx.JSON(http.StatusCreated, search)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteAlert removes one of the current user's alerts along with its delivery log.
+func DeleteAlert(ctx *gin.Context) {
+	alertID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	alert, err := store.GetAlert(ctx.Request.Context(), rdb, alertID)
+	// Other users' alerts are reported as missing so their IDs are not disclosed
+	if errors.Is(err, store.ErrNotFound) || (err == nil && alert.Owner != ctx.GetString("username")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching alert %s: %v", alertID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert from Redis"})
+		return
+	}
+
+	if err := store.DeleteAlert(ctx.Request.Context(), rdb, alertID); err != nil {
+		log.Printf("Error deleting alert %s: %v", alertID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
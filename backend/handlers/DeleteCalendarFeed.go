@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteCalendarFeed revokes the calendar feed URL of the current user.
+func DeleteCalendarFeed(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	owner := ctx.GetString("username")
+	err := store.DeleteCalendarFeed(ctx.Request.Context(), rdb, owner)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting calendar feed of %s: %v", owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete calendar feed"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteDeadLetter discards a rejected record that should not be replayed.
+func DeleteDeadLetter(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	if _, err := store.GetDeadLetter(ctx.Request.Context(), rdb, id); errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+		return
+	} else if err != nil {
+		log.Printf("Error fetching dead letter %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letter from Redis"})
+		return
+	}
+
+	if err := store.DeleteDeadLetter(ctx.Request.Context(), rdb, id); err != nil {
+		log.Printf("Error deleting dead letter %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete dead letter"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
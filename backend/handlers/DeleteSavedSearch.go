@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteSavedSearch removes one of the current user's saved searches.
+func DeleteSavedSearch(ctx *gin.Context) {
+	searchID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	err := store.DeleteSearch(ctx.Request.Context(), rdb, ctx.GetString("username"), searchID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting saved search %s: %v", searchID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
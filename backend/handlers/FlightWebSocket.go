@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"FlightAPI/events"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	wsWriteTimeout     = 10 * time.Second
+	wsPongTimeout      = 60 * time.Second
+	wsPingInterval     = 25 * time.Second // Must be shorter than wsPongTimeout
+	wsMaxMessageSize   = 4096
+	wsMaxSubscriptions = 50
+	// wsSendBuffer is how many messages may queue for a client; a client that falls
+	// further behind is disconnected instead of slowing down everyone else.
+	wsSendBuffer = 64
+)
+
+// Message types of the WebSocket protocol.
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+	wsSnapshot    = "snapshot"
+	wsUpdate      = "update"
+	wsError       = "error"
+)
+
+// WebSocketProtocol is the subprotocol of the flight WebSocket. Browsers can't set headers
+// on the handshake, so they authenticate by offering it along with a second subprotocol
+// made of WebSocketTokenPrefix and their JWT. Only WebSocketProtocol is echoed back.
+const (
+	WebSocketProtocol    = "flightapi"
+	WebSocketTokenPrefix = "bearer."
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{WebSocketProtocol},
+	// Authentication uses bearer tokens rather than cookies, so cross-origin clients are fine
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsMessage is the envelope of every message exchanged over the WebSocket.
+type wsMessage struct {
+	Type         string              `json:"type"`
+	Subscription string              `json:"subscription,omitempty"`
+	FlightID     string              `json:"flightId,omitempty"`
+	Origin       string              `json:"origin,omitempty"`
+	Destination  string              `json:"destination,omitempty"`
+	Date         string              `json:"date,omitempty"`
+	FlightNumber string              `json:"flightNumber,omitempty"`
+	Flights      []models.Flight     `json:"flights,omitzero"` // Sent with snapshot, empty when nothing matches
+	Event        *models.FlightEvent `json:"event,omitempty"`  // Sent with update
+	Error        string              `json:"error,omitempty"`
+}
+
+// wsClient is one WebSocket connection and its subscriptions.
+type wsClient struct {
+	conn          *websocket.Conn
+	rdb           *redis.Client
+	send          chan wsMessage
+	done          chan struct{}
+	closeOnce     sync.Once
+	mu            sync.Mutex
+	subscriptions map[string]events.Filter
+}
+
+// FlightWebSocket upgrades the request to a WebSocket on which clients subscribe to
+// flights or routes at runtime. Each subscription gets a snapshot of the matching
+// flights, then an update for every change the crawler detects.
+func FlightWebSocket(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// The upgrader already replied with an HTTP error
+		log.Printf("Error upgrading to WebSocket: %v", err)
+		return
+	}
+
+	client := &wsClient{
+		conn:          conn,
+		rdb:           rdb,
+		send:          make(chan wsMessage, wsSendBuffer),
+		done:          make(chan struct{}),
+		subscriptions: make(map[string]events.Filter),
+	}
+	defer client.close()
+
+	// The connection outlives the request context once hijacked, so it gets its own
+	connCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := rdb.Subscribe(connCtx, events.Channel)
+	defer sub.Close()
+
+	log.Printf("WebSocket opened for %s", ctx.GetString("username"))
+
+	go client.writePump()
+	go client.forwardEvents(sub.Channel())
+	client.readPump(connCtx)
+
+	log.Printf("WebSocket closed for %s", ctx.GetString("username"))
+}
+
+// readPump handles the client messages until the connection fails or is closed.
+func (c *wsClient) readPump(ctx context.Context) {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(wsMessage{Type: wsError, Error: "Invalid message"})
+			continue
+		}
+
+		switch msg.Type {
+		case wsSubscribe:
+			c.subscribe(ctx, msg)
+		case wsUnsubscribe:
+			c.unsubscribe(msg)
+		default:
+			c.enqueue(wsMessage{Type: wsError, Subscription: msg.Subscription, Error: fmt.Sprintf("Unknown message type %q", msg.Type)})
+		}
+	}
+}
+
+// subscribe registers a subscription and sends the snapshot of the flights it matches.
+func (c *wsClient) subscribe(ctx context.Context, msg wsMessage) {
+	if msg.FlightID == "" && msg.Origin == "" && msg.Destination == "" && msg.FlightNumber == "" {
+		c.enqueue(wsMessage{Type: wsError, Subscription: msg.Subscription, Error: "A flightId, flightNumber, origin or destination is required"})
+		return
+	}
+
+	filter, err := parseEventFilter(msg.Origin, msg.Destination, msg.Date, msg.FlightNumber)
+	if err != nil {
+		c.enqueue(wsMessage{Type: wsError, Subscription: msg.Subscription, Error: err.Error()})
+		return
+	}
+	filter.FlightID = strings.ToUpper(msg.FlightID)
+
+	id := msg.Subscription
+	if id == "" {
+		id = store.NewID()
+	}
+
+	c.mu.Lock()
+	_, exists := c.subscriptions[id]
+	if !exists && len(c.subscriptions) >= wsMaxSubscriptions {
+		c.mu.Unlock()
+		c.enqueue(wsMessage{Type: wsError, Subscription: id, Error: "Too many subscriptions"})
+		return
+	}
+	c.subscriptions[id] = filter
+	c.mu.Unlock()
+
+	flights, err := snapshotFlights(ctx, c.rdb, filter)
+	if err != nil {
+		log.Printf("Error building snapshot for subscription %s: %v", id, err)
+		c.enqueue(wsMessage{Type: wsError, Subscription: id, Error: "Failed to fetch flights from Redis"})
+		return
+	}
+
+	c.enqueue(wsMessage{Type: wsSnapshot, Subscription: id, Flights: flights})
+}
+
+// unsubscribe removes a subscription. Unknown subscriptions are reported as errors.
+func (c *wsClient) unsubscribe(msg wsMessage) {
+	c.mu.Lock()
+	_, exists := c.subscriptions[msg.Subscription]
+	delete(c.subscriptions, msg.Subscription)
+	c.mu.Unlock()
+
+	if !exists {
+		c.enqueue(wsMessage{Type: wsError, Subscription: msg.Subscription, Error: "Unknown subscription"})
+	}
+}
+
+// forwardEvents sends an update for every live event matching a subscription.
+func (c *wsClient) forwardEvents(messages <-chan *redis.Message) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			event, err := events.Decode(message.Payload)
+			if err != nil {
+				log.Printf("Error decoding flight event: %v", err)
+				continue
+			}
+
+			c.mu.Lock()
+			var matched []string
+			for id, filter := range c.subscriptions {
+				if filter.Matches(event) {
+					matched = append(matched, id)
+				}
+			}
+			c.mu.Unlock()
+
+			for _, id := range matched {
+				c.enqueue(wsMessage{Type: wsUpdate, Subscription: id, Event: &event})
+			}
+		}
+	}
+}
+
+// writePump is the only writer of the connection: it sends queued messages and pings.
+func (c *wsClient) writePump() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				log.Printf("WebSocket write error: %v", err)
+				c.close()
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// enqueue queues a message without blocking. Clients that can't keep up are disconnected.
+func (c *wsClient) enqueue(msg wsMessage) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		log.Println("WebSocket client too slow, closing connection")
+		closeMessage := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow")
+		_ = c.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(wsWriteTimeout))
+		c.close()
+	}
+}
+
+// close stops the pumps and closes the connection. It is safe to call more than once.
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// snapshotFlights returns the current flights matching a subscription, with the latest
+// schedule and status of each flight like the REST endpoints.
+func snapshotFlights(ctx context.Context, rdb *redis.Client, filter events.Filter) ([]models.Flight, error) {
+	flights, err := matchingFlights(ctx, rdb, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFlightInfo(ctx, rdb, flights); err != nil {
+		return nil, err
+	}
+	if filter.FlightID == "" && filter.Date != "" {
+		flights = departingOn(flights, filter.Date)
+	}
+	return flights, nil
+}
+
+// matchingFlights returns the stored fares matching a subscription.
+func matchingFlights(ctx context.Context, rdb *redis.Client, filter events.Filter) ([]models.Flight, error) {
+	if filter.FlightID != "" {
+		return store.FlightStates(ctx, rdb, filter.FlightID)
+	}
+
+	// A date narrows the snapshot to a single list
+	var dates []string
+	if filter.Date != "" {
+		dates = []string{filter.Date}
+	} else {
+		var err error
+		dates, err = store.FlightDates(ctx, rdb)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	flights := []models.Flight{}
+	for _, date := range dates {
+		dayFlights, err := store.FlightsByDate(ctx, rdb, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, flight := range dayFlights {
+			if filter.MatchesFlight(flight) {
+				flights = append(flights, flight)
+			}
+		}
+	}
+	return flights, nil
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"FlightAPI/events"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDate = "2027-04-26"
+
+func testFlight() models.Flight {
+	return models.Flight{
+		ID: "DL123-" + testDate, FlightNumber: "DL123", Airline: "Delta",
+		DepartureAirport: models.Airport{Code: "ATL"}, ArrivalAirport: models.Airport{Code: "JFK"},
+		DepartureTime: testDate + "T10:00:00-04:00", ArrivalTime: testDate + "T12:15:00-04:00",
+		Class: models.CabinEconomy, Status: models.StatusScheduled, PriceUSD: 25000,
+	}
+}
+
+// newWebSocketServer stores a flight listed on testDate whose status has since moved to
+// Delayed, and serves FlightWebSocket.
+func newWebSocketServer(t *testing.T) (*redis.Client, string) {
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	flight := testFlight()
+	data, err := json.Marshal(flight)
+	require.NoError(t, err)
+	require.NoError(t, rdb.LPush(ctx, testDate, data).Err())
+	require.NoError(t, store.SaveFlightState(ctx, rdb, flight))
+	info := models.FlightOf(flight)
+	info.Status = models.StatusDelayed
+	pipe := rdb.TxPipeline()
+	require.NoError(t, store.SaveFlightInfo(ctx, pipe, info))
+	_, err = pipe.Exec(ctx)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("redisClient", rdb) })
+	r.GET("/ws", FlightWebSocket)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return rdb, "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func exchange(t *testing.T, conn *websocket.Conn, msg wsMessage) wsMessage {
+	require.NoError(t, conn.WriteJSON(msg))
+	return read(t, conn)
+}
+
+func read(t *testing.T, conn *websocket.Conn) wsMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var reply wsMessage
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func TestWebSocketSnapshot(t *testing.T) {
+	_, url := newWebSocketServer(t)
+	conn := dial(t, url)
+
+	tests := []struct {
+		name string
+		msg  wsMessage
+	}{
+		{name: "route and date", msg: wsMessage{Type: wsSubscribe, Subscription: "route", Origin: "ATL", Date: testDate}},
+		{name: "flight", msg: wsMessage{Type: wsSubscribe, Subscription: "flight", FlightID: "dl123-" + testDate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := exchange(t, conn, tt.msg)
+			assert.Equal(t, wsSnapshot, reply.Type)
+			assert.Equal(t, tt.msg.Subscription, reply.Subscription)
+			// Snapshots show the flight as it was last updated
+			if assert.Len(t, reply.Flights, 1) {
+				assert.Equal(t, models.StatusDelayed, reply.Flights[0].Status)
+			}
+		})
+	}
+
+	reply := exchange(t, conn, wsMessage{Type: wsSubscribe, Subscription: "other", Origin: "ATL", Date: "2027-04-27"})
+	assert.Equal(t, wsSnapshot, reply.Type)
+	assert.Empty(t, reply.Flights)
+}
+
+func TestWebSocketSubscribeErrors(t *testing.T) {
+	_, url := newWebSocketServer(t)
+	conn := dial(t, url)
+
+	tests := []struct {
+		name     string
+		msg      wsMessage
+		expected string
+	}{
+		{name: "no filter", msg: wsMessage{Type: wsSubscribe, Subscription: "s"}, expected: "A flightId, flightNumber, origin or destination is required"},
+		{name: "key as date", msg: wsMessage{Type: wsSubscribe, Subscription: "s", Origin: "ATL", Date: "holds:expi"}, expected: `invalid date "holds:expi", expected YYYY-MM-DD`},
+		{name: "unknown subscription", msg: wsMessage{Type: wsUnsubscribe, Subscription: "missing"}, expected: "Unknown subscription"},
+		{name: "unknown type", msg: wsMessage{Type: "ping"}, expected: `Unknown message type "ping"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := exchange(t, conn, tt.msg)
+			assert.Equal(t, wsError, reply.Type)
+			assert.Contains(t, reply.Error, tt.expected)
+		})
+	}
+}
+
+func TestWebSocketUpdates(t *testing.T) {
+	rdb, url := newWebSocketServer(t)
+	conn := dial(t, url)
+
+	reply := exchange(t, conn, wsMessage{Type: wsSubscribe, Subscription: "atl", Origin: "ATL"})
+	require.Equal(t, wsSnapshot, reply.Type)
+	reply = exchange(t, conn, wsMessage{Type: wsSubscribe, Subscription: "lhr", Origin: "LHR"})
+	require.Equal(t, wsSnapshot, reply.Type)
+
+	flight := testFlight()
+	event := models.FlightEvent{Type: models.EventFlightPriceChanged, FlightID: flight.ID, Flight: flight, PreviousPriceUSD: 30000}
+	require.NoError(t, events.Publish(context.Background(), rdb, &event))
+
+	// Only the matching subscription gets the update
+	reply = read(t, conn)
+	assert.Equal(t, wsUpdate, reply.Type)
+	assert.Equal(t, "atl", reply.Subscription)
+	if assert.NotNil(t, reply.Event) {
+		assert.Equal(t, event.ID, reply.Event.ID)
+	}
+
+	// Unsubscribed clients get no more updates
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsUnsubscribe, Subscription: "atl"}))
+	reply = exchange(t, conn, wsMessage{Type: wsSubscribe, Subscription: "dl", FlightNumber: "DL123"})
+	require.Equal(t, wsSnapshot, reply.Type)
+	require.NoError(t, events.Publish(context.Background(), rdb, &event))
+	reply = read(t, conn)
+	assert.Equal(t, "dl", reply.Subscription)
+}
+
+func TestWebSocketSlowClient(t *testing.T) {
+	// The client is never written to, so its queue fills up like a client that stopped reading
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &wsClient{conn: conn, send: make(chan wsMessage, wsSendBuffer), done: make(chan struct{})}
+		for i := 0; i <= wsSendBuffer; i++ {
+			client.enqueue(wsMessage{Type: wsUpdate})
+		}
+		<-client.done
+	}))
+	t.Cleanup(server.Close)
+	conn := dial(t, "ws"+strings.TrimPrefix(server.URL, "http"))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), "unexpected error %v", err)
+}
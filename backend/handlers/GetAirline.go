@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"FlightAPI/airlines"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAirline returns the registry record for an airline IATA or ICAO designator.
+func GetAirline(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	airline, ok := airlines.Default().Lookup(code)
+	if !ok {
+		log.Printf("Airline not found for code '%s'", code)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Airline not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airline)
+}
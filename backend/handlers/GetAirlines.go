@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"FlightAPI/airlines"
+	"FlightAPI/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAirlines lists the airlines in the registry, optionally filtered by alliance and country.
+func GetAirlines(ctx *gin.Context) {
+	alliance := ctx.Query("alliance")
+	country := ctx.Query("country")
+
+	var results []models.Airline
+	for _, airline := range airlines.Default().All() {
+		if alliance != "" && !strings.EqualFold(airline.Alliance, alliance) {
+			continue
+		}
+		if country != "" && !strings.EqualFold(airline.Country, country) {
+			continue
+		}
+		results = append(results, airline)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"airlines": results})
+}
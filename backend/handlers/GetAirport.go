@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"FlightAPI/airports"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAirport returns the reference record for an IATA or ICAO airport code.
+func GetAirport(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	airport, ok := airports.Default().Lookup(code)
+	if !ok {
+		log.Printf("Airport not found for code '%s'", code)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Airport not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airport)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetAlertDeliveries returns the webhook delivery log of one of the current user's alerts, newest first.
+func GetAlertDeliveries(ctx *gin.Context) {
+	alertID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	alert, err := store.GetAlert(ctx.Request.Context(), rdb, alertID)
+	// Other users' alerts are reported as missing so their IDs are not disclosed
+	if errors.Is(err, store.ErrNotFound) || (err == nil && alert.Owner != ctx.GetString("username")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching alert %s: %v", alertID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert from Redis"})
+		return
+	}
+
+	deliveries, err := store.ListDeliveries(ctx.Request.Context(), rdb, alertID)
+	if err != nil {
+		log.Printf("Error fetching deliveries for alert %s: %v", alertID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetAlerts lists the price alerts of the current user. Webhook secrets are not included.
+func GetAlerts(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	alerts, err := store.ListAlerts(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error listing alerts: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts from Redis"})
+		return
+	}
+
+	for i := range alerts {
+		alerts[i].WebhookSecret = ""
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
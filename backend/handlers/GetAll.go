@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"FlightAPI/currency"
+	"FlightAPI/exporter"
 	"FlightAPI/models"
+	"FlightAPI/store"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -17,6 +23,23 @@ var ctx = context.Background()
 func GetAll(c *gin.Context) {
 	log.Println("Starting GetAll")
 
+	code, err := requestedCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grouped, err := groupedByFlight(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, ok := requestedFormat(c)
+	if !ok {
+		return
+	}
+
 	// Retrieve the Redis client from the Gin context
 	rdb, ok := c.MustGet("redisClient").(*redis.Client)
 	if !ok {
@@ -25,11 +48,11 @@ func GetAll(c *gin.Context) {
 		return
 	}
 
-	// Use SCAN to fetch keys
+	// Use SCAN to fetch the per-date flight keys
 	var cursor uint64
 	var keys []string
 	for {
-		scanKeys, newCursor, err := rdb.Scan(ctx, cursor, "*", 10).Result()
+		scanKeys, newCursor, err := rdb.Scan(ctx, cursor, store.FlightKeyPattern, 10).Result()
 		if err != nil {
 			log.Printf("Error scanning keys: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan keys from Redis"})
@@ -44,7 +67,8 @@ func GetAll(c *gin.Context) {
 
 	log.Printf("Keys retrieved: %v", keys)
 
-	if len(keys) == 0 {
+	// Exports are written empty, so they can be loaded like any other
+	if len(keys) == 0 && format == exporter.JSON {
 		log.Println("No keys found in Redis")
 		c.JSON(http.StatusOK, gin.H{"message": "No keys found in Redis"})
 		return
@@ -76,16 +100,20 @@ func GetAll(c *gin.Context) {
 					return
 				}
 
+				var listed []models.Flight
 				for _, item := range listData {
 					var flight models.Flight
 					if err := json.Unmarshal([]byte(item), &flight); err != nil {
 						log.Printf("Failed to unmarshal list item for key %s: %v", key, err)
 						continue
 					}
-					mu.Lock()
-					flights = append(flights, flight)
-					mu.Unlock()
+					listed = append(listed, flight)
 				}
+
+				// Every crawl pushes the fares again: only the latest copy of each is returned
+				mu.Lock()
+				flights = append(flights, store.LatestFares(listed)...)
+				mu.Unlock()
 			} else if keyType == "hash" {
 				// Handle hash data
 				hashData, err := rdb.HGetAll(ctx, key).Result()
@@ -118,7 +146,27 @@ func GetAll(c *gin.Context) {
 
 	wg.Wait()
 
+	if err := applyFlightInfo(c.Request.Context(), rdb, flights); err != nil {
+		log.Printf("Error fetching flight details: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+
+	if err := convertFlightPrices(c.Request.Context(), rdb, flights, code); err != nil {
+		log.Printf("Error converting prices to %s: %v", code, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert prices to " + code})
+		return
+	}
+
 	log.Printf("Returning flights")
+	if format != exporter.JSON {
+		writeExport(c, format, flights, grouped)
+		return
+	}
+	if grouped {
+		c.JSON(http.StatusOK, models.GroupFares(flights))
+		return
+	}
 	c.JSON(http.StatusOK, flights)
 }
 
@@ -132,3 +180,112 @@ func mapToStruct(hashData map[string]string, dest interface{}) error {
 	// Unmarshal the JSON into the destination struct
 	return json.Unmarshal(jsonData, dest)
 }
+
+// requestedCurrency returns the currency given with the currency query parameter, which
+// prices are converted to in the response. It is empty when prices are returned as stored.
+func requestedCurrency(c *gin.Context) (string, error) {
+	requested := c.Query("currency")
+	if requested == "" {
+		return "", nil
+	}
+	return currency.Normalize(requested)
+}
+
+// groupedByFlight reports whether the group query parameter asks for fares grouped under
+// their flight ("flight") rather than one record per fare ("fare", the default).
+func groupedByFlight(c *gin.Context) (bool, error) {
+	switch c.DefaultQuery("group", "fare") {
+	case "fare":
+		return false, nil
+	case "flight":
+		return true, nil
+	}
+	return false, fmt.Errorf("group must be fare or flight")
+}
+
+// requestedFormat returns the format flights are written in: the one named by the format
+// query parameter, or else the one the Accept header prefers. When neither gives a format
+// it can write, it answers the request and returns false.
+func requestedFormat(c *gin.Context) (exporter.Format, bool) {
+	c.Header("Vary", "Accept")
+	if name := c.Query("format"); name != "" {
+		format, ok := exporter.ParseFormat(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, csv, ndjson or xml"})
+		}
+		return format, ok
+	}
+	format, ok := exporter.Negotiate(c.GetHeader("Accept"))
+	if !ok {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "Flights can be returned as application/json, text/csv, application/x-ndjson or application/xml"})
+	}
+	return format, ok
+}
+
+// writeExport writes flights in a format other than JSON, grouped under their flight when
+// asked. The response is streamed, so an error halfway can only be logged.
+func writeExport(c *gin.Context, format exporter.Format, flights []models.Flight, grouped bool) {
+	c.Header("Content-Type", exporter.ContentType(format))
+	c.Status(http.StatusOK)
+
+	var err error
+	if grouped {
+		err = exporter.WriteFlights(c.Writer, format, models.GroupFares(flights))
+	} else {
+		err = exporter.WriteFares(c.Writer, format, flights)
+	}
+	if err != nil {
+		log.Printf("Error writing flights as %s: %v", format, err)
+	}
+}
+
+// applyFlightInfo gives every fare the schedule, status and aircraft its flight was last
+// updated with, since fares listed from older crawls may be out of date.
+func applyFlightInfo(ctx context.Context, rdb *redis.Client, flights []models.Flight) error {
+	ids := make([]string, 0, len(flights))
+	for _, flight := range flights {
+		ids = append(ids, flight.ID)
+	}
+	infos, err := store.FlightInfos(ctx, rdb, ids)
+	if err != nil {
+		return err
+	}
+	for i := range flights {
+		if info, ok := infos[flights[i].ID]; ok {
+			info.ApplyTo(&flights[i])
+		}
+	}
+	return nil
+}
+
+// departingOn keeps the fares whose flight leaves on a local date. A fare stays in the list of
+// the date it was crawled for, so once applyFlightInfo moved its flight to another day it no
+// longer belongs to that date.
+func departingOn(flights []models.Flight, date string) []models.Flight {
+	kept := flights[:0]
+	for _, flight := range flights {
+		if strings.HasPrefix(flight.DepartureTime, date) {
+			kept = append(kept, flight)
+		}
+	}
+	return kept
+}
+
+// convertFlightPrices converts the price of each flight to a currency at today's rates.
+// Nothing is converted when the currency is empty.
+func convertFlightPrices(ctx context.Context, rdb *redis.Client, flights []models.Flight, code string) error {
+	if code == "" {
+		return nil
+	}
+	rates, err := currency.LoadTable(ctx, rdb)
+	if err != nil {
+		return fmt.Errorf("load exchange rates: %w", err)
+	}
+	now := time.Now()
+	for i := range flights {
+		if err := rates.ConvertFlight(&flights[i], code, now); err != nil {
+			return fmt.Errorf("flight %s: %w", flights[i].ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetBooking returns one of the current user's bookings by its record locator.
+func GetBooking(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	booking, ok := ownBooking(ctx, rdb, ctx.Param("locator"))
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// ownBooking fetches a booking of the current user, or writes the error response and returns
+// false. Other users' bookings are reported as missing so their locators are not disclosed.
+func ownBooking(ctx *gin.Context, rdb *redis.Client, locator string) (models.Booking, bool) {
+	locator = strings.ToUpper(locator)
+	booking, err := store.GetBooking(ctx.Request.Context(), rdb, locator)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && booking.Owner != ctx.GetString("username")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return models.Booking{}, false
+	}
+	if err != nil {
+		log.Printf("Error fetching booking %s: %v", locator, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch booking from Redis"})
+		return models.Booking{}, false
+	}
+	return booking, true
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"FlightAPI/ical"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetBookingCalendar exports the flights of one of the current user's bookings as an
+// iCalendar (.ics) file, one event per segment.
+func GetBookingCalendar(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	booking, ok := ownBooking(ctx, rdb, ctx.Param("locator"))
+	if !ok {
+		return
+	}
+
+	events, err := bookingEvents(ctx.Request.Context(), rdb, []models.Booking{booking})
+	if err != nil {
+		log.Printf("Error fetching flights of booking %s: %v", booking.Locator, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+	writeCalendar(ctx, ical.Calendar{Name: "Booking " + booking.Locator, Events: events}, booking.Locator+".ics")
+}
+
+// bookingEvents describes the segments of bookings as calendar events, with the latest
+// schedule and status of their flights. Cancelled bookings are left out.
+func bookingEvents(ctx context.Context, rdb *redis.Client, bookings []models.Booking) ([]ical.Event, error) {
+	var flightIDs []string
+	for _, booking := range bookings {
+		for _, segment := range booking.Segments {
+			flightIDs = append(flightIDs, segment.FlightID)
+		}
+	}
+	infos, err := store.FlightInfos(ctx, rdb, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ical.Event
+	byFlight := make(map[string]int)
+	for _, booking := range bookings {
+		if booking.Status == models.BookingCancelled {
+			continue
+		}
+		passengers := make([]string, len(booking.Passengers))
+		for i, passenger := range booking.Passengers {
+			passengers[i] = passenger.FirstName + " " + passenger.LastName
+		}
+
+		for _, segment := range booking.Segments {
+			seats := fmt.Sprintf("%d %s seats", segment.Seats, segment.Class)
+			if segment.Seats == 1 {
+				seats = fmt.Sprintf("1 %s seat", segment.Class)
+			}
+			note := fmt.Sprintf("Booking %s (%s): %s for %s", booking.Locator, booking.Status, seats, strings.Join(passengers, ", "))
+			if i, ok := byFlight[segment.FlightID]; ok {
+				events[i].Description += "\n" + note
+				continue
+			}
+
+			flight, ok := infos[segment.FlightID]
+			if !ok {
+				// Fall back on the schedule the flight had when it was booked
+				flight = models.FlightFares{
+					ID:               segment.FlightID,
+					FlightNumber:     segment.FlightNumber,
+					DepartureAirport: models.Airport{Code: segment.DepartureAirport},
+					ArrivalAirport:   models.Airport{Code: segment.ArrivalAirport},
+					DepartureTime:    segment.DepartureTime,
+					ArrivalTime:      segment.ArrivalTime,
+				}
+			}
+			event, ok := ical.FlightEvent(flight)
+			if !ok {
+				continue
+			}
+			event.Description += "\n" + note
+			byFlight[segment.FlightID] = len(events)
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetBookings lists the bookings of the current user, newest first.
+func GetBookings(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	bookings, err := store.ListBookings(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error listing bookings: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"bookings": bookings})
+}
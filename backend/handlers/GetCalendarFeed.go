@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"FlightAPI/ical"
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// calendarRefreshInterval is how often calendar apps are asked to fetch a feed again, as
+// often as flights are crawled.
+const calendarRefreshInterval = 30 * time.Minute
+
+// GetCalendarFeed serves the calendar feed of a user: the flights of their bookings and
+// watchlist, with their latest schedule and status. The token in the URL stands in for
+// the JWT, which calendar apps can't send.
+func GetCalendarFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	owner, err := store.CalendarFeedOwner(ctx.Request.Context(), rdb, token)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching calendar feed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feed from Redis"})
+		return
+	}
+
+	bookings, err := store.ListBookings(ctx.Request.Context(), rdb, owner)
+	if err != nil {
+		log.Printf("Error listing bookings of %s: %v", owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bookings from Redis"})
+		return
+	}
+	events, err := bookingEvents(ctx.Request.Context(), rdb, bookings)
+	if err != nil {
+		log.Printf("Error fetching flights of the bookings of %s: %v", owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+	watched, err := watchlistEvents(ctx.Request.Context(), rdb, owner)
+	if err != nil {
+		log.Printf("Error fetching watchlist of %s: %v", owner, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist from Redis"})
+		return
+	}
+
+	// A booked flight that is also watched is shown once, as booked
+	booked := make(map[string]bool, len(events))
+	for _, event := range events {
+		booked[event.UID] = true
+	}
+	for _, event := range watched {
+		if !booked[event.UID] {
+			events = append(events, event)
+		}
+	}
+
+	writeCalendar(ctx, ical.Calendar{Name: "My flights", RefreshInterval: calendarRefreshInterval, Events: events}, "")
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetCrawlDiff returns the report of a crawl run: the fares added, removed, and whose price,
+// status or schedule changed compared to the previous run of the same provider.
+func GetCrawlDiff(ctx *gin.Context) {
+	crawlID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	report, err := store.GetCrawlReport(ctx.Request.Context(), rdb, crawlID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Crawl report not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching report of crawl %s: %v", crawlID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl report from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetCrawlRun returns one crawl run with its counters and outcome.
+func GetCrawlRun(ctx *gin.Context) {
+	crawlID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	run, err := store.GetCrawlRun(ctx.Request.Context(), rdb, crawlID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Crawl run not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching crawl run %s: %v", crawlID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl run from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
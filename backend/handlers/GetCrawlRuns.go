@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultCrawlRunsLimit = 50
+	maxCrawlRunsLimit     = 500
+)
+
+// GetCrawlRuns lists the most recent crawl runs, newest first. The number of runs is set with limit.
+func GetCrawlRuns(ctx *gin.Context) {
+	limit := defaultCrawlRunsLimit
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxCrawlRunsLimit {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxCrawlRunsLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	runs, err := store.ListCrawlRuns(ctx.Request.Context(), rdb, limit)
+	if err != nil {
+		log.Printf("Error listing crawl runs: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl runs from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"runs": runs})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"FlightAPI/store"
 	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -24,8 +25,8 @@ func GetDates(ctx *gin.Context) {
 		return
 	}
 
-	// Fetch all keys from Redis
-	keys, err := rdb.Keys(context.Background(), "*").Result()
+	// Fetch all the per-date flight keys from Redis
+	keys, err := rdb.Keys(context.Background(), store.FlightKeyPattern).Result()
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch keys from Redis"})
 		return
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetDeadLetter returns one rejected record with the reason it was rejected.
+func GetDeadLetter(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	letter, err := store.GetDeadLetter(ctx.Request.Context(), rdb, id)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching dead letter %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letter from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, letter)
+}
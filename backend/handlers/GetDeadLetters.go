@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultDeadLettersLimit = 100
+	maxDeadLettersLimit     = 1000
+)
+
+// GetDeadLetters lists the records rejected while crawling, newest first.
+// They can be filtered by provider and crawlId, and the number returned is set with limit.
+func GetDeadLetters(ctx *gin.Context) {
+	limit, err := parseDeadLettersLimit(ctx.Query("limit"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	letters, err := store.ListDeadLetters(ctx.Request.Context(), rdb, ctx.Query("provider"), ctx.Query("crawlId"), limit)
+	if err != nil {
+		log.Printf("Error listing dead letters: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letters from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"deadLetters": letters})
+}
+
+// parseDeadLettersLimit reads the optional limit of dead letters to return.
+func parseDeadLettersLimit(value string) (int, error) {
+	if value == "" {
+		return defaultDeadLettersLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxDeadLettersLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxDeadLettersLimit)
+	}
+	return limit, nil
+}
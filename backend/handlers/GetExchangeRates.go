@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"FlightAPI/currency"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetExchangeRates lists the currencies prices can be stored in and the exchange-rate table,
+// the embedded rates merged with the rates loaded by the admins.
+func GetExchangeRates(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	rates, err := currency.LoadTable(ctx.Request.Context(), rdb)
+	if err != nil {
+		log.Printf("Error loading exchange rates: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"base":       currency.USD,
+		"currencies": currency.Default().Currencies(),
+		"rates":      rates.Rates(),
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"FlightAPI/ical"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetFlightCalendar exports a flight as an iCalendar (.ics) event, from its departure to its
+// arrival in the local time of each airport.
+func GetFlightCalendar(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	flight, found, err := store.GetFlight(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flight from Redis"})
+		return
+	}
+	if !found {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Flight not found"})
+		return
+	}
+
+	event, ok := ical.FlightEvent(flight)
+	if !ok {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Flight has no valid departure time"})
+		return
+	}
+	writeCalendar(ctx, ical.Calendar{Name: flight.FlightNumber, Events: []ical.Event{event}}, flightID+".ics")
+}
+
+// writeCalendar responds with a calendar. With a filename, it is sent as a file to save.
+func writeCalendar(ctx *gin.Context, calendar ical.Calendar, filename string) {
+	sort.SliceStable(calendar.Events, func(i, j int) bool { return calendar.Events[i].Start.Before(calendar.Events[j].Start) })
+
+	var body bytes.Buffer
+	if err := ical.Write(&body, calendar, time.Now()); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write calendar"})
+		return
+	}
+	if filename != "" {
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	ctx.Data(http.StatusOK, ical.ContentType, body.Bytes())
+}
+
+// flightEvents describes flights as calendar events, skipping flights without valid times.
+func flightEvents(flights []models.FlightFares) []ical.Event {
+	events := make([]ical.Event, 0, len(flights))
+	for _, flight := range flights {
+		if event, ok := ical.FlightEvent(flight); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
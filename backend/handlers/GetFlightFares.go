@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetFlightFares returns a flight with its schedule, status and aircraft and the latest
+// state of every fare sold on it. With currency, fares are converted at today's rates.
+func GetFlightFares(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+
+	code, err := requestedCurrency(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	flight, found, err := store.GetFlight(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching fares of flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flight from Redis"})
+		return
+	}
+	if !found {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Flight not found"})
+		return
+	}
+
+	fares := flight.Flights()
+	if err := convertFlightPrices(ctx.Request.Context(), rdb, fares, code); err != nil {
+		log.Printf("Error converting prices to %s: %v", code, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert prices to " + code})
+		return
+	}
+	flight.Fares = models.GroupFares(fares)[0].Fares
+
+	ctx.JSON(http.StatusOK, flight)
+}
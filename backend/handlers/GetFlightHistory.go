@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetFlightHistory returns the status timeline of a flight, oldest first.
+func GetFlightHistory(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	history, err := store.StatusHistory(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching status history for flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status history from Redis"})
+		return
+	}
+
+	if len(history) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No status history found for flight"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"flightId":      flightID,
+		"currentStatus": history[len(history)-1].To,
+		"history":       history,
+	})
+}
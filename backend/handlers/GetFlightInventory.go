@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetFlightInventory returns the seats left for sale per fare class of a flight, after
+// the seats taken by active and confirmed holds.
+func GetFlightInventory(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	inventory, err := store.GetInventory(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching inventory of flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory from Redis"})
+		return
+	}
+	if len(inventory.Seats) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No seat inventory for flight"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, inventory)
+}
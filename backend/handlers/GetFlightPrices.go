@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"FlightAPI/currency"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// farePriceHistory is the price history and its summary for one fare class of a flight.
+type farePriceHistory struct {
+	Class   models.CabinClass         `json:"class"`
+	Summary models.PriceSummary       `json:"summary"`
+	History []models.PriceObservation `json:"history"`
+}
+
+// GetFlightPrices returns the price observations recorded across crawls for a flight,
+// grouped by fare class with min, max, current and change since first seen. With currency,
+// each observation is converted at the rates of the day it was observed.
+func GetFlightPrices(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+	class := ctx.Query("class")
+	if cabin, ok := models.ParseCabinClass(class); ok {
+		class = string(cabin)
+	}
+
+	code, err := requestedCurrency(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	observations, err := store.PriceHistory(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching price history for flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price history from Redis"})
+		return
+	}
+
+	if len(observations) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No price history found for flight"})
+		return
+	}
+
+	if code != "" {
+		rates, err := currency.LoadTable(ctx.Request.Context(), rdb)
+		if err != nil {
+			log.Printf("Error loading exchange rates: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exchange rates from Redis"})
+			return
+		}
+		for i := range observations {
+			if err := rates.ConvertObservation(&observations[i], code); err != nil {
+				log.Printf("Error converting prices of flight '%s' to %s: %v", flightID, code, err)
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert prices to " + code})
+				return
+			}
+		}
+	}
+
+	classes, byClass := store.GroupPricesByClass(observations)
+
+	fares := []farePriceHistory{}
+	for _, fareClass := range classes {
+		if class != "" && !strings.EqualFold(string(fareClass), class) {
+			continue
+		}
+		fares = append(fares, farePriceHistory{
+			Class:   fareClass,
+			Summary: store.SummarizePrices(byClass[fareClass]),
+			History: byClass[fareClass],
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"flightId": flightID, "fares": fares})
+}
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"FlightAPI/airlines"
+	"FlightAPI/airports"
+	"FlightAPI/exporter"
 	"FlightAPI/models"
+	"FlightAPI/store"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -14,11 +22,29 @@ import (
 )
 
 func GetFlightsBySearch(ctx *gin.Context) {
-	origin := ctx.Query("origin")
-	destination := ctx.Query("destination")
-	date := ctx.Query("date")
+	criteria, err := parseSearchCriteria(ctx.Request.URL.Query())
+	if err != nil {
+		log.Printf("Invalid search parameters: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err := requestedCurrency(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	log.Printf("Received search parameters: origin=%s, destination=%s, date=%s", origin, destination, date)
+	grouped, err := groupedByFlight(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, ok := requestedFormat(ctx)
+	if !ok {
+		return
+	}
 
 	// Retrieve the Redis client from the Gin context
 	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
@@ -28,16 +54,57 @@ func GetFlightsBySearch(ctx *gin.Context) {
 		return
 	}
 
+	matchingFlights, err := searchFlights(ctx.Request.Context(), rdb, criteria)
+	if err != nil {
+		log.Printf("Error searching flights: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan keys from Redis"})
+		return
+	}
+
+	// Return matching flights
+	if len(matchingFlights) == 0 && format == exporter.JSON {
+		ctx.JSON(http.StatusOK, gin.H{"message": "No matching flights found"})
+		return
+	}
+
+	if err := applyFlightInfo(ctx.Request.Context(), rdb, matchingFlights); err != nil {
+		log.Printf("Error fetching flight details: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+
+	sortFlights(matchingFlights, criteria.sortBy)
+
+	if err := convertFlightPrices(ctx.Request.Context(), rdb, matchingFlights, code); err != nil {
+		log.Printf("Error converting prices to %s: %v", code, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert prices to " + code})
+		return
+	}
+
+	// Flights come in the order of their first fare, so by their cheapest fare when sorting by price
+	if format != exporter.JSON {
+		writeExport(ctx, format, matchingFlights, grouped)
+		return
+	}
+	if grouped {
+		ctx.JSON(http.StatusOK, models.GroupFares(matchingFlights))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, matchingFlights)
+}
+
+// searchFlights returns every stored fare matching the criteria, across all crawls and in
+// no particular order.
+func searchFlights(ctx context.Context, rdb *redis.Client, criteria searchCriteria) ([]models.Flight, error) {
 	var cursor uint64
 	var keys []string
 
-	// Use SCAN to fetch all keys
+	// Use SCAN to fetch all the per-date flight keys
 	for {
-		scanKeys, newCursor, err := rdb.Scan(context.Background(), cursor, "*", 10).Result()
+		scanKeys, newCursor, err := rdb.Scan(ctx, cursor, store.FlightKeyPattern, 10).Result()
 		if err != nil {
-			log.Printf("Error scanning keys: %v", err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan keys from Redis"})
-			return
+			return nil, fmt.Errorf("scan keys: %w", err)
 		}
 		keys = append(keys, scanKeys...)
 		cursor = newCursor
@@ -55,7 +122,7 @@ func GetFlightsBySearch(ctx *gin.Context) {
 		go func(key string) {
 			defer wg.Done()
 
-			keyType, err := rdb.Type(context.Background(), key).Result()
+			keyType, err := rdb.Type(ctx, key).Result()
 			if err != nil {
 				log.Printf("Failed to fetch type for key %s: %v", key, err)
 				return
@@ -63,29 +130,31 @@ func GetFlightsBySearch(ctx *gin.Context) {
 
 			if keyType == "list" {
 				// Process list data
-				listData, err := rdb.LRange(context.Background(), key, 0, -1).Result()
+				listData, err := rdb.LRange(ctx, key, 0, -1).Result()
 				if err != nil {
 					log.Printf("Failed to fetch list for key %s: %v", key, err)
 					return
 				}
 
+				var listed []models.Flight
 				for _, item := range listData {
 					var flight models.Flight
 					if err := json.Unmarshal([]byte(item), &flight); err != nil {
 						log.Printf("Failed to unmarshal list item for key %s: %v", key, err)
 						continue
 					}
+					listed = append(listed, flight)
+				}
 
-					// Filter flights
-					if strings.EqualFold(flight.DepartureAirport.Code, origin) &&
-						strings.EqualFold(flight.ArrivalAirport.Code, destination) &&
-						strings.HasPrefix(flight.DepartureTime, date) {
+				// Filter the latest copy of each fare, so an older crawl can't match in its place
+				for _, flight := range store.LatestFares(listed) {
+					if criteria.matches(flight) {
 						flightsChan <- flight
 					}
 				}
 			} else if keyType == "hash" {
 				// Process hash data
-				hashData, err := rdb.HGetAll(context.Background(), key).Result()
+				hashData, err := rdb.HGetAll(ctx, key).Result()
 				if err != nil {
 					log.Printf("Failed to fetch hash for key %s: %v", key, err)
 					return
@@ -102,9 +171,7 @@ func GetFlightsBySearch(ctx *gin.Context) {
 				}
 
 				// Filter flights
-				if strings.EqualFold(flight.DepartureAirport.Code, origin) &&
-					strings.EqualFold(flight.ArrivalAirport.Code, destination) &&
-					strings.HasPrefix(flight.DepartureTime, date) {
+				if criteria.matches(flight) {
 					flightsChan <- flight
 				}
 			}
@@ -122,12 +189,157 @@ func GetFlightsBySearch(ctx *gin.Context) {
 	for flight := range flightsChan {
 		matchingFlights = append(matchingFlights, flight)
 	}
+	return matchingFlights, nil
+}
 
-	// Return matching flights
-	if len(matchingFlights) == 0 {
-		ctx.JSON(http.StatusOK, gin.H{"message": "No matching flights found"})
-		return
+// searchCriteria holds the filters of a flight search.
+// Origins and destinations are sets because a search location can be a metropolitan
+// area, coordinates or an airport with a radius around it.
+type searchCriteria struct {
+	origins      map[string]bool
+	destinations map[string]bool
+	date         string
+	airlineCode  string
+	checkedBag   bool   // Only fares known to include a checked bag
+	refundable   bool   // Only fares known to be refundable
+	sortBy       string // "price" or "departure"
+}
+
+// searchParams are the query parameters of a flight search that filter and order flights,
+// as opposed to the ones that only change how they are written, such as currency.
+var searchParams = []string{"origin", "destination", "date", "airline", "originRadius", "destinationRadius", "checkedBag", "refundable", "sort"}
+
+// parseSearchCriteria reads the search filters from the query string.
+// origin and destination accept an airport code, a metro code (NYC) or "lat,lon" coordinates,
+// and originRadius/destinationRadius (km) widen them to every airport within that distance.
+// checkedBag=true and refundable=true keep the fares known to include a checked bag or to be
+// refundable; fares whose provider doesn't say are left out.
+// sort orders the results by price (the default) or departure time.
+func parseSearchCriteria(query url.Values) (searchCriteria, error) {
+	origin := query.Get("origin")
+	destination := query.Get("destination")
+	date := query.Get("date")
+	airline := query.Get("airline")
+
+	log.Printf("Received search parameters: origin=%s, destination=%s, date=%s, airline=%s", origin, destination, date, airline)
+
+	originRadius, err := parseRadius(query.Get("originRadius"))
+	if err != nil {
+		return searchCriteria{}, fmt.Errorf("invalid originRadius")
+	}
+	destinationRadius, err := parseRadius(query.Get("destinationRadius"))
+	if err != nil {
+		return searchCriteria{}, fmt.Errorf("invalid destinationRadius")
 	}
 
-	ctx.JSON(http.StatusOK, matchingFlights)
+	db := airports.Default()
+	origins, err := db.Expand(origin, originRadius)
+	if err != nil {
+		return searchCriteria{}, fmt.Errorf("invalid origin: %w", err)
+	}
+	destinations, err := db.Expand(destination, destinationRadius)
+	if err != nil {
+		return searchCriteria{}, fmt.Errorf("invalid destination: %w", err)
+	}
+
+	criteria := searchCriteria{origins: origins, destinations: destinations, date: date}
+
+	if criteria.checkedBag, err = parseFlag(query.Get("checkedBag")); err != nil {
+		return searchCriteria{}, fmt.Errorf("checkedBag must be true or false")
+	}
+	if criteria.refundable, err = parseFlag(query.Get("refundable")); err != nil {
+		return searchCriteria{}, fmt.Errorf("refundable must be true or false")
+	}
+
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = "price"
+	}
+	switch sortBy {
+	case "price", "departure":
+		criteria.sortBy = sortBy
+	default:
+		return searchCriteria{}, fmt.Errorf("sort must be price or departure")
+	}
+
+	// The airline filter accepts any code, name or alias known to the registry
+	if airline != "" {
+		resolved, ok := airlines.Default().Resolve(airline)
+		if !ok {
+			return searchCriteria{}, fmt.Errorf("unknown airline")
+		}
+		criteria.airlineCode = resolved.Code
+	}
+
+	return criteria, nil
+}
+
+// parseRadius parses an optional radius in kilometres. Empty means no radius.
+func parseRadius(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	radius, err := strconv.ParseFloat(value, 64)
+	if err != nil || radius < 0 || radius > maxNearbyRadiusKm {
+		return 0, fmt.Errorf("radius must be between 0 and %.0f km", maxNearbyRadiusKm)
+	}
+	return radius, nil
+}
+
+// parseFlag parses an optional boolean filter. Empty means false.
+func parseFlag(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// sortFlights orders flights by price, cheapest first, or by departure time, earliest first.
+// Prices are compared in US cents, so fares in different currencies rank together; fares
+// of the same price are ordered by departure time.
+func sortFlights(flights []models.Flight, by string) {
+	switch by {
+	case "price":
+		sort.SliceStable(flights, func(i, j int) bool {
+			if flights[i].PriceUSD != flights[j].PriceUSD {
+				return flights[i].PriceUSD < flights[j].PriceUSD
+			}
+			return flights[i].DepartureTime < flights[j].DepartureTime
+		})
+	case "departure":
+		sort.SliceStable(flights, func(i, j int) bool {
+			return flights[i].DepartureTime < flights[j].DepartureTime
+		})
+	}
+}
+
+// matches reports whether a flight satisfies every filter of the search.
+func (c searchCriteria) matches(flight models.Flight) bool {
+	if !c.origins[strings.ToUpper(flight.DepartureAirport.Code)] ||
+		!c.destinations[strings.ToUpper(flight.ArrivalAirport.Code)] ||
+		!strings.HasPrefix(flight.DepartureTime, c.date) {
+		return false
+	}
+
+	if c.airlineCode != "" {
+		// Flights stored before normalization have no carrier code, so resolve their airline field
+		code := flight.CarrierCode
+		if code == "" {
+			if airline, ok := airlines.Default().Resolve(flight.Airline); ok {
+				code = airline.Code
+			}
+		}
+		if code != c.airlineCode {
+			return false
+		}
+	}
+
+	if c.checkedBag && (flight.Baggage == nil || flight.Baggage.CheckedBags == 0) {
+		return false
+	}
+	if c.refundable && (flight.FareRules == nil || !flight.FareRules.Refundable) {
+		return false
+	}
+
+	return true
 }
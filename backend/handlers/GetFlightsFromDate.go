@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"FlightAPI/exporter"
 	"FlightAPI/models"
+	"FlightAPI/store"
 	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
@@ -10,13 +12,37 @@ import (
 	"net/http"
 	"sort"
 	"sync"
+	"time"
 )
 
 func GetFlightsFromDate(ctx *gin.Context) {
-	// Extract date from URL parameter
-	date := ctx.Param("date")
+	// Extract date from URL parameter. It shares the :id wildcard with the per-flight routes.
+	date := ctx.Param("id")
 	log.Printf("Received date parameter: %s", date)
 
+	// The date is used as the Redis key, so anything else must not reach Redis
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return
+	}
+
+	code, err := requestedCurrency(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grouped, err := groupedByFlight(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, ok := requestedFormat(ctx)
+	if !ok {
+		return
+	}
+
 	// Retrieve Redis client from context
 	redisClient, exists := ctx.Get("redisClient")
 	if !exists {
@@ -53,18 +79,16 @@ func GetFlightsFromDate(ctx *gin.Context) {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
 			return
 		}
+		// Every crawl pushes the fares again, newest first, so the list is read in order
 		for _, item := range data {
-			wg.Add(1)
-			go func(item string) {
-				defer wg.Done()
-				var flight models.Flight
-				if err := json.Unmarshal([]byte(item), &flight); err != nil {
-					log.Printf("Error parsing flight data: %v", err)
-					return
-				}
-				flightChan <- flight
-			}(item)
+			var flight models.Flight
+			if err := json.Unmarshal([]byte(item), &flight); err != nil {
+				log.Printf("Error parsing flight data: %v", err)
+				continue
+			}
+			flights = append(flights, flight)
 		}
+		flights = store.LatestFares(flights)
 
 	case "hash":
 		// Handle hash type
@@ -87,6 +111,10 @@ func GetFlightsFromDate(ctx *gin.Context) {
 			}(item)
 		}
 
+	case "none":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No flights found on " + date})
+		return
+
 	default:
 		log.Printf("Unsupported Redis key type '%s' for key '%s'", keyType, date)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported Redis key type"})
@@ -104,11 +132,34 @@ func GetFlightsFromDate(ctx *gin.Context) {
 		flights = append(flights, flight)
 	}
 
+	if err := applyFlightInfo(ctx.Request.Context(), rdb, flights); err != nil {
+		log.Printf("Error fetching flight details for '%s': %v", date, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+	flights = departingOn(flights, date)
+
 	// Sort flights by departure time (earliest to latest)
-	sort.Slice(flights, func(i, j int) bool {
+	sort.SliceStable(flights, func(i, j int) bool {
 		return flights[i].DepartureTime < flights[j].DepartureTime
 	})
 
+	if err := convertFlightPrices(ctx.Request.Context(), rdb, flights, code); err != nil {
+		log.Printf("Error converting prices to %s: %v", code, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert prices to " + code})
+		return
+	}
+
+	if format != exporter.JSON {
+		writeExport(ctx, format, flights, grouped)
+		return
+	}
+
+	if grouped {
+		ctx.JSON(http.StatusOK, gin.H{"flights": models.GroupFares(flights)})
+		return
+	}
+
 	// Return sorted flights as JSON
 	ctx.JSON(http.StatusOK, gin.H{"flights": flights})
 }
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFlightsFromDateDropsMovedFlights(t *testing.T) {
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	staying := testFlight()
+	moved := testFlight()
+	moved.ID, moved.FlightNumber = "DL456-"+testDate, "DL456"
+	for _, flight := range []models.Flight{staying, moved} {
+		data, err := json.Marshal(flight)
+		require.NoError(t, err)
+		require.NoError(t, rdb.LPush(ctx, testDate, data).Err())
+	}
+
+	// DL456 was since moved to the next day
+	info := models.FlightOf(moved)
+	info.DepartureTime = "2027-04-27T09:00:00-04:00"
+	info.ArrivalTime = "2027-04-27T11:15:00-04:00"
+	pipe := rdb.TxPipeline()
+	require.NoError(t, store.SaveFlightInfo(ctx, pipe, info))
+	_, err := pipe.Exec(ctx)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("redisClient", rdb) })
+	r.GET("/flights/:id", GetFlightsFromDate)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/flights/"+testDate, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Flights []models.Flight `json:"flights"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body.Flights, 1) {
+		assert.Equal(t, "DL123", body.Flights[0].FlightNumber)
+	}
+}
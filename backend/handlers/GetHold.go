@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetHold returns one of the current user's holds.
+func GetHold(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	hold, ok := ownHold(ctx, rdb, ctx.Param("id"))
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// ownHold fetches a hold of the current user, or writes the error response and returns false.
+// Other users' holds are reported as missing so their IDs are not disclosed.
+func ownHold(ctx *gin.Context, rdb *redis.Client, holdID string) (models.Hold, bool) {
+	hold, err := store.GetHold(ctx.Request.Context(), rdb, holdID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && hold.Owner != ctx.GetString("username")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return models.Hold{}, false
+	}
+	if err != nil {
+		log.Printf("Error fetching hold %s: %v", holdID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hold from Redis"})
+		return models.Hold{}, false
+	}
+	return hold, true
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetHolds lists the seat holds of the current user, newest first.
+func GetHolds(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	holds, err := store.ListHolds(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error listing holds: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch holds from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"holds": holds})
+}
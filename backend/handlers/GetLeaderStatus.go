@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"FlightAPI/leader"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLeaderStatus returns the replica that leads the scheduled crawls, as seen by the one serving the request.
+func GetLeaderStatus(ctx *gin.Context) {
+	elector, ok := ctx.MustGet("elector").(*leader.Elector)
+	if !ok {
+		log.Println("Leader elector not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Leader elector not found"})
+		return
+	}
+
+	status, err := elector.Status(ctx.Request.Context())
+	if err != nil {
+		log.Printf("Error fetching leader status: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leader status from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
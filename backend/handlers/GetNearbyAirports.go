@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"FlightAPI/airports"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNearbyRadiusKm = 100.0
+	maxNearbyRadiusKm     = 1000.0
+)
+
+// GetNearbyAirports lists the airports within a radius (in km) of an airport code
+// or of lat/lon coordinates, closest first.
+func GetNearbyAirports(ctx *gin.Context) {
+	radius := defaultNearbyRadiusKm
+	if rawRadius := ctx.Query("radius"); rawRadius != "" {
+		parsed, err := strconv.ParseFloat(rawRadius, 64)
+		if err != nil || parsed <= 0 || parsed > maxNearbyRadiusKm {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius"})
+			return
+		}
+		radius = parsed
+	}
+
+	db := airports.Default()
+
+	var lat, lon float64
+	if code := ctx.Query("code"); code != "" {
+		airport, ok := db.Lookup(code)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Airport not found"})
+			return
+		}
+		lat, lon = airport.Latitude, airport.Longitude
+	} else {
+		var errLat, errLon error
+		lat, errLat = strconv.ParseFloat(ctx.Query("lat"), 64)
+		lon, errLon = strconv.ParseFloat(ctx.Query("lon"), 64)
+		if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Either 'code' or valid 'lat' and 'lon' are required"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"airports": db.Near(lat, lon, radius)})
+}
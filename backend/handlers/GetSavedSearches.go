@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetSavedSearches lists the saved searches of the current user, newest first.
+func GetSavedSearches(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	searches, err := store.ListSearches(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error listing saved searches: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved searches from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"searches": searches})
+}
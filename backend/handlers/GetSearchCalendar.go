@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"FlightAPI/ical"
+	"FlightAPI/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetSearchCalendar exports the flights matching a search as an iCalendar (.ics) file, one
+// event per flight. It takes the filters of /api/flights/search.
+func GetSearchCalendar(ctx *gin.Context) {
+	criteria, err := parseSearchCriteria(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	fares, err := searchFlights(ctx.Request.Context(), rdb, criteria)
+	if err != nil {
+		log.Printf("Error searching flights: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan keys from Redis"})
+		return
+	}
+	if err := applyFlightInfo(ctx.Request.Context(), rdb, fares); err != nil {
+		log.Printf("Error fetching flight details: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flights from Redis"})
+		return
+	}
+
+	// A flight is in the calendar once, whatever fares it matched with
+	writeCalendar(ctx, ical.Calendar{Name: "Flight search", Events: flightEvents(models.GroupFares(fares))}, "flights.ics")
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"FlightAPI/validation"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// validationRuleResponse is a validation rule with the number of records that broke it.
+type validationRuleResponse struct {
+	validation.Rule
+	Violations int `json:"violations"`
+}
+
+// GetValidationRules lists the data-quality rules applied to ingested flights, with their
+// current severity and how many records broke each of them.
+func GetValidationRules(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	rules, err := validation.Load(ctx.Request.Context(), rdb)
+	if err != nil {
+		log.Printf("Error loading validation rules: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch validation rules from Redis"})
+		return
+	}
+
+	counters, err := store.RuleCounters(ctx.Request.Context(), rdb)
+	if err != nil {
+		log.Printf("Error fetching validation counters: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch validation counters from Redis"})
+		return
+	}
+
+	response := []validationRuleResponse{}
+	for _, rule := range rules.Rules() {
+		response = append(response, validationRuleResponse{Rule: rule, Violations: counters[rule.Name]})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"rules": response})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetWatchlist lists the flight fares the current user watches, in departure order, with
+// their current status and the price changes seen since each was added.
+func GetWatchlist(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	entries, err := store.Watchlist(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error fetching watchlist: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist from Redis"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"watchlist": entries})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"FlightAPI/ical"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetWatchlistCalendar exports the flights the current user watches as an iCalendar (.ics)
+// file, one event per flight.
+func GetWatchlistCalendar(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	events, err := watchlistEvents(ctx.Request.Context(), rdb, ctx.GetString("username"))
+	if err != nil {
+		log.Printf("Error fetching watchlist: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist from Redis"})
+		return
+	}
+	writeCalendar(ctx, ical.Calendar{Name: "Watchlist", Events: events}, "watchlist.ics")
+}
+
+// watchlistEvents describes the flights an owner watches as calendar events, with their
+// latest schedule and status. A flight watched in several classes is one event.
+func watchlistEvents(ctx context.Context, rdb *redis.Client, owner string) ([]ical.Event, error) {
+	watched, err := store.WatchedFlights(ctx, rdb, owner)
+	if err != nil {
+		return nil, err
+	}
+	flightIDs := make([]string, len(watched))
+	for i, entry := range watched {
+		flightIDs[i] = entry.FlightID
+	}
+	infos, err := store.FlightInfos(ctx, rdb, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ical.Event
+	byFlight := make(map[string]int)
+	for _, entry := range watched {
+		note := fmt.Sprintf("Watching the %s fare, %s USD when added", entry.Class, entry.AddedPriceUSD)
+		if i, ok := byFlight[entry.FlightID]; ok {
+			events[i].Description += "\n" + note
+			continue
+		}
+
+		flight, ok := infos[entry.FlightID]
+		if !ok {
+			// Fall back on what the watchlist remembers of the flight
+			flight = models.FlightFares{
+				ID:               entry.FlightID,
+				FlightNumber:     entry.FlightNumber,
+				DepartureAirport: models.Airport{Code: entry.DepartureAirport},
+				ArrivalAirport:   models.Airport{Code: entry.ArrivalAirport},
+				DepartureTime:    entry.DepartureTime,
+				Status:           entry.AddedStatus,
+			}
+		}
+		event, ok := ical.FlightEvent(flight)
+		if !ok {
+			continue
+		}
+		event.Description += "\n" + note
+		byFlight[entry.FlightID] = len(events)
+		events = append(events, event)
+	}
+	return events, nil
+}
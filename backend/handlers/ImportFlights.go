@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"FlightAPI/crawlers"
+	"FlightAPI/importer"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxImportSize bounds the size of an uploaded import file.
+const maxImportSize = 32 << 20
+
+// ImportFlights imports the flights of an uploaded CSV, JSON, NDJSON or SSIM file. The multipart form
+// holds the file, an optional JSON column mapping for CSV, and an optional format when the file
+// extension doesn't tell it. With dryRun=true, nothing is stored and the response tells what would change.
+func ImportFlights(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+
+	dryRun := false
+	if value := ctx.Query("dryRun"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+			return
+		}
+	}
+
+	format, ok := importer.DetectFormat(fileHeader.Filename)
+	if value := ctx.PostForm("format"); value != "" {
+		format, ok = importer.ParseFormat(value)
+	}
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, json, ndjson or ssim"})
+		return
+	}
+
+	var mapping importer.Mapping
+	if value := ctx.PostForm("mapping"); value != "" {
+		if mapping, err = importer.LoadMapping(strings.NewReader(value)); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("Error opening uploaded file %s: %v", fileHeader.Filename, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the file"})
+		return
+	}
+	defer file.Close()
+
+	records, err := importer.Read(file, format, mapping)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	result, err := crawlers.Import(ctx.Request.Context(), rdb, records, crawlers.ImportOptions{
+		Source:      fileHeader.Filename,
+		TriggeredBy: ctx.GetString("username"),
+		DryRun:      dryRun,
+		Schedule:    format == importer.SSIM,
+	})
+	if errors.Is(err, crawlers.ErrCrawlInProgress) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "An import is already in progress"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error importing %s: %v", fileHeader.Filename, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import flights"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"FlightAPI/currency"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type loadExchangeRatesRequest struct {
+	Rates []models.ExchangeRate `json:"rates" binding:"required"`
+}
+
+// LoadExchangeRates adds exchange rates to the table, from a JSON body {"rates": [...]} or a
+// text/csv body with the header currency,effective_date,per_usd. A rate replaces the rate of
+// the same currency and effective date. Flights ingested from then on are priced with them.
+func LoadExchangeRates(ctx *gin.Context) {
+	var rates []models.ExchangeRate
+	if ctx.ContentType() == "text/csv" {
+		parsed, err := currency.ParseRates(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rates = parsed
+	} else {
+		var req loadExchangeRatesRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		for _, rate := range req.Rates {
+			valid, err := currency.ValidateRate(rate)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			rates = append(rates, valid)
+		}
+	}
+
+	if len(rates) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No exchange rates given"})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	if err := store.SaveExchangeRates(ctx.Request.Context(), rdb, rates); err != nil {
+		log.Printf("Error saving exchange rates: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save exchange rates"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"loaded": len(rates), "rates": rates})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReleaseHold gives the seats of one of the current user's holds back to the inventory.
+// Confirmed holds can be released too, e.g. when the payment is refunded.
+func ReleaseHold(ctx *gin.Context) {
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	hold, ok := ownHold(ctx, rdb, ctx.Param("id"))
+	if !ok {
+		return
+	}
+
+	released, err := store.ReleaseHold(ctx.Request.Context(), rdb, hold.ID, time.Now())
+	// The seats of a booked hold follow its booking
+	if errors.Is(err, store.ErrHoldBooked) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Hold belongs to booking " + released.Booking + ", cancel the booking instead", "hold": released})
+		return
+	}
+	if errors.Is(err, store.ErrHoldNotActive) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Hold is " + string(released.Status), "hold": released})
+		return
+	}
+	if err != nil {
+		log.Printf("Error releasing hold %s: %v", hold.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release hold"})
+		return
+	}
+
+	log.Printf("Released hold %s of %d %s seats on %s", hold.ID, hold.Seats, hold.Class, hold.FlightID)
+	ctx.JSON(http.StatusOK, released)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"FlightAPI/crawlers"
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplayDeadLetter runs a rejected record through the ingestion again. An accepted record
+// is stored like a crawled one and leaves the dead letters; a rejected one gets its new reason.
+func ReplayDeadLetter(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	letter, err := store.GetDeadLetter(ctx.Request.Context(), rdb, id)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching dead letter %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letter from Redis"})
+		return
+	}
+
+	flight, err := crawlers.Replay(ctx.Request.Context(), rdb, letter)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Record rejected again", "reason": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"flight": flight})
+}
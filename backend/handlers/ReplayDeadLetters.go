@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"FlightAPI/crawlers"
+	"FlightAPI/store"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplayDeadLetters replays the rejected records matching the provider and crawlId filters,
+// up to limit, and reports how many were accepted. The others stay in the dead letters.
+func ReplayDeadLetters(ctx *gin.Context) {
+	limit, err := parseDeadLettersLimit(ctx.Query("limit"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	letters, err := store.ListDeadLetters(ctx.Request.Context(), rdb, ctx.Query("provider"), ctx.Query("crawlId"), limit)
+	if err != nil {
+		log.Printf("Error listing dead letters: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dead letters from Redis"})
+		return
+	}
+
+	replayed := 0
+	failed := []gin.H{}
+	for _, letter := range letters {
+		if _, err := crawlers.Replay(ctx.Request.Context(), rdb, letter); err != nil {
+			failed = append(failed, gin.H{"id": letter.ID, "reason": err.Error()})
+			continue
+		}
+		replayed++
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"replayed": replayed, "failed": failed})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// RunSavedSearch runs one of the current user's saved searches against the latest flights.
+// It answers like /api/flights/search: the filters come from the saved search, and the
+// other parameters of the request, such as currency and group, still apply.
+func RunSavedSearch(ctx *gin.Context) {
+	searchID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	// Searches are stored per user, so other users' searches are simply not found
+	search, err := store.GetSearch(ctx.Request.Context(), rdb, ctx.GetString("username"), searchID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching saved search %s: %v", searchID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved search from Redis"})
+		return
+	}
+
+	query := ctx.Request.URL.Query()
+	for _, param := range searchParams {
+		query.Del(param)
+	}
+	for param, value := range search.Query {
+		if slices.Contains(searchParams, param) {
+			query.Set(param, value)
+		}
+	}
+	ctx.Request.URL.RawQuery = query.Encode()
+
+	ranAt := time.Now().UTC()
+	search.LastRunAt = &ranAt
+	if err := store.SaveSearch(ctx.Request.Context(), rdb, search); err != nil {
+		log.Printf("Error saving search %s: %v", search.ID, err)
+	}
+
+	GetFlightsBySearch(ctx)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"FlightAPI/airports"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAirportResults = 10
+	maxAirportResults     = 50
+)
+
+// SearchAirports autocompletes airports by code, city or name using the q query parameter.
+func SearchAirports(ctx *gin.Context) {
+	query := ctx.Query("q")
+	if query == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	limit := defaultAirportResults
+	if rawLimit := ctx.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = min(parsed, maxAirportResults)
+	}
+
+	results := airports.Default().Search(query, limit)
+	ctx.JSON(http.StatusOK, gin.H{"airports": results})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type setInventoryRequest struct {
+	Seats map[string]int `json:"seats" binding:"required"`
+}
+
+// SetFlightInventory sets the seats left for sale of fare classes of a flight, e.g.
+// {"seats": {"Economy": 120, "Business": 12}}. Seats already held are not counted in.
+func SetFlightInventory(ctx *gin.Context) {
+	flightID := strings.ToUpper(ctx.Param("id"))
+
+	var req setInventoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || len(req.Seats) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	inventory := models.Inventory{FlightID: flightID, Seats: make(map[models.CabinClass]int, len(req.Seats))}
+	for raw, seats := range req.Seats {
+		class, ok := models.ParseCabinClass(raw)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown class " + raw})
+			return
+		}
+		if seats < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "seats must not be negative"})
+			return
+		}
+		inventory.Seats[class] = seats
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	if err := store.SetInventory(ctx.Request.Context(), rdb, inventory); err != nil {
+		log.Printf("Error setting inventory of flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save inventory"})
+		return
+	}
+
+	updated, err := store.GetInventory(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching inventory of flight '%s': %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory from Redis"})
+		return
+	}
+	ctx.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"FlightAPI/airports"
+	"FlightAPI/events"
+	"FlightAPI/models"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// streamHeartbeat is how often a comment is sent to keep idle connections and proxies alive.
+const streamHeartbeat = 15 * time.Second
+
+// StreamFlightEvents pushes flight created, updated, status and price change events as
+// Server-Sent Events. Events can be filtered by origin, destination, date and flightNumber,
+// and clients resume after a disconnect with the Last-Event-ID header.
+func StreamFlightEvents(ctx *gin.Context) {
+	filter, err := parseEventFilter(ctx.Query("origin"), ctx.Query("destination"), ctx.Query("date"), ctx.Query("flightNumber"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// EventSource sends Last-Event-ID on reconnect; the query parameter helps clients that can't set headers
+	lastID := ctx.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = ctx.Query("lastEventId")
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+
+	// Subscribe before replaying so no event falls between the replay and the live feed
+	sub := rdb.Subscribe(reqCtx, events.Channel)
+	defer sub.Close()
+	if _, err := sub.Receive(reqCtx); err != nil {
+		log.Printf("Error subscribing to flight events: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to subscribe to flight events"})
+		return
+	}
+
+	var missed []models.FlightEvent
+	if lastID != "" {
+		missed, err = events.Since(reqCtx, rdb, lastID)
+		if err != nil {
+			log.Printf("Error replaying flight events since %s: %v", lastID, err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	for _, event := range missed {
+		lastID = event.ID
+		if filter.Matches(event) {
+			writeEvent(ctx, event)
+		}
+	}
+
+	messages := sub.Channel()
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-reqCtx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			event, err := events.Decode(message.Payload)
+			if err != nil {
+				log.Printf("Error decoding flight event: %v", err)
+				continue
+			}
+			// Skip events already sent during the replay
+			if !events.IsAfter(event.ID, lastID) {
+				continue
+			}
+			lastID = event.ID
+			if filter.Matches(event) {
+				writeEvent(ctx, event)
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent sends one event to the client and flushes it.
+func writeEvent(ctx *gin.Context, event models.FlightEvent) {
+	ctx.Render(-1, sse.Event{Id: event.ID, Event: event.Type, Data: event})
+	ctx.Writer.Flush()
+}
+
+// parseEventFilter builds an event filter from optional values. Origin and destination
+// accept airport and metro codes like flight search; the date is a YYYY-MM-DD departure date.
+func parseEventFilter(origin, destination, date, flightNumber string) (events.Filter, error) {
+	filter := events.Filter{Date: date, FlightNumber: flightNumber}
+
+	// Snapshots read the flight list of the date, so it must be a date and not any key
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return events.Filter{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	if origin != "" {
+		origins, err := airports.Default().Expand(origin, 0)
+		if err != nil {
+			return events.Filter{}, fmt.Errorf("invalid origin: %w", err)
+		}
+		filter.Origins = origins
+	}
+	if destination != "" {
+		destinations, err := airports.Default().Expand(destination, 0)
+		if err != nil {
+			return events.Filter{}, fmt.Errorf("invalid destination: %w", err)
+		}
+		filter.Destinations = destinations
+	}
+
+	return filter, nil
+}
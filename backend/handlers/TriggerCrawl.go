@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"FlightAPI/crawlers"
+	"FlightAPI/models"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type triggerCrawlRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Force    bool   `json:"force"` // Parse the payload even if it didn't change since the last run
+}
+
+// TriggerCrawl starts an on-demand crawl of a provider. The crawl runs in the background;
+// its progress is followed through the returned run.
+func TriggerCrawl(ctx *gin.Context) {
+	var req triggerCrawlRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "providers": crawlers.Providers()})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	run, err := crawlers.Start(ctx.Request.Context(), rdb, provider, crawlers.Options{
+		Trigger:     models.TriggerManual,
+		TriggeredBy: ctx.GetString("username"),
+		Force:       req.Force,
+	})
+	switch {
+	case errors.Is(err, crawlers.ErrUnknownProvider):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "providers": crawlers.Providers()})
+		return
+	case errors.Is(err, crawlers.ErrCrawlInProgress):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error starting crawl of %s: %v", provider, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start crawl"})
+		return
+	}
+
+	ctx.Header("Location", "/api/admin/crawls/"+run.ID)
+	ctx.JSON(http.StatusAccepted, run)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// UnwatchFlight removes an entry from the current user's watchlist.
+func UnwatchFlight(ctx *gin.Context) {
+	entryID := ctx.Param("id")
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	err := store.UnwatchFlight(ctx.Request.Context(), rdb, ctx.GetString("username"), entryID)
+	if errors.Is(err, store.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Watchlist entry not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error removing watchlist entry %s: %v", entryID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove flight from watchlist"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
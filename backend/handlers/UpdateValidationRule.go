@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"FlightAPI/store"
+	"FlightAPI/validation"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type updateValidationRuleRequest struct {
+	Severity string `json:"severity" binding:"required"`
+}
+
+// UpdateValidationRule changes the severity of a data-quality rule: reject, warn or off.
+// Required rules always reject. The change applies from the next crawl.
+func UpdateValidationRule(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	var req updateValidationRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	severity, ok := validation.ParseSeverity(req.Severity)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "severity must be reject, warn or off"})
+		return
+	}
+
+	rule, ok := validation.Defaults().Lookup(name)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Validation rule not found"})
+		return
+	}
+	if rule.Required && severity != validation.Reject {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Rule " + name + " is required and always rejects"})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	if err := store.SetRuleSeverity(ctx.Request.Context(), rdb, name, string(severity)); err != nil {
+		log.Printf("Error updating validation rule %s: %v", name, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update validation rule"})
+		return
+	}
+
+	rule.Severity = severity
+	ctx.JSON(http.StatusOK, rule)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxWatchedFlights is how many flight fares one user can watch.
+const maxWatchedFlights = 100
+
+type watchFlightRequest struct {
+	FlightID string `json:"flightId" binding:"required"`
+	Class    string `json:"class"`
+}
+
+// WatchFlight adds a flight fare to the current user's watchlist, remembering its status
+// and price so the watchlist can show what changed. Without a class, the cheapest fare of
+// the flight is watched.
+func WatchFlight(ctx *gin.Context) {
+	var req watchFlightRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	class, ok := models.ParseCabinClass(req.Class)
+	if req.Class != "" && !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "class must be economy, premium economy, business or first"})
+		return
+	}
+
+	rdb, ok := ctx.MustGet("redisClient").(*redis.Client)
+	if !ok {
+		log.Println("Redis client not found in context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Redis client not found"})
+		return
+	}
+
+	flightID := strings.ToUpper(req.FlightID)
+	flight, found, err := store.GetFlight(ctx.Request.Context(), rdb, flightID)
+	if err != nil {
+		log.Printf("Error fetching flight %s: %v", flightID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch flight from Redis"})
+		return
+	}
+	if !found {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Flight not found"})
+		return
+	}
+
+	var fare *models.Fare
+	for i, candidate := range flight.Fares {
+		if candidate.Class == class || (class == "" && (fare == nil || candidate.PriceUSD < fare.PriceUSD)) {
+			fare = &flight.Fares[i]
+		}
+	}
+	if fare == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No " + string(class) + " fare on this flight"})
+		return
+	}
+
+	owner := ctx.GetString("username")
+	watched, err := store.WatchedFlights(ctx.Request.Context(), rdb, owner)
+	if err != nil {
+		log.Printf("Error fetching watchlist: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist from Redis"})
+		return
+	}
+	for _, entry := range watched {
+		if entry.FlightID == flight.ID && entry.Class == fare.Class {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Fare is already on your watchlist", "watched": entry})
+			return
+		}
+	}
+	if len(watched) >= maxWatchedFlights {
+		ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can watch at most %d fares", maxWatchedFlights)})
+		return
+	}
+
+	entry := models.WatchedFlight{
+		ID:               store.NewID(),
+		Owner:            owner,
+		FlightID:         flight.ID,
+		Class:            fare.Class,
+		FlightNumber:     flight.FlightNumber,
+		DepartureAirport: flight.DepartureAirport.Code,
+		ArrivalAirport:   flight.ArrivalAirport.Code,
+		DepartureTime:    flight.DepartureTime,
+		AddedAt:          time.Now().UTC(),
+		AddedStatus:      flight.Status,
+		AddedPriceUSD:    fare.PriceUSD,
+	}
+	if err := store.WatchFlight(ctx.Request.Context(), rdb, entry); err != nil {
+		log.Printf("Error saving watchlist entry: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add flight to watchlist"})
+		return
+	}
+
+	log.Printf("%s watches %s %s", owner, entry.FlightID, entry.Class)
+	ctx.JSON(http.StatusCreated, entry)
+}
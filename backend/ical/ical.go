@@ -0,0 +1,342 @@
+// Package ical writes flights as iCalendar (RFC 5545) events, so travellers can add them
+// to their calendars or subscribe to a feed of them. Events start and end in the timezones
+// of the departure and arrival airports, which are described in VTIMEZONE components.
+package ical
+
+import (
+	"FlightAPI/airports"
+	"FlightAPI/models"
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	// The image is built from scratch, without a zone database to load airport timezones from
+	_ "time/tzdata"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of the calendars written by Write.
+const ContentType = "text/calendar; charset=utf-8"
+
+// productID identifies the application that wrote a calendar.
+const productID = "-//FlightAPI//Flights//EN"
+
+// uidDomain makes event UIDs globally unique, as RFC 5545 asks.
+const uidDomain = "flightapi"
+
+// maxLineLength is the length in octets after which content lines are folded.
+const maxLineLength = 75
+
+// Calendar is a set of events with the name calendar apps show for it.
+type Calendar struct {
+	Name string
+	// RefreshInterval tells subscribed calendar apps how often to fetch the calendar
+	// again. Zero leaves it to them.
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is a flight in a calendar.
+type Event struct {
+	UID         string // Stable across exports, so re-importing updates the event
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time // In the timezone of the departure airport
+	End         time.Time // In the timezone of the arrival airport
+	Cancelled   bool
+}
+
+// FlightEvent describes a flight as an event from its departure to its arrival, in the
+// local time of each airport. It returns false if the flight times can't be parsed.
+func FlightEvent(flight models.FlightFares) (Event, bool) {
+	departure, err := time.Parse(time.RFC3339, flight.DepartureTime)
+	if err != nil {
+		return Event{}, false
+	}
+	arrival, err := time.Parse(time.RFC3339, flight.ArrivalTime)
+	if err != nil || arrival.Before(departure) {
+		arrival = departure
+	}
+
+	from := enrichAirport(flight.DepartureAirport)
+	to := enrichAirport(flight.ArrivalAirport)
+
+	lines := []string{"Flight " + flight.FlightNumber}
+	if flight.Airline != "" {
+		lines[0] = fmt.Sprintf("%s flight %s", flight.Airline, flight.FlightNumber)
+	}
+	if flight.Status != "" {
+		lines = append(lines, "Status: "+string(flight.Status))
+	}
+	lines = append(lines, "From: "+airportName(from), "To: "+airportName(to))
+	if flight.Aircraft != "" {
+		lines = append(lines, "Aircraft: "+flight.Aircraft)
+	}
+
+	return Event{
+		UID:         flight.ID + "@" + uidDomain,
+		Summary:     fmt.Sprintf("%s %s to %s", flight.FlightNumber, from.Code, to.Code),
+		Description: strings.Join(lines, "\n"),
+		Location:    airportName(from),
+		Start:       inAirportTime(departure, from),
+		End:         inAirportTime(arrival, to),
+		Cancelled:   flight.Status == models.StatusCancelled,
+	}, true
+}
+
+// enrichAirport fills in the name and timezone of airports known to the registry.
+func enrichAirport(airport models.Airport) models.Airport {
+	if enriched, ok := airports.Default().Enrich(airport); ok {
+		return enriched
+	}
+	return airport
+}
+
+func airportName(airport models.Airport) string {
+	if airport.Name == "" {
+		return airport.Code
+	}
+	return fmt.Sprintf("%s (%s)", airport.Name, airport.Code)
+}
+
+// inAirportTime returns t in the timezone of an airport, or unchanged if the airport has
+// no known timezone.
+func inAirportTime(t time.Time, airport models.Airport) time.Time {
+	if airport.Timezone == "" {
+		return t
+	}
+	loc, err := time.LoadLocation(airport.Timezone)
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// Write writes a calendar as an iCalendar object, stamping events with now.
+func Write(w io.Writer, calendar Calendar, now time.Time) error {
+	out := &writer{w: bufio.NewWriter(w)}
+
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:" + productID)
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	if calendar.Name != "" {
+		out.line("X-WR-CALNAME:" + escapeText(calendar.Name))
+	}
+	if calendar.RefreshInterval > 0 {
+		interval := formatDuration(calendar.RefreshInterval)
+		out.line("REFRESH-INTERVAL;VALUE=DURATION:" + interval)
+		out.line("X-PUBLISHED-TTL:" + interval)
+	}
+
+	for _, zone := range timezones(calendar.Events) {
+		zone.write(out)
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, event := range calendar.Events {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + escapeText(event.UID))
+		out.line("DTSTAMP:" + stamp)
+		out.line(formatTime("DTSTART", event.Start))
+		out.line(formatTime("DTEND", event.End))
+		out.line("SUMMARY:" + escapeText(event.Summary))
+		if event.Description != "" {
+			out.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		if event.Location != "" {
+			out.line("LOCATION:" + escapeText(event.Location))
+		}
+		if event.Cancelled {
+			out.line("STATUS:CANCELLED")
+		} else {
+			out.line("STATUS:CONFIRMED")
+		}
+		out.line("TRANSP:OPAQUE")
+		out.line("END:VEVENT")
+	}
+
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// writer writes folded content lines ending in CRLF, keeping the first error.
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, folding it into lines of at most maxLineLength octets that
+// continue with a space. Lines are only folded between UTF-8 characters.
+func (w *writer) line(content string) {
+	if w.err != nil {
+		return
+	}
+	limit := maxLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(content[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		content = content[cut:]
+		// Continuation lines start with the space
+		limit = maxLineLength - 1
+	}
+	_, w.err = w.w.WriteString(content + "\r\n")
+}
+
+// escapeText escapes a TEXT value: backslashes, semicolons, commas and newlines.
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// formatTime writes a date-time property in the local time of its zone with a TZID, or
+// in UTC for times without a named zone.
+func formatTime(property string, t time.Time) string {
+	if !hasNamedZone(t) {
+		return property + ":" + t.UTC().Format("20060102T150405Z")
+	}
+	return fmt.Sprintf("%s;TZID=%s:%s", property, t.Location().String(), t.Format("20060102T150405"))
+}
+
+// hasNamedZone reports whether t is in an IANA timezone, rather than UTC or a fixed offset
+// parsed from a timestamp.
+func hasNamedZone(t time.Time) bool {
+	name := t.Location().String()
+	return strings.Contains(name, "/")
+}
+
+// formatDuration writes a duration as an RFC 5545 DURATION, e.g. PT1H30M.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	text := "PT"
+	if hours := int(d / time.Hour); hours > 0 {
+		text += fmt.Sprintf("%dH", hours)
+	}
+	if minutes := int(d % time.Hour / time.Minute); minutes > 0 {
+		text += fmt.Sprintf("%dM", minutes)
+	}
+	if seconds := int(d % time.Minute / time.Second); seconds > 0 || text == "PT" {
+		text += fmt.Sprintf("%dS", seconds)
+	}
+	return text
+}
+
+// timezone is a VTIMEZONE component: the offsets of a zone over the span of the events
+// that use it.
+type timezone struct {
+	name        string
+	transitions []transition
+}
+
+// transition is a change of UTC offset, written as a STANDARD or DAYLIGHT component.
+type transition struct {
+	at         time.Time
+	offsetFrom int
+	offsetTo   int
+	abbrev     string
+	daylight   bool
+}
+
+// timezones describes every named zone the events start or end in, sorted by name.
+func timezones(events []Event) []timezone {
+	type span struct {
+		loc         *time.Location
+		first, last time.Time
+	}
+	spans := make(map[string]*span)
+	for _, event := range events {
+		for _, t := range []time.Time{event.Start, event.End} {
+			if !hasNamedZone(t) {
+				continue
+			}
+			s, ok := spans[t.Location().String()]
+			if !ok {
+				spans[t.Location().String()] = &span{loc: t.Location(), first: t, last: t}
+				continue
+			}
+			if t.Before(s.first) {
+				s.first = t
+			}
+			if t.After(s.last) {
+				s.last = t
+			}
+		}
+	}
+
+	zones := make([]timezone, 0, len(spans))
+	for name, s := range spans {
+		zones = append(zones, timezone{name: name, transitions: transitions(s.first.In(s.loc), s.last.In(s.loc))})
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].name < zones[j].name })
+	return zones
+}
+
+// transitions lists the offset in effect at first, from when it started, and every change
+// of offset up to last.
+func transitions(first, last time.Time) []transition {
+	var result []transition
+	t := first
+	for {
+		start, end := t.ZoneBounds()
+		abbrev, offset := t.Zone()
+		current := transition{offsetFrom: offset, offsetTo: offset, abbrev: abbrev, daylight: t.IsDST()}
+		if start.IsZero() {
+			// The offset has always been in effect, as far as the zone database goes
+			current.at = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+		} else {
+			current.at = start
+			_, current.offsetFrom = start.Add(-time.Second).Zone()
+		}
+		result = append(result, current)
+
+		if end.IsZero() || end.After(last) {
+			return result
+		}
+		t = end
+	}
+}
+
+func (z timezone) write(out *writer) {
+	out.line("BEGIN:VTIMEZONE")
+	out.line("TZID:" + z.name)
+	for _, t := range z.transitions {
+		component := "STANDARD"
+		if t.daylight {
+			component = "DAYLIGHT"
+		}
+		out.line("BEGIN:" + component)
+		// The onset is written in the local time before the change
+		out.line("DTSTART:" + t.at.In(time.FixedZone("", t.offsetFrom)).Format("20060102T150405"))
+		out.line("TZOFFSETFROM:" + formatOffset(t.offsetFrom))
+		out.line("TZOFFSETTO:" + formatOffset(t.offsetTo))
+		if t.abbrev != "" {
+			out.line("TZNAME:" + escapeText(t.abbrev))
+		}
+		out.line("END:" + component)
+	}
+	out.line("END:VTIMEZONE")
+}
+
+// formatOffset writes a UTC offset in seconds as +HHMM, or +HHMMSS when it has seconds.
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	text := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if seconds := offset % 60; seconds != 0 {
+		text += fmt.Sprintf("%02d", seconds)
+	}
+	return text
+}
@@ -0,0 +1,101 @@
+package ical
+
+import (
+	"FlightAPI/models"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFlight() models.FlightFares {
+	return models.FlightFares{
+		ID:               "DL123-2025-04-28",
+		FlightNumber:     "DL123",
+		Airline:          "Delta Air Lines",
+		DepartureAirport: models.Airport{Code: "ATL"},
+		ArrivalAirport:   models.Airport{Code: "LHR"},
+		DepartureTime:    "2025-04-29T02:30:00Z",
+		ArrivalTime:      "2025-04-29T10:40:00Z",
+		Aircraft:         "339",
+		Status:           models.StatusScheduled,
+	}
+}
+
+func TestFlightEvent(t *testing.T) {
+	event, ok := FlightEvent(testFlight())
+	require.True(t, ok)
+
+	assert.Equal(t, "DL123-2025-04-28@flightapi", event.UID)
+	assert.Equal(t, "DL123 ATL to LHR", event.Summary)
+	// Times are in the local time of each airport, looked up from the registry
+	assert.Equal(t, "America/New_York", event.Start.Location().String())
+	assert.Equal(t, "2025-04-28T22:30:00-04:00", event.Start.Format(time.RFC3339))
+	assert.Equal(t, "Europe/London", event.End.Location().String())
+	assert.Equal(t, "2025-04-29T11:40:00+01:00", event.End.Format(time.RFC3339))
+	assert.Contains(t, event.Description, "Status: Scheduled")
+	assert.False(t, event.Cancelled)
+
+	flight := testFlight()
+	flight.DepartureTime = "soon"
+	_, ok = FlightEvent(flight)
+	assert.False(t, ok)
+}
+
+func TestWrite(t *testing.T) {
+	event, ok := FlightEvent(testFlight())
+	require.True(t, ok)
+	event.Description += "\nBooking K7QM2X; 2 seats, Economy"
+	cancelled := event
+	cancelled.UID = "DL124-2025-05-02@flightapi"
+	cancelled.Cancelled = true
+
+	var out bytes.Buffer
+	calendar := Calendar{Name: "My flights", RefreshInterval: time.Hour, Events: []Event{event, cancelled}}
+	require.NoError(t, Write(&out, calendar, time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)))
+	text := out.String()
+
+	assert.True(t, strings.HasPrefix(text, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(text, "END:VCALENDAR\r\n"))
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength, line)
+	}
+
+	// Unfold the content lines before looking for properties
+	unfolded := strings.ReplaceAll(text, "\r\n ", "")
+	assert.Contains(t, unfolded, "X-WR-CALNAME:My flights\r\n")
+	assert.Contains(t, unfolded, "REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n")
+	assert.Contains(t, unfolded, "DTSTAMP:20250401T100000Z\r\n")
+	assert.Contains(t, unfolded, "DTSTART;TZID=America/New_York:20250428T223000\r\n")
+	assert.Contains(t, unfolded, "DTEND;TZID=Europe/London:20250429T114000\r\n")
+	assert.Contains(t, unfolded, `Booking K7QM2X\; 2 seats\, Economy`)
+	assert.Contains(t, unfolded, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, unfolded, "STATUS:CANCELLED\r\n")
+
+	// Both zones are described once, from the offset change before the events
+	assert.Equal(t, 2, strings.Count(unfolded, "BEGIN:VTIMEZONE"))
+	assert.Contains(t, unfolded, "TZID:America/New_York\r\nBEGIN:DAYLIGHT\r\nDTSTART:20250309T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\nEND:DAYLIGHT\r\n")
+	assert.Contains(t, unfolded, "TZID:Europe/London\r\nBEGIN:DAYLIGHT\r\nDTSTART:20250330T010000\r\nTZOFFSETFROM:+0000\r\nTZOFFSETTO:+0100\r\nTZNAME:BST\r\n")
+}
+
+func TestWriteAcrossOffsetChange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	events := []Event{
+		{UID: "a", Start: time.Date(2025, 10, 20, 9, 0, 0, 0, newYork), End: time.Date(2025, 10, 20, 11, 0, 0, 0, newYork)},
+		{UID: "b", Start: time.Date(2025, 11, 20, 9, 0, 0, 0, newYork), End: time.Date(2025, 11, 20, 11, 0, 0, 0, newYork)},
+		// Times without a named zone are written in UTC
+		{UID: "c", Start: time.Date(2025, 11, 21, 9, 0, 0, 0, time.FixedZone("", 3600)), End: time.Date(2025, 11, 21, 11, 0, 0, 0, time.FixedZone("", 3600))},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, Calendar{Events: events}, time.Now()))
+	text := out.String()
+
+	assert.Contains(t, text, "BEGIN:STANDARD\r\nDTSTART:20251102T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\nEND:STANDARD\r\n")
+	assert.Contains(t, text, "DTSTART:20251121T080000Z\r\n")
+	assert.Equal(t, 1, strings.Count(text, "BEGIN:VTIMEZONE"))
+}
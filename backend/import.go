@@ -0,0 +1,99 @@
+package main
+
+import (
+	"FlightAPI/crawlers"
+	"FlightAPI/importer"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// runImport implements the import command, which imports the flights of a CSV, JSON,
+// NDJSON or SSIM file into Redis. It returns the exit code of the process.
+//
+//	app import [-format csv] [-mapping mapping.json] [-dry-run] [-redis redis:6379] flights.csv
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatName := flags.String("format", "", "file format: csv, json, ndjson or ssim (default: from the file extension)")
+	mappingPath := flags.String("mapping", "", "JSON column-mapping config for CSV files")
+	dryRun := flags.Bool("dry-run", false, "report what would change without storing anything")
+	redisAddr := flags.String("redis", "redis:6379", "Redis address")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: app import [flags] FILE")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	format, ok := importer.DetectFormat(path)
+	if *formatName != "" {
+		format, ok = importer.ParseFormat(*formatName)
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "format must be csv, json, ndjson or ssim")
+		return 2
+	}
+
+	var mapping importer.Mapping
+	if *mappingPath != "" {
+		mappingFile, err := os.Open(*mappingPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		mapping, err = importer.LoadMapping(mappingFile)
+		mappingFile.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer file.Close()
+
+	records, err := importer.Read(file, format, mapping)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: *redisAddr, Password: "", DB: 0})
+	defer rdb.Close()
+
+	result, err := crawlers.Import(context.Background(), rdb, records, crawlers.ImportOptions{
+		Source:      path,
+		TriggeredBy: "cli",
+		DryRun:      *dryRun,
+		Schedule:    format == importer.SSIM,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(result.Rejected) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,299 @@
+// Package importer reads flight records from CSV, JSON, NDJSON and SSIM files. Records are
+// returned as JSON in the shape of models.Flight so they go through the same ingestion
+// as crawled data, and rejected ones can be dead-lettered as they were read.
+package importer
+
+import (
+	"FlightAPI/money"
+	"FlightAPI/ssim"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the format of an import file.
+type Format string
+
+const (
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	SSIM   Format = "ssim" // IATA SSIM Chapter 7 schedules
+)
+
+// maxLineSize bounds an NDJSON line.
+const maxLineSize = 1 << 20
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(value string) (Format, bool) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case CSV, JSON, NDJSON, SSIM:
+		return format, true
+	case "jsonl":
+		return NDJSON, true
+	}
+	return "", false
+}
+
+// DetectFormat returns the format of a file from its extension.
+func DetectFormat(filename string) (Format, bool) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// fields are the flight fields a CSV column can be mapped to.
+var fields = []string{
+	"flightNumber", "airline", "departureAirport", "arrivalAirport", "departureTime",
+	"arrivalTime", "class", "status", "duration", "aircraft", "priceUSD",
+	"priceAmount", "currency", "fareBasis", "seatsRemaining", "checkedBags",
+	"refundable", "changeable",
+}
+
+// nestedFields are the CSV fields that belong to an object of the flight, e.g. the
+// checkedBags column sets baggage.checkedBags.
+var nestedFields = map[string]string{
+	"checkedBags": "baggage",
+	"refundable":  "fareRules",
+	"changeable":  "fareRules",
+}
+
+// Mapping configures how CSV columns map to flight fields.
+type Mapping struct {
+	// Columns maps a flight field to the header of its column. Fields that are not
+	// listed are read from the column named after the field, e.g. priceUSD.
+	Columns map[string]string `json:"columns"`
+	// TimeLayout is the Go time layout of the departure and arrival times when they
+	// are not RFC 3339, e.g. "2006-01-02 15:04".
+	TimeLayout string `json:"timeLayout"`
+	// Timezone is the IANA zone of times parsed with TimeLayout. Defaults to UTC.
+	Timezone string `json:"timezone"`
+}
+
+// LoadMapping reads a JSON column-mapping config.
+func LoadMapping(r io.Reader) (Mapping, error) {
+	var mapping Mapping
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&mapping); err != nil {
+		return Mapping{}, fmt.Errorf("invalid mapping: %w", err)
+	}
+
+	for field := range mapping.Columns {
+		if !isField(field) {
+			return Mapping{}, fmt.Errorf("invalid mapping: unknown field %q, expected one of %s", field, strings.Join(fields, ", "))
+		}
+	}
+	if mapping.Timezone != "" {
+		if _, err := time.LoadLocation(mapping.Timezone); err != nil {
+			return Mapping{}, fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+	return mapping, nil
+}
+
+// Read returns the records of a file. CSV files need a header row; mapping is only used for CSV.
+// Syntax errors fail the whole file, since the records after them can't be trusted.
+func Read(r io.Reader, format Format, mapping Mapping) ([]json.RawMessage, error) {
+	switch format {
+	case CSV:
+		return readCSV(r, mapping)
+	case JSON:
+		return readJSON(r)
+	case NDJSON:
+		return readNDJSON(r)
+	case SSIM:
+		return readSSIM(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// readJSON reads the {"flights": [...]} document sent by the providers. A bare array is accepted too.
+func readJSON(r io.Reader) ([]json.RawMessage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return records, nil
+	}
+
+	var document struct {
+		Flights []json.RawMessage `json:"flights"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if document.Flights == nil {
+		return nil, errors.New(`invalid JSON: expected a "flights" array`)
+	}
+	return document.Flights, nil
+}
+
+// readNDJSON reads one record per line, skipping blank lines.
+func readNDJSON(r io.Reader) ([]json.RawMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var records []json.RawMessage
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if !json.Valid(text) {
+			return nil, fmt.Errorf("invalid JSON on line %d", line)
+		}
+		records = append(records, json.RawMessage(append([]byte(nil), text...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// readSSIM expands the flight legs of a schedule into one record per dated flight and cabin.
+func readSSIM(r io.Reader) ([]json.RawMessage, error) {
+	flights, err := ssim.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSIM: %w", err)
+	}
+
+	records := make([]json.RawMessage, 0, len(flights))
+	for _, flight := range flights {
+		data, err := json.Marshal(flight)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, data)
+	}
+	return records, nil
+}
+
+// readCSV converts every row to a flight record using the column mapping.
+func readCSV(r io.Reader, mapping Mapping) ([]json.RawMessage, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("invalid CSV: missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	// Index of the column of each field
+	index := make(map[string]int)
+	for _, field := range fields {
+		column := field
+		if mapped, ok := mapping.Columns[field]; ok {
+			column = mapped
+		}
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), column) {
+				index[field] = i
+				break
+			}
+		}
+	}
+	if len(index) == 0 {
+		return nil, errors.New("invalid CSV: no column matches a flight field")
+	}
+
+	location := time.UTC
+	if mapping.Timezone != "" {
+		if location, err = time.LoadLocation(mapping.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+
+	var records []json.RawMessage
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		record := make(map[string]interface{})
+		for field, i := range index {
+			if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+			value := convertCell(field, strings.TrimSpace(row[i]), mapping.TimeLayout, location)
+			if parent, ok := nestedFields[field]; ok {
+				nested, _ := record[parent].(map[string]interface{})
+				if nested == nil {
+					nested = make(map[string]interface{})
+					record[parent] = nested
+				}
+				nested[field] = value
+				continue
+			}
+			record[field] = value
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, data)
+	}
+	return records, nil
+}
+
+// convertCell converts a CSV cell to the JSON value of a flight field. Values that can't be
+// converted are kept as text, so the record is rejected with its original data.
+func convertCell(field, value, timeLayout string, location *time.Location) interface{} {
+	switch field {
+	case "departureAirport", "arrivalAirport":
+		return map[string]string{"code": value}
+	case "priceUSD":
+		// Keep the decimal text so the price is read without float rounding
+		if _, err := money.ParseUSD(value); err == nil {
+			return json.Number(value)
+		}
+	case "priceAmount":
+		if amount, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return amount
+		}
+	case "seatsRemaining", "checkedBags":
+		if count, err := strconv.Atoi(value); err == nil {
+			return count
+		}
+	case "refundable", "changeable":
+		if allowed, err := strconv.ParseBool(value); err == nil {
+			return allowed
+		}
+	case "departureTime", "arrivalTime":
+		if timeLayout != "" {
+			if t, err := time.ParseInLocation(timeLayout, value, location); err == nil {
+				return t.Format(time.RFC3339)
+			}
+		}
+	}
+	return value
+}
+
+func isField(name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
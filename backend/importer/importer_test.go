@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, records []json.RawMessage) []models.Flight {
+	flights := make([]models.Flight, len(records))
+	for i, record := range records {
+		assert.NoError(t, json.Unmarshal(record, &flights[i]))
+	}
+	return flights
+}
+
+func TestReadCSV(t *testing.T) {
+	mapping, err := LoadMapping(strings.NewReader(`{
+		"columns": {"flightNumber": "Flight", "departureAirport": "From", "arrivalAirport": "To", "departureTime": "Departs", "arrivalTime": "Arrives", "priceUSD": "Fare"},
+		"timeLayout": "2006-01-02 15:04",
+		"timezone": "America/New_York"
+	}`))
+	assert.NoError(t, err)
+
+	file := "Flight,From,To,Departs,Arrives,class,Fare\n" +
+		"DL123,ATL,JFK,2025-04-28 10:00,2025-04-28 12:15,Economy,250.50\n" +
+		"BA117,LHR,JFK,2025-04-28 18:00,,Business,\n"
+
+	records, err := Read(strings.NewReader(file), CSV, mapping)
+	assert.NoError(t, err)
+	flights := decode(t, records)
+	if assert.Len(t, flights, 2) {
+		assert.Equal(t, "DL123", flights[0].FlightNumber)
+		assert.Equal(t, "ATL", flights[0].DepartureAirport.Code)
+		assert.Equal(t, "2025-04-28T10:00:00-04:00", flights[0].DepartureTime)
+		assert.Equal(t, money.USD(25050), flights[0].PriceUSD)
+		assert.Equal(t, models.CabinEconomy, flights[0].Class)
+		// Empty cells are left out
+		assert.Empty(t, flights[1].ArrivalTime)
+		assert.Zero(t, flights[1].PriceUSD)
+	}
+}
+
+func TestReadCSVFareDetails(t *testing.T) {
+	file := "flightNumber,class,fareBasis,seatsRemaining,checkedBags,refundable,changeable\n" +
+		"DL123,Economy,YLOWUS,4,1,false,true\n" +
+		"BA117,Business,,,,,\n"
+
+	records, err := Read(strings.NewReader(file), CSV, Mapping{})
+	assert.NoError(t, err)
+	flights := decode(t, records)
+	if assert.Len(t, flights, 2) {
+		assert.Equal(t, "YLOWUS", flights[0].FareBasis)
+		if assert.NotNil(t, flights[0].SeatsRemaining) {
+			assert.Equal(t, 4, *flights[0].SeatsRemaining)
+		}
+		assert.Equal(t, &models.Baggage{CheckedBags: 1}, flights[0].Baggage)
+		assert.Equal(t, &models.FareRules{Refundable: false, Changeable: true}, flights[0].FareRules)
+		// Fares without details don't claim any
+		assert.Nil(t, flights[1].SeatsRemaining)
+		assert.Nil(t, flights[1].Baggage)
+		assert.Nil(t, flights[1].FareRules)
+	}
+}
+
+func TestReadCSVKeepsInvalidValues(t *testing.T) {
+	records, err := Read(strings.NewReader("flightNumber,priceUSD\nDL123,cheap\n"), CSV, Mapping{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"flightNumber":"DL123","priceUSD":"cheap"}`, string(records[0]))
+}
+
+func TestReadJSON(t *testing.T) {
+	records, err := Read(strings.NewReader(`{"flights":[{"flightNumber":"DL123"},{"flightNumber":"BA117"}]}`), JSON, Mapping{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = Read(strings.NewReader(`[{"flightNumber":"DL123"}]`), JSON, Mapping{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	_, err = Read(strings.NewReader(`{"data":[]}`), JSON, Mapping{})
+	assert.Error(t, err)
+}
+
+func TestReadNDJSON(t *testing.T) {
+	records, err := Read(strings.NewReader("{\"flightNumber\":\"DL123\"}\n\n{\"flightNumber\":\"BA117\"}\n"), NDJSON, Mapping{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DL123", "BA117"}, []string{decode(t, records)[0].FlightNumber, decode(t, records)[1].FlightNumber})
+
+	_, err = Read(strings.NewReader("{\"flightNumber\":\"DL123\"}\n{oops\n"), NDJSON, Mapping{})
+	assert.EqualError(t, err, "invalid JSON on line 2")
+}
+
+func TestLoadMapping(t *testing.T) {
+	_, err := LoadMapping(strings.NewReader(`{"columns": {"price": "Fare"}}`))
+	assert.Error(t, err)
+
+	_, err = LoadMapping(strings.NewReader(`{"timezone": "Mars/Olympus"}`))
+	assert.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	for filename, expected := range map[string]Format{"a.csv": CSV, "a.JSON": JSON, "a.ndjson": NDJSON, "a.jsonl": NDJSON} {
+		format, ok := DetectFormat(filename)
+		assert.True(t, ok, filename)
+		assert.Equal(t, expected, format, filename)
+	}
+	_, ok := DetectFormat("flights.xlsx")
+	assert.False(t, ok)
+}
@@ -0,0 +1,184 @@
+// Package leader elects one instance among the backend replicas through a lease in Redis.
+// The leader renews the lease while it runs; if it dies, the lease expires and another
+// replica takes over.
+package leader
+
+import (
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// leaseTTL is how long a lease lasts without renewal, i.e. the failover delay.
+	leaseTTL = 15 * time.Second
+	// renewInterval must leave room for a couple of failed renewals before the lease expires.
+	renewInterval = 5 * time.Second
+)
+
+// renewScript extends the lease only if this instance still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript gives the lease up only if this instance still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Status describes the leadership seen by one instance.
+type Status struct {
+	Instance       string    `json:"instance"`
+	Leader         string    `json:"leader,omitempty"` // Empty while nobody holds the lease
+	IsLeader       bool      `json:"isLeader"`
+	LeaseExpiresAt time.Time `json:"leaseExpiresAt,omitzero"`
+}
+
+// Elector campaigns for the lease of a role and keeps it while this instance is alive.
+type Elector struct {
+	rdb      *redis.Client
+	key      string
+	instance string
+
+	mu       sync.RWMutex
+	isLeader bool
+	elected  chan struct{}
+}
+
+// NewElector returns an elector for a role, e.g. "crawler". The instance is identified
+// by its hostname, which is the container ID under Docker, and a random suffix.
+func NewElector(rdb *redis.Client, role string) *Elector {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &Elector{
+		rdb:      rdb,
+		key:      "leader:" + role,
+		instance: fmt.Sprintf("%s-%s", hostname, store.NewID()[:6]),
+		elected:  make(chan struct{}, 1),
+	}
+}
+
+// Instance returns the identity of this instance.
+func (e *Elector) Instance() string {
+	return e.instance
+}
+
+// IsLeader reports whether this instance held the lease at its last renewal.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Elected receives a value each time this instance becomes the leader.
+func (e *Elector) Elected() <-chan struct{} {
+	return e.elected
+}
+
+// Run campaigns for the lease and renews it until ctx is done, then gives it up
+// so another replica can take over without waiting for the lease to expire.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns this instance's view of the leadership.
+func (e *Elector) Status(ctx context.Context) (Status, error) {
+	status := Status{Instance: e.instance, IsLeader: e.IsLeader()}
+
+	leader, err := e.rdb.Get(ctx, e.key).Result()
+	if err == redis.Nil {
+		return status, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	status.Leader = leader
+
+	ttl, err := e.rdb.PTTL(ctx, e.key).Result()
+	if err != nil {
+		return Status{}, err
+	}
+	if ttl > 0 {
+		status.LeaseExpiresAt = time.Now().Add(ttl).UTC()
+	}
+	return status, nil
+}
+
+// campaign renews the lease if this instance holds it, or tries to acquire it.
+func (e *Elector) campaign(ctx context.Context) {
+	var held bool
+	var err error
+	if e.IsLeader() {
+		var renewed int64
+		renewed, err = renewScript.Run(ctx, e.rdb, []string{e.key}, e.instance, leaseTTL.Milliseconds()).Int64()
+		held = renewed == 1
+	} else {
+		held, err = e.rdb.SetNX(ctx, e.key, e.instance, leaseTTL).Result()
+	}
+
+	if err != nil {
+		// Redis is unreachable: assume the worst so that two instances never crawl at once
+		log.Printf("Error renewing %s lease: %v", e.key, err)
+		held = false
+	}
+	e.setLeader(held)
+}
+
+// resign releases the lease if this instance holds it.
+func (e *Elector) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), renewInterval)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.rdb, []string{e.key}, e.instance).Err(); err != nil {
+		log.Printf("Error releasing %s lease: %v", e.key, err)
+	}
+	e.setLeader(false)
+}
+
+func (e *Elector) setLeader(isLeader bool) {
+	e.mu.Lock()
+	changed := e.isLeader != isLeader
+	e.isLeader = isLeader
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if isLeader {
+		log.Printf("Instance %s is now the leader for %s", e.instance, e.key)
+		select {
+		case e.elected <- struct{}{}:
+		default:
+		}
+	} else {
+		log.Printf("Instance %s is no longer the leader for %s", e.instance, e.key)
+	}
+}
@@ -0,0 +1,114 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return server, rdb
+}
+
+func TestCampaign(t *testing.T) {
+	ctx := context.Background()
+	server, rdb := newTestRedis(t)
+	first, second := NewElector(rdb, "crawler"), NewElector(rdb, "crawler")
+	require.NotEqual(t, first.Instance(), second.Instance())
+
+	// The first instance to campaign gets the lease
+	first.campaign(ctx)
+	second.campaign(ctx)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	select {
+	case <-first.Elected():
+	default:
+		t.Fatal("the leader was not notified")
+	}
+
+	status, err := second.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.Instance(), status.Leader)
+	assert.False(t, status.IsLeader)
+	assert.False(t, status.LeaseExpiresAt.IsZero())
+
+	// Renewals keep the lease past its original expiry
+	for i := 0; i < 4; i++ {
+		server.FastForward(renewInterval)
+		first.campaign(ctx)
+		second.campaign(ctx)
+	}
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	assert.Equal(t, leaseTTL, server.TTL("leader:crawler"))
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+	server, rdb := newTestRedis(t)
+	first, second := NewElector(rdb, "crawler"), NewElector(rdb, "crawler")
+
+	first.campaign(ctx)
+	second.campaign(ctx)
+	require.True(t, first.IsLeader())
+
+	// The leader stops renewing: the other instance takes over once the lease expires
+	server.FastForward(leaseTTL - time.Second)
+	second.campaign(ctx)
+	assert.False(t, second.IsLeader())
+	server.FastForward(time.Second)
+	second.campaign(ctx)
+	assert.True(t, second.IsLeader())
+
+	// The former leader finds out at its next renewal
+	first.campaign(ctx)
+	assert.False(t, first.IsLeader())
+	leader, err := rdb.Get(ctx, "leader:crawler").Result()
+	require.NoError(t, err)
+	assert.Equal(t, second.Instance(), leader)
+}
+
+func TestResign(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	first, second := NewElector(rdb, "crawler"), NewElector(rdb, "crawler")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		first.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-first.Elected():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the instance was not elected")
+	}
+
+	// Stopping gives the lease up right away
+	cancel()
+	<-done
+	assert.False(t, first.IsLeader())
+	status, err := first.Status(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, status.Leader)
+
+	second.campaign(context.Background())
+	assert.True(t, second.IsLeader())
+
+	// Resigning doesn't release a lease held by another instance
+	first.resign()
+	first.setLeader(true)
+	first.resign()
+	leader, err := rdb.Get(context.Background(), "leader:crawler").Result()
+	require.NoError(t, err)
+	assert.Equal(t, second.Instance(), leader)
+}
@@ -1,53 +1,77 @@
 package main
 
 import (
+	"FlightAPI/airlines"
+	"FlightAPI/airports"
+	"FlightAPI/alerts"
 	"FlightAPI/crawlers"
+	"FlightAPI/currency"
 	"FlightAPI/handlers"
+	"FlightAPI/leader"
+	"FlightAPI/models"
+	"FlightAPI/store"
 	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
+	"os"
 	"time"
 )
 
 func main() {
+	// The import command loads flights from a file instead of serving the API
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
 	ctx := context.Background()
+
+	// Load the bundled airport, airline and currency reference data before serving requests
+	log.Printf("Loaded %d airports", airports.Default().Len())
+	log.Printf("Loaded %d airlines", len(airlines.Default().All()))
+	log.Printf("Loaded %d currencies and %d exchange rates", len(currency.Default().Currencies()), len(currency.DefaultRates()))
+
 	// Initialize Redis client
 	// This should be moved to a config file or env var in production code
 	rdb := redis.NewClient(&redis.Options{Addr: "redis:6379", Password: "", DB: 0})
 
-	// Create timeout context with 5 min timeout.
-	// This context will be used to cancel the API call if it takes too long.
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+	// Only one replica runs the scheduled crawls. The others wait to take over if it dies.
+	elector := leader.NewElector(rdb, "crawler")
+	go elector.Run(ctx)
+	log.Printf("Running as instance %s", elector.Instance())
 
 	// Create ticker to trigger API calls every 30 minutes
 	// This shouldn't be hardcoded in production code. We should pull this from env vars or config files.
+	// Every run has its own timeout, so a slow provider doesn't stop the schedule.
 	ticker := time.NewTicker(30 * time.Minute)
 	defer ticker.Stop()
 
 	go func() {
-		// Trigger the first API call immediately
-		err := crawlers.CallMockyAPI(timeoutCtx, rdb)
-		if err != nil {
-			log.Printf("Error calling Mocky API on start: %v", err)
-		}
-
 		for {
 			select {
+			case <-elector.Elected():
+				// Crawl as soon as we lead, so a failover doesn't leave a gap in the data
+				crawlAll(ctx, rdb)
 			case <-ticker.C:
-				err := crawlers.CallMockyAPI(timeoutCtx, rdb)
-				if err != nil {
-					log.Printf("Error calling Mocky API: %v", err)
+				if elector.IsLeader() {
+					crawlAll(ctx, rdb)
 				}
-			case <-timeoutCtx.Done():
+			case <-ctx.Done():
 				log.Println("Context canceled, stopping ticker")
 				return
 			}
 		}
 	}()
 
+	// Give the seats of unconfirmed holds back once they expire. The sweep is atomic per
+	// hold, so every replica runs it.
+	go expireHolds(ctx, rdb, holdSweepInterval)
+
+	// Call the webhooks of the alerts matched by the crawls. Every replica takes from the
+	// same queue.
+	go alerts.Work(ctx, rdb, alertWorkers)
+
 	r := gin.Default()
 
 	r.GET("/", func(c *gin.Context) {
@@ -67,6 +91,15 @@ func main() {
 		})
 	})
 
+	// Calendar feeds, subscribed to from calendar apps. The token in the URL grants access,
+	// since calendar apps can't send a JWT.
+	feeds := r.Group("/calendar")
+	feeds.Use(func(c *gin.Context) {
+		c.Set("redisClient", rdb)
+		c.Next()
+	})
+	feeds.GET("/:token", handlers.GetCalendarFeed)
+
 	protected := r.Group("/api")
 	// Middleware to check JWT token
 	protected.Use(JWTAuthMiddleware())
@@ -83,13 +116,156 @@ func main() {
 	// Route to fetch all the dates where flights are available
 	protected.GET("/dates", handlers.GetDates)
 
-	// Route to fetch all flights from a date
-	protected.GET("/flights/:date", handlers.GetFlightsFromDate)
+	// Route to fetch all flights from a date.
+	// Gin requires every wildcard at this position to share a name, hence :id.
+	protected.GET("/flights/:id", handlers.GetFlightsFromDate)
+
+	// Route to fetch the price history of a flight (e.g. DL123-2025-04-28)
+	protected.GET("/flights/:id/prices", handlers.GetFlightPrices)
+
+	// Route to fetch a flight with every fare sold on it
+	protected.GET("/flights/:id/fares", handlers.GetFlightFares)
+
+	// Route to fetch the seats left per fare class of a flight
+	protected.GET("/flights/:id/inventory", handlers.GetFlightInventory)
+
+	// Route to fetch the status timeline of a flight
+	protected.GET("/flights/:id/history", handlers.GetFlightHistory)
 
 	protected.GET("/flights/search", handlers.GetFlightsBySearch)
 
+	// Server-Sent Events stream of flight changes detected by the crawler
+	protected.GET("/stream", handlers.StreamFlightEvents)
+
+	// WebSocket to subscribe to flights and routes at runtime
+	protected.GET("/ws", handlers.FlightWebSocket)
+
+	// Price-drop alerts, evaluated by the crawler after every run and delivered to webhooks
+	protected.POST("/alerts", handlers.CreateAlert)
+	protected.GET("/alerts", handlers.GetAlerts)
+	protected.DELETE("/alerts/:id", handlers.DeleteAlert)
+	protected.GET("/alerts/:id/deliveries", handlers.GetAlertDeliveries)
+
+	// Saved searches, stored per user and run again against the latest flights
+	protected.POST("/searches", handlers.CreateSavedSearch)
+	protected.GET("/searches", handlers.GetSavedSearches)
+	protected.DELETE("/searches/:id", handlers.DeleteSavedSearch)
+	protected.GET("/searches/:id/run", handlers.RunSavedSearch)
+
+	// Flight fares a user watches, with their status and price changes since they were added
+	protected.POST("/watchlist", handlers.WatchFlight)
+	protected.GET("/watchlist", handlers.GetWatchlist)
+	protected.DELETE("/watchlist/:id", handlers.UnwatchFlight)
+
+	// iCalendar (.ics) exports of flights, and the calendar feed URL of the current user
+	protected.GET("/calendar/flights/:id", handlers.GetFlightCalendar)
+	protected.GET("/calendar/search", handlers.GetSearchCalendar)
+	protected.GET("/calendar/watchlist", handlers.GetWatchlistCalendar)
+	protected.GET("/calendar/bookings/:locator", handlers.GetBookingCalendar)
+	protected.POST("/calendar/feed", handlers.CreateCalendarFeed)
+	protected.DELETE("/calendar/feed", handlers.DeleteCalendarFeed)
+
+	// Time-limited seat holds, taken from the inventory until confirmed, released or expired
+	protected.POST("/holds", handlers.CreateHold)
+	protected.GET("/holds", handlers.GetHolds)
+	protected.GET("/holds/:id", handlers.GetHold)
+	protected.POST("/holds/:id/confirm", handlers.ConfirmHold)
+	protected.POST("/holds/:id/release", handlers.ReleaseHold)
+
+	// Bookings of held seats for named passengers, identified by a six-character record locator
+	protected.POST("/bookings", handlers.CreateBooking)
+	protected.GET("/bookings", handlers.GetBookings)
+	protected.GET("/bookings/:locator", handlers.GetBooking)
+	protected.POST("/bookings/:locator/confirm", handlers.ConfirmBooking)
+	protected.POST("/bookings/:locator/cancel", handlers.CancelBooking)
+
+	// Airport reference data: lookup by IATA/ICAO code and autocomplete
+	protected.GET("/airports", handlers.SearchAirports)
+	protected.GET("/airports/nearby", handlers.GetNearbyAirports)
+	protected.GET("/airports/:code", handlers.GetAirport)
+
+	// Airline registry
+	protected.GET("/airlines", handlers.GetAirlines)
+	protected.GET("/airlines/:code", handlers.GetAirline)
+
+	// Admin routes to audit the crawlers
+	admin := protected.Group("/admin")
+	admin.Use(AdminOnlyMiddleware())
+	admin.Use(func(c *gin.Context) {
+		c.Set("elector", elector)
+		c.Next()
+	})
+	// Which replica leads the scheduled crawls
+	admin.GET("/status", handlers.GetLeaderStatus)
+	admin.GET("/crawls", handlers.GetCrawlRuns)
+	admin.POST("/crawls", handlers.TriggerCrawl)
+	admin.GET("/crawls/:id", handlers.GetCrawlRun)
+	admin.GET("/crawls/:id/diff", handlers.GetCrawlDiff)
+
+	// Records rejected while crawling, replayable once the parser or mapping is fixed
+	admin.GET("/deadletters", handlers.GetDeadLetters)
+	admin.POST("/deadletters/replay", handlers.ReplayDeadLetters)
+	admin.GET("/deadletters/:id", handlers.GetDeadLetter)
+	admin.POST("/deadletters/:id/replay", handlers.ReplayDeadLetter)
+	admin.DELETE("/deadletters/:id", handlers.DeleteDeadLetter)
+
+	// Data-quality rules applied to ingested flights
+	admin.GET("/validation/rules", handlers.GetValidationRules)
+	admin.PUT("/validation/rules/:name", handlers.UpdateValidationRule)
+
+	// Import flights from CSV, JSON and NDJSON files
+	admin.POST("/imports", handlers.ImportFlights)
+
+	// Exchange rates used to price non-USD fares and convert prices with ?currency=
+	admin.GET("/rates", handlers.GetExchangeRates)
+	admin.POST("/rates", handlers.LoadExchangeRates)
+
+	// Seats for sale per fare class, which holds are taken from
+	admin.PUT("/flights/:id/inventory", handlers.SetFlightInventory)
+
 	err := r.Run(":8080")
 	if err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
+
+// alertWorkers is how many alert webhooks each replica calls at once.
+const alertWorkers = 4
+
+// holdSweepInterval is how often expired holds are looked for. Confirming an expired hold
+// fails even between two sweeps.
+const holdSweepInterval = 30 * time.Second
+
+// expireHolds gives the seats of expired holds back to the inventory until ctx is done.
+func expireHolds(ctx context.Context, rdb *redis.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			expired, err := store.ExpireHolds(ctx, rdb, time.Now())
+			if err != nil {
+				log.Printf("Error expiring holds: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("Expired %d holds", expired)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// crawlAll runs a scheduled crawl of every provider, one after the other.
+func crawlAll(ctx context.Context, rdb *redis.Client) {
+	for _, provider := range crawlers.Providers() {
+		run, err := crawlers.Run(ctx, rdb, provider, crawlers.Options{Trigger: models.TriggerSchedule})
+		if err != nil {
+			log.Printf("Error starting scheduled crawl of %s: %v", provider, err)
+			continue
+		}
+		if run.Status == models.CrawlFailed {
+			log.Printf("Scheduled crawl %s of %s failed: %s", run.ID, provider, run.Error)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -171,3 +173,71 @@ func TestProtectedRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminOnlyRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	admin := r.Group("/admin")
+	admin.Use(JWTAuthMiddleware(), AdminOnlyMiddleware())
+	admin.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "Hello admin!"})
+	})
+
+	tokenFor := func(username string) string {
+		claims := &jwt.RegisteredClaims{Subject: username, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKey)
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		username       string
+		expectedStatus int
+	}{
+		{name: "admin", username: "admin", expectedStatus: http.StatusOK},
+		{name: "other user", username: "alice", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/admin/", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenFor(tt.username))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+		})
+	}
+}
+
+func TestWebSocketToken(t *testing.T) {
+	router := setupRouter()
+	claims := &jwt.RegisteredClaims{Subject: "admin", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKey)
+
+	tests := []struct {
+		name           string
+		url            string
+		protocols      string
+		expectedStatus int
+	}{
+		{name: "token subprotocol", url: "/secret/", protocols: "flightapi, bearer." + token, expectedStatus: http.StatusOK},
+		{name: "no token subprotocol", url: "/secret/", protocols: "flightapi", expectedStatus: http.StatusUnauthorized},
+		// Tokens in the URL would end up in the request logs
+		{name: "query parameter", url: "/secret/?access_token=" + token, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			req.Header.Set("Upgrade", "websocket")
+			if tt.protocols != "" {
+				req.Header.Set("Sec-WebSocket-Protocol", tt.protocols)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+		})
+	}
+}
@@ -1,23 +1,23 @@
 package main
 
 import (
+	"FlightAPI/handlers"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
 )
 
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		tokenString, ok := tokenFromRequest(c)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, jwt.ErrSignatureInvalid
@@ -30,6 +30,49 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Expose the token subject so handlers can scope data to the user
+		subject, err := token.Claims.GetSubject()
+		if err != nil || subject == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		c.Set("username", subject)
+
+		c.Next()
+	}
+}
+
+// tokenFromRequest reads the bearer token from the Authorization header.
+// Browsers can't set headers on WebSocket handshakes, so upgrade requests may
+// pass the token as a Sec-WebSocket-Protocol instead. It stays out of the URL,
+// which ends up in request logs.
+func tokenFromRequest(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), true
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		for _, protocol := range websocket.Subprotocols(c.Request) {
+			if token := strings.TrimPrefix(protocol, handlers.WebSocketTokenPrefix); token != protocol && token != "" {
+				return token, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// adminUsername is the only user allowed on the admin routes until users get roles.
+const adminUsername = "admin"
+
+// AdminOnlyMiddleware rejects users other than the admin. It must run after JWTAuthMiddleware.
+func AdminOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("username") != adminUsername {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,9 @@
+package models
+
+type Airline struct {
+	Code     string `json:"code"` // IATA designator, e.g. DL
+	ICAO     string `json:"icao"`
+	Name     string `json:"name"`
+	Alliance string `json:"alliance,omitempty"`
+	Country  string `json:"country"`
+}
@@ -1,8 +1,12 @@
 package models
 
 type Airport struct {
-	Code    string `json:"code"`
-	Name    string `json:"name"`
-	City    string `json:"city"`
-	Country string `json:"country"`
+	Code      string  `json:"code"`
+	ICAO      string  `json:"icao,omitempty"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Timezone  string  `json:"timezone,omitempty"` // IANA zone name, e.g. America/New_York
 }
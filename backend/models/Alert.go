@@ -0,0 +1,46 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"time"
+)
+
+// Alert notifies a webhook when a fare on a route gets cheaper than a target price
+// or drops by a percentage from the first price we saw for it.
+type Alert struct {
+	ID             string     `json:"id"`
+	Owner          string     `json:"owner"` // Subject of the token that created the alert
+	Name           string     `json:"name,omitempty"`
+	Origin         string     `json:"origin"`             // Airport or metro code
+	Destination    string     `json:"destination"`        // Airport or metro code
+	DateFrom       string     `json:"dateFrom,omitempty"` // First departure date, YYYY-MM-DD, inclusive
+	DateTo         string     `json:"dateTo,omitempty"`   // Last departure date, YYYY-MM-DD, inclusive
+	Class          CabinClass `json:"class,omitempty"`
+	TargetPriceUSD money.USD  `json:"targetPriceUSD,omitempty"`
+	DropPercent    float64    `json:"dropPercent,omitempty"`
+	WebhookURL     string     `json:"webhookUrl"`
+	WebhookSecret  string     `json:"webhookSecret,omitempty"` // Only returned when the alert is created
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// AlertDelivery records one attempt to deliver an alert match to its webhook, including retries.
+type AlertDelivery struct {
+	ID         string     `json:"id"`
+	AlertID    string     `json:"alertId"`
+	FlightID   string     `json:"flightId"`
+	Class      CabinClass `json:"class"`
+	PriceUSD   money.USD  `json:"priceUSD"`
+	Attempts   int        `json:"attempts"`
+	StatusCode int        `json:"statusCode,omitempty"` // Status of the last attempt
+	Success    bool       `json:"success"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// AlertMatch is a fare that triggered an alert, queued until its webhook is called.
+type AlertMatch struct {
+	AlertID       string    `json:"alertId"`
+	Flight        Flight    `json:"flight"`
+	FirstPriceUSD money.USD `json:"firstPriceUSD"` // First price recorded for the fare, for drop alerts
+	MatchedAt     time.Time `json:"matchedAt"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// BookingStatus is a step of the booking lifecycle.
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "pending"   // Seats are held until the holds expire
+	BookingConfirmed BookingStatus = "confirmed" // Seats are sold
+	BookingCancelled BookingStatus = "cancelled" // Seats went back to the inventory
+)
+
+// Passenger is a traveller of a booking, named as on their travel document.
+type Passenger struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+// Contact is how the airline reaches the customer about a booking.
+type Contact struct {
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Segment is one flight of a booking, sold through a seat hold.
+type Segment struct {
+	HoldID           string     `json:"holdId"`
+	FlightID         string     `json:"flightId"`
+	FlightNumber     string     `json:"flightNumber,omitempty"`
+	DepartureAirport string     `json:"departureAirport,omitempty"`
+	ArrivalAirport   string     `json:"arrivalAirport,omitempty"`
+	DepartureTime    string     `json:"departureTime,omitempty"`
+	ArrivalTime      string     `json:"arrivalTime,omitempty"`
+	Class            CabinClass `json:"class"`
+	Seats            int        `json:"seats"`
+}
+
+// Booking is a reservation of seats on one or more flights for named passengers,
+// identified by a six-character record locator such as K7QM2X.
+type Booking struct {
+	Locator     string        `json:"locator"`
+	Owner       string        `json:"owner"`
+	Status      BookingStatus `json:"status"`
+	Passengers  []Passenger   `json:"passengers"`
+	Contact     Contact       `json:"contact"`
+	Segments    []Segment     `json:"segments"` // In departure order
+	CreatedAt   time.Time     `json:"createdAt"`
+	ExpiresAt   time.Time     `json:"expiresAt"` // When the first hold of a pending booking expires
+	ConfirmedAt *time.Time    `json:"confirmedAt,omitempty"`
+	CancelledAt *time.Time    `json:"cancelledAt,omitempty"`
+}
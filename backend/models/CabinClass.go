@@ -0,0 +1,72 @@
+package models
+
+import "strings"
+
+// CabinClass is the cabin a fare is sold in.
+type CabinClass string
+
+const (
+	CabinEconomy        CabinClass = "Economy"
+	CabinPremiumEconomy CabinClass = "Premium Economy"
+	CabinBusiness       CabinClass = "Business"
+	CabinFirst          CabinClass = "First"
+)
+
+// cabinAliases maps the spellings providers use to our cabin classes.
+var cabinAliases = map[string]CabinClass{
+	"economy":               CabinEconomy,
+	"economy class":         CabinEconomy,
+	"coach":                 CabinEconomy,
+	"main cabin":            CabinEconomy,
+	"y":                     CabinEconomy,
+	"premium economy":       CabinPremiumEconomy,
+	"premium economy class": CabinPremiumEconomy,
+	"premium":               CabinPremiumEconomy,
+	"economy plus":          CabinPremiumEconomy,
+	"w":                     CabinPremiumEconomy,
+	"business":              CabinBusiness,
+	"business class":        CabinBusiness,
+	"j":                     CabinBusiness,
+	"c":                     CabinBusiness,
+	"first":                 CabinFirst,
+	"first class":           CabinFirst,
+	"f":                     CabinFirst,
+}
+
+// ParseCabinClass normalizes a provider cabin such as "ECONOMY", "premium_economy" or "J".
+func ParseCabinClass(raw string) (CabinClass, bool) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	key = strings.NewReplacer("-", " ", "_", " ").Replace(key)
+	class, ok := cabinAliases[key]
+	return class, ok
+}
+
+// rank orders the cabins from economy to first. Unknown cabins come last.
+func (c CabinClass) rank() int {
+	switch c {
+	case CabinEconomy:
+		return 0
+	case CabinPremiumEconomy:
+		return 1
+	case CabinBusiness:
+		return 2
+	case CabinFirst:
+		return 3
+	}
+	return 4
+}
+
+// Baggage is the checked baggage included in a fare.
+type Baggage struct {
+	CheckedBags     int `json:"checkedBags"`               // Number of checked bags, 0 for hand baggage only
+	CheckedWeightKg int `json:"checkedWeightKg,omitempty"` // Weight allowance per bag, when the carrier has one
+}
+
+// FareRules are the change and refund conditions of a fare. Fees are in minor units of
+// the fare currency.
+type FareRules struct {
+	Refundable bool   `json:"refundable"`
+	RefundFee  *int64 `json:"refundFee,omitempty"` // Deducted from the refund, when refundable
+	Changeable bool   `json:"changeable"`
+	ChangeFee  *int64 `json:"changeFee,omitempty"` // Charged on top of the fare difference, when changeable
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCabinClass(t *testing.T) {
+	tests := []struct {
+		raw         string
+		expected    CabinClass
+		expectFound bool
+	}{
+		{raw: "Economy", expected: CabinEconomy, expectFound: true},
+		{raw: "COACH", expected: CabinEconomy, expectFound: true},
+		{raw: "premium_economy", expected: CabinPremiumEconomy, expectFound: true},
+		{raw: " Business Class ", expected: CabinBusiness, expectFound: true},
+		{raw: "J", expected: CabinBusiness, expectFound: true},
+		{raw: "first-class", expected: CabinFirst, expectFound: true},
+		{raw: "sleeper", expectFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			class, ok := ParseCabinClass(tt.raw)
+			assert.Equal(t, tt.expectFound, ok)
+			assert.Equal(t, tt.expected, class)
+		})
+	}
+}
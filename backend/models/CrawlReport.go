@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// CrawlReport records what a crawl run changed compared to the previous run of the same provider.
+type CrawlReport struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	PreviousID string    `json:"previousId,omitempty"` // Empty for the first crawl of a provider
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Fares      int       `json:"fares"` // Fares returned by the provider during this run
+	Summary    DiffCount `json:"summary"`
+	Diff       CrawlDiff `json:"diff"`
+}
+
+// CrawlDiff lists the fares that changed between two consecutive crawls.
+// A fare can appear in several of the changed lists.
+type CrawlDiff struct {
+	Added           []FareChange `json:"added"`
+	Removed         []FareChange `json:"removed"`
+	PriceChanged    []FareChange `json:"priceChanged"`
+	StatusChanged   []FareChange `json:"statusChanged"`
+	ScheduleChanged []FareChange `json:"scheduleChanged"`
+}
+
+// DiffCount is the number of entries in each list of a CrawlDiff.
+type DiffCount struct {
+	Added           int `json:"added"`
+	Removed         int `json:"removed"`
+	PriceChanged    int `json:"priceChanged"`
+	StatusChanged   int `json:"statusChanged"`
+	ScheduleChanged int `json:"scheduleChanged"`
+}
+
+// FareChange is one fare in a CrawlDiff. Before is nil for added fares, After for removed ones.
+type FareChange struct {
+	FlightID string     `json:"flightId"`
+	Class    CabinClass `json:"class"`
+	Before   *Flight    `json:"before,omitempty"`
+	After    *Flight    `json:"after,omitempty"`
+}
+
+// Count returns the number of entries in each list of the diff.
+func (d CrawlDiff) Count() DiffCount {
+	return DiffCount{
+		Added:           len(d.Added),
+		Removed:         len(d.Removed),
+		PriceChanged:    len(d.PriceChanged),
+		StatusChanged:   len(d.StatusChanged),
+		ScheduleChanged: len(d.ScheduleChanged),
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// CrawlRunStatus is the outcome of a crawl run.
+type CrawlRunStatus string
+
+const (
+	CrawlRunning   CrawlRunStatus = "running"
+	CrawlSucceeded CrawlRunStatus = "succeeded"
+	CrawlUnchanged CrawlRunStatus = "unchanged" // The provider sent the same data as the last run
+	CrawlFailed    CrawlRunStatus = "failed"
+)
+
+// Triggers of a crawl run.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerImport   = "import"
+)
+
+// CrawlRun is one execution of a crawler. Every record read from the provider is
+// either inserted, updated or rejected.
+type CrawlRun struct {
+	ID          string         `json:"id"` // Also the ID of the crawl report
+	Provider    string         `json:"provider"`
+	Status      CrawlRunStatus `json:"status"`
+	Trigger     string         `json:"trigger"`
+	TriggeredBy string         `json:"triggeredBy,omitempty"` // User who started a manual run
+	Forced      bool           `json:"forced,omitempty"`      // Fetched and parsed even if unchanged
+	Source      string         `json:"source,omitempty"`      // File name of an import
+	StartedAt   time.Time      `json:"startedAt"`
+	FinishedAt  *time.Time     `json:"finishedAt,omitempty"`
+	Read        int            `json:"read"`
+	Inserted    int            `json:"inserted"` // Fares stored for the first time
+	Updated     int            `json:"updated"`  // Fares that were already stored
+	Rejected    int            `json:"rejected"`
+	Warned      int            `json:"warned"`               // Fares stored with data-quality warnings
+	Violations  map[string]int `json:"violations,omitempty"` // Records breaking each validation rule
+	Error       string         `json:"error,omitempty"`
+	Checksum    string         `json:"checksum,omitempty"` // SHA-256 of the payload
+}
+
+// PayloadValidators identify the last payload fetched from a provider, so the next
+// crawl can skip it if it didn't change.
+type PayloadValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Currency is an ISO 4217 currency.
+type Currency struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	MinorUnits int    `json:"minorUnits"` // Digits after the decimal point, e.g. 2 for USD, 0 for JPY
+}
+
+// ExchangeRate is the value of one US dollar in a currency from EffectiveDate until
+// the next rate of the same currency.
+type ExchangeRate struct {
+	Currency      string  `json:"currency"`
+	EffectiveDate string  `json:"effectiveDate"` // YYYY-MM-DD, in UTC
+	PerUSD        float64 `json:"perUSD"`
+}
+
+// Conversion states the rate a price was converted with.
+type Conversion struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Rate          float64 `json:"rate"`          // Units of To for one unit of From
+	EffectiveDate string  `json:"effectiveDate"` // Date of the most recent exchange rate used
+	Amount        int64   `json:"amount"`        // Original amount in minor units of From
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a provider record rejected while crawling, kept so it can be inspected
+// and replayed once the parser or the mapping is fixed.
+type DeadLetter struct {
+	ID         string          `json:"id"`
+	Provider   string          `json:"provider"`
+	CrawlID    string          `json:"crawlId"`
+	Reason     string          `json:"reason"`
+	Raw        json.RawMessage `json:"raw"` // The record exactly as the provider sent it
+	CreatedAt  time.Time       `json:"createdAt"`
+	Replays    int             `json:"replays"`
+	LastReplay *time.Time      `json:"lastReplay,omitempty"`
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Fare is one offer sold on a flight: its cabin, price, conditions and availability.
+type Fare struct {
+	Class          CabinClass       `json:"class"`
+	FareBasis      string           `json:"fareBasis,omitempty"`
+	Baggage        *Baggage         `json:"baggage,omitempty"`
+	FareRules      *FareRules       `json:"fareRules,omitempty"`
+	SeatsRemaining *int             `json:"seatsRemaining,omitempty"`
+	PriceUSD       money.USD        `json:"priceUSD"`
+	Price          money.Money      `json:"-"`                      // Written to JSON as priceAmount and currency
+	ExchangeRate   *Conversion      `json:"exchangeRate,omitempty"` // Set when the price was converted for the response
+	Warnings       []QualityWarning `json:"warnings,omitempty"`
+}
+
+// MarshalJSON writes the price of the fare as priceAmount, in minor units, and currency.
+func (f Fare) MarshalJSON() ([]byte, error) {
+	type plain Fare
+	return json.Marshal(struct {
+		plain
+		PriceAmount int64  `json:"priceAmount,omitempty"`
+		Currency    string `json:"currency,omitempty"`
+	}{plain(f), f.Price.Amount, f.Price.Currency})
+}
+
+// UnmarshalJSON reads the price of the fare from priceAmount and currency, in US dollars
+// when there is no priceAmount.
+func (f *Fare) UnmarshalJSON(data []byte) error {
+	type plain Fare
+	decoded := struct {
+		*plain
+		PriceAmount *int64 `json:"priceAmount"`
+		Currency    string `json:"currency"`
+	}{plain: (*plain)(f)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	switch {
+	case decoded.PriceAmount != nil:
+		f.Price = money.New(*decoded.PriceAmount, decoded.Currency)
+	case decoded.Currency == "" || strings.EqualFold(decoded.Currency, "USD"):
+		f.Price = f.PriceUSD.Money()
+	default:
+		f.Price = money.New(0, decoded.Currency)
+	}
+	return nil
+}
+
+// FlightFares is a flight with its schedule, status and aircraft, and the fares sold on it.
+// Providers send one record per fare; the flight part is shared by all of them.
+type FlightFares struct {
+	ID               string       `json:"id"`
+	FlightNumber     string       `json:"flightNumber"`
+	CarrierCode      string       `json:"carrierCode,omitempty"`
+	Number           string       `json:"number,omitempty"`
+	Airline          string       `json:"airline"`
+	DepartureAirport Airport      `json:"departureAirport"`
+	ArrivalAirport   Airport      `json:"arrivalAirport"`
+	DepartureTime    string       `json:"departureTime"`
+	ArrivalTime      string       `json:"arrivalTime"`
+	Duration         string       `json:"duration"`
+	Aircraft         string       `json:"aircraft,omitempty"`
+	Status           FlightStatus `json:"status"`
+	Fares            []Fare       `json:"fares,omitempty"` // Economy first
+}
+
+// Fare returns the fare part of a flight record.
+func (f Flight) Fare() Fare {
+	return Fare{
+		Class:          f.Class,
+		FareBasis:      f.FareBasis,
+		Baggage:        f.Baggage,
+		FareRules:      f.FareRules,
+		SeatsRemaining: f.SeatsRemaining,
+		PriceUSD:       f.PriceUSD,
+		Price:          f.Price,
+		ExchangeRate:   f.ExchangeRate,
+		Warnings:       f.Warnings,
+	}
+}
+
+// FlightOf returns the flight part of a flight record, without fares.
+func FlightOf(flight Flight) FlightFares {
+	id := flight.ID
+	if id == "" {
+		id = FlightID(flight)
+	}
+	return FlightFares{
+		ID:               id,
+		FlightNumber:     flight.FlightNumber,
+		CarrierCode:      flight.CarrierCode,
+		Number:           flight.Number,
+		Airline:          flight.Airline,
+		DepartureAirport: flight.DepartureAirport,
+		ArrivalAirport:   flight.ArrivalAirport,
+		DepartureTime:    flight.DepartureTime,
+		ArrivalTime:      flight.ArrivalTime,
+		Duration:         flight.Duration,
+		Aircraft:         flight.Aircraft,
+		Status:           flight.Status,
+	}
+}
+
+// ApplyTo replaces the schedule, status and aircraft of a fare record with those of the
+// flight, so every fare shows the flight as it was last updated.
+func (f FlightFares) ApplyTo(flight *Flight) {
+	flight.ID = f.ID
+	flight.FlightNumber = f.FlightNumber
+	flight.CarrierCode = f.CarrierCode
+	flight.Number = f.Number
+	flight.Airline = f.Airline
+	flight.DepartureAirport = f.DepartureAirport
+	flight.ArrivalAirport = f.ArrivalAirport
+	flight.DepartureTime = f.DepartureTime
+	flight.ArrivalTime = f.ArrivalTime
+	flight.Duration = f.Duration
+	flight.Aircraft = f.Aircraft
+	flight.Status = f.Status
+}
+
+// Flights returns one flight record per fare.
+func (f FlightFares) Flights() []Flight {
+	flights := make([]Flight, 0, len(f.Fares))
+	for _, fare := range f.Fares {
+		flight := Flight{
+			Class:          fare.Class,
+			FareBasis:      fare.FareBasis,
+			Baggage:        fare.Baggage,
+			FareRules:      fare.FareRules,
+			SeatsRemaining: fare.SeatsRemaining,
+			PriceUSD:       fare.PriceUSD,
+			Price:          fare.Price,
+			ExchangeRate:   fare.ExchangeRate,
+			Warnings:       fare.Warnings,
+		}
+		f.ApplyTo(&flight)
+		flights = append(flights, flight)
+	}
+	return flights
+}
+
+// SortFares orders the fares by cabin, economy first, then by price.
+func (f *FlightFares) SortFares() {
+	sort.SliceStable(f.Fares, func(i, j int) bool {
+		a, b := f.Fares[i], f.Fares[j]
+		if a.Class.rank() != b.Class.rank() {
+			return a.Class.rank() < b.Class.rank()
+		}
+		return a.PriceUSD < b.PriceUSD
+	})
+}
+
+// GroupFares groups fare records by flight, in the order the flights first appear.
+// The flight part comes from the first record of each flight, so records should be given
+// newest first; later records of a fare class already seen are dropped.
+func GroupFares(flights []Flight) []FlightFares {
+	var grouped []FlightFares
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, flight := range flights {
+		part := FlightOf(flight)
+		i, ok := index[part.ID]
+		if !ok {
+			i = len(grouped)
+			index[part.ID] = i
+			grouped = append(grouped, part)
+		}
+
+		fare := part.ID + "|" + string(flight.Class)
+		if seen[fare] {
+			continue
+		}
+		seen[fare] = true
+		grouped[i].Fares = append(grouped[i].Fares, flight.Fare())
+	}
+
+	for i := range grouped {
+		grouped[i].SortFares()
+	}
+	return grouped
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupFares(t *testing.T) {
+	fare := func(number string, class CabinClass, cents int64, status FlightStatus) Flight {
+		flight := Flight{FlightNumber: number, DepartureTime: "2025-04-28T10:00:00Z", Class: class, Status: status, PriceUSD: money.USD(cents), Price: money.New(cents, "USD")}
+		flight.ID = FlightID(flight)
+		return flight
+	}
+
+	// Newest first, as in the flight lists
+	grouped := GroupFares([]Flight{
+		fare("DL123", CabinBusiness, 90000, StatusDelayed),
+		fare("BA117", CabinEconomy, 45000, StatusScheduled),
+		fare("DL123", CabinEconomy, 30000, StatusScheduled),
+		fare("DL123", CabinBusiness, 95000, StatusScheduled),
+	})
+
+	require.Len(t, grouped, 2)
+	assert.Equal(t, "DL123-2025-04-28", grouped[0].ID)
+	assert.Equal(t, StatusDelayed, grouped[0].Status)
+	if assert.Len(t, grouped[0].Fares, 2) {
+		assert.Equal(t, CabinEconomy, grouped[0].Fares[0].Class)
+		assert.Equal(t, CabinBusiness, grouped[0].Fares[1].Class)
+		assert.Equal(t, money.USD(90000), grouped[0].Fares[1].PriceUSD)
+	}
+	assert.Equal(t, "BA117-2025-04-28", grouped[1].ID)
+
+	// Every fare of the flight gets its status back
+	flights := grouped[0].Flights()
+	if assert.Len(t, flights, 2) {
+		for _, flight := range flights {
+			assert.Equal(t, StatusDelayed, flight.Status)
+			assert.Equal(t, "DL123-2025-04-28", flight.ID)
+		}
+		assert.Equal(t, money.New(30000, "USD"), flights[0].Price)
+	}
+}
+
+func TestFareJSON(t *testing.T) {
+	seats := 4
+	fare := Fare{Class: CabinEconomy, SeatsRemaining: &seats, PriceUSD: 46802, Price: money.New(45000, "EUR")}
+
+	data, err := json.Marshal(fare)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"class":"Economy","seatsRemaining":4,"priceUSD":468.02,"priceAmount":45000,"currency":"EUR"}`, string(data))
+
+	var decoded Fare
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, fare, decoded)
+}
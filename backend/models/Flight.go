@@ -1,14 +1,85 @@
 package models
 
+import (
+	"FlightAPI/money"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
 type Flight struct {
-	FlightNumber     string  `json:"flightNumber"`
-	Airline          string  `json:"airline"`
-	DepartureAirport Airport `json:"departureAirport"`
-	ArrivalAirport   Airport `json:"arrivalAirport"`
-	DepartureTime    string  `json:"departureTime"` // You can use time.Time if you want to parse it
-	ArrivalTime      string  `json:"arrivalTime"`   // Same here
-	Class            string  `json:"class"`
-	Status           string  `json:"status"`
-	Duration         string  `json:"duration"`
-	PriceUSD         float64 `json:"priceUSD"`
+	ID               string           `json:"id,omitempty"` // Stable identity across crawls, see FlightID
+	FlightNumber     string           `json:"flightNumber"`
+	CarrierCode      string           `json:"carrierCode,omitempty"` // IATA airline designator split from the flight number
+	Number           string           `json:"number,omitempty"`      // Numeric part of the flight number, without leading zeros
+	Airline          string           `json:"airline"`
+	DepartureAirport Airport          `json:"departureAirport"`
+	ArrivalAirport   Airport          `json:"arrivalAirport"`
+	DepartureTime    string           `json:"departureTime"` // You can use time.Time if you want to parse it
+	ArrivalTime      string           `json:"arrivalTime"`   // Same here
+	Class            CabinClass       `json:"class"`
+	FareBasis        string           `json:"fareBasis,omitempty"`      // Fare basis code, e.g. YLOWUS
+	Baggage          *Baggage         `json:"baggage,omitempty"`        // Nil when the provider does not say
+	FareRules        *FareRules       `json:"fareRules,omitempty"`      // Nil when the provider does not say
+	SeatsRemaining   *int             `json:"seatsRemaining,omitempty"` // Seats left at this fare, when the provider says
+	Status           FlightStatus     `json:"status"`
+	Duration         string           `json:"duration"`
+	Aircraft         string           `json:"aircraft,omitempty"`     // IATA aircraft type code, e.g. 789
+	PriceUSD         money.USD        `json:"priceUSD"`               // Price converted to US dollars at the rate of the crawl day
+	Price            money.Money      `json:"-"`                      // Written to JSON as priceAmount and currency
+	ExchangeRate     *Conversion      `json:"exchangeRate,omitempty"` // Set when the price was converted for the response
+	Warnings         []QualityWarning `json:"warnings,omitempty"`     // Data-quality rules the flight was stored in spite of
+}
+
+// QualityWarning is a data-quality rule a flight broke without being rejected.
+type QualityWarning struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// MarshalJSON writes the price of the fare as priceAmount, in minor units, and currency.
+func (f Flight) MarshalJSON() ([]byte, error) {
+	type plain Flight
+	return json.Marshal(struct {
+		plain
+		PriceAmount int64  `json:"priceAmount,omitempty"`
+		Currency    string `json:"currency,omitempty"`
+	}{plain(f), f.Price.Amount, f.Price.Currency})
+}
+
+// UnmarshalJSON reads the price of the fare from priceAmount and currency. Flights without
+// a priceAmount, stored before prices had one or sent by providers that only know priceUSD,
+// are priced in US dollars.
+func (f *Flight) UnmarshalJSON(data []byte) error {
+	type plain Flight
+	decoded := struct {
+		*plain
+		PriceAmount *int64 `json:"priceAmount"`
+		Currency    string `json:"currency"`
+	}{plain: (*plain)(f)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	switch {
+	case decoded.PriceAmount != nil:
+		f.Price = money.New(*decoded.PriceAmount, decoded.Currency)
+	case decoded.Currency == "" || strings.EqualFold(decoded.Currency, "USD"):
+		f.Price = f.PriceUSD.Money()
+	default:
+		f.Price = money.New(0, decoded.Currency)
+	}
+	return nil
+}
+
+// FlightID builds the identity of a flight across crawls from its flight number and
+// local departure date, e.g. DL123-2025-04-28. Fares of every class share the same ID.
+func FlightID(flight Flight) string {
+	date := flight.DepartureTime
+	if t, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		date = t.Format("2006-01-02")
+	} else if len(date) > 10 {
+		date = date[:10]
+	}
+	return strings.ToUpper(strings.ReplaceAll(flight.FlightNumber, " ", "")) + "-" + date
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"time"
+)
+
+// Types of FlightEvent.
+const (
+	EventFlightCreated       = "flight.created"
+	EventFlightUpdated       = "flight.updated"
+	EventFlightStatusChanged = "flight.status_changed"
+	EventFlightPriceChanged  = "flight.price_changed"
+)
+
+// FlightEvent is a change to a flight detected while crawling.
+type FlightEvent struct {
+	ID               string       `json:"id"` // Redis stream entry ID, used for Last-Event-ID resume
+	Type             string       `json:"type"`
+	FlightID         string       `json:"flightId"`
+	Flight           Flight       `json:"flight"`
+	PreviousStatus   FlightStatus `json:"previousStatus,omitempty"`
+	PreviousPriceUSD money.USD    `json:"previousPriceUSD,omitempty"`
+	OccurredAt       time.Time    `json:"occurredAt"`
+}
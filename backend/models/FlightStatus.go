@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// FlightStatus is a step of the flight lifecycle.
+type FlightStatus string
+
+const (
+	StatusScheduled FlightStatus = "Scheduled"
+	StatusDelayed   FlightStatus = "Delayed"
+	StatusBoarding  FlightStatus = "Boarding"
+	StatusDeparted  FlightStatus = "Departed"
+	StatusLanded    FlightStatus = "Landed"
+	StatusCancelled FlightStatus = "Cancelled"
+	StatusDiverted  FlightStatus = "Diverted"
+)
+
+// statusTransitions lists the statuses each status may move to.
+// Landed and Cancelled are final.
+var statusTransitions = map[FlightStatus][]FlightStatus{
+	StatusScheduled: {StatusDelayed, StatusBoarding, StatusDeparted, StatusCancelled},
+	StatusDelayed:   {StatusScheduled, StatusBoarding, StatusDeparted, StatusCancelled},
+	StatusBoarding:  {StatusDelayed, StatusDeparted, StatusCancelled},
+	StatusDeparted:  {StatusLanded, StatusDiverted},
+	StatusDiverted:  {StatusLanded},
+	StatusLanded:    {},
+	StatusCancelled: {},
+}
+
+// statusAliases maps the spellings providers use to our statuses.
+var statusAliases = map[string]FlightStatus{
+	"scheduled": StatusScheduled,
+	"on time":   StatusScheduled,
+	"ontime":    StatusScheduled,
+	"delayed":   StatusDelayed,
+	"boarding":  StatusBoarding,
+	"departed":  StatusDeparted,
+	"in air":    StatusDeparted,
+	"airborne":  StatusDeparted,
+	"en route":  StatusDeparted,
+	"landed":    StatusLanded,
+	"arrived":   StatusLanded,
+	"cancelled": StatusCancelled,
+	"canceled":  StatusCancelled,
+	"diverted":  StatusDiverted,
+}
+
+// ParseFlightStatus normalizes a provider status such as "on-time" or "CANCELED".
+func ParseFlightStatus(raw string) (FlightStatus, bool) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	key = strings.NewReplacer("-", " ", "_", " ").Replace(key)
+	status, ok := statusAliases[key]
+	return status, ok
+}
+
+// CanTransition reports whether a flight may move from one status to another.
+// Staying in the same status is always allowed.
+func CanTransition(from, to FlightStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange is one entry of a flight status timeline.
+type StatusChange struct {
+	From      FlightStatus `json:"from,omitempty"` // Empty for the first status seen
+	To        FlightStatus `json:"to"`
+	ChangedAt time.Time    `json:"changedAt"`
+	Source    string       `json:"source"` // Provider that reported the change
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlightStatus(t *testing.T) {
+	tests := []struct {
+		raw         string
+		expected    FlightStatus
+		expectFound bool
+	}{
+		{raw: "Scheduled", expected: StatusScheduled, expectFound: true},
+		{raw: "on-time", expected: StatusScheduled, expectFound: true},
+		{raw: "CANCELED", expected: StatusCancelled, expectFound: true},
+		{raw: " en_route ", expected: StatusDeparted, expectFound: true},
+		{raw: "teleported", expectFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			status, ok := ParseFlightStatus(tt.raw)
+			assert.Equal(t, tt.expectFound, ok)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     FlightStatus
+		to       FlightStatus
+		expected bool
+	}{
+		{from: StatusScheduled, to: StatusDelayed, expected: true},
+		{from: StatusDelayed, to: StatusScheduled, expected: true},
+		{from: StatusBoarding, to: StatusDeparted, expected: true},
+		{from: StatusDeparted, to: StatusDiverted, expected: true},
+		{from: StatusDiverted, to: StatusLanded, expected: true},
+		{from: StatusLanded, to: StatusLanded, expected: true},
+		{from: StatusLanded, to: StatusScheduled, expected: false},
+		{from: StatusCancelled, to: StatusBoarding, expected: false},
+		{from: StatusScheduled, to: StatusLanded, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanTransition(tt.from, tt.to))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlightPriceJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedPrice money.Money
+		expectedUSD   money.USD
+	}{
+		{name: "priceUSD only", data: `{"priceUSD":199.99}`, expectedPrice: money.New(19999, "USD"), expectedUSD: 19999},
+		{name: "priceUSD in dollars", data: `{"priceUSD":0.29,"currency":"usd"}`, expectedPrice: money.New(29, "USD"), expectedUSD: 29},
+		{name: "amount and currency", data: `{"priceAmount":45000,"currency":"EUR","priceUSD":468.02}`, expectedPrice: money.New(45000, "EUR"), expectedUSD: 46802},
+		{name: "currency without amount", data: `{"currency":"EUR"}`, expectedPrice: money.New(0, "EUR")},
+		{name: "no price", data: `{}`, expectedPrice: money.New(0, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var flight Flight
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &flight))
+			assert.Equal(t, tt.expectedPrice, flight.Price)
+			assert.Equal(t, tt.expectedUSD, flight.PriceUSD)
+		})
+	}
+}
+
+func TestFlightPriceJSONRoundTrip(t *testing.T) {
+	flight := Flight{ID: "AF22-2025-04-29", FlightNumber: "AF22", Class: "Economy", PriceUSD: 46802, Price: money.New(45000, "EUR")}
+
+	data, err := json.Marshal(flight)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 468.02, fields["priceUSD"])
+	assert.Equal(t, 45000.0, fields["priceAmount"])
+	assert.Equal(t, "EUR", fields["currency"])
+
+	var decoded Flight
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, flight, decoded)
+}
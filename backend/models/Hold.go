@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// HoldStatus is a step of the hold lifecycle.
+type HoldStatus string
+
+const (
+	HoldActive    HoldStatus = "held"      // Seats are taken from the inventory until the hold expires
+	HoldConfirmed HoldStatus = "confirmed" // Seats are sold
+	HoldReleased  HoldStatus = "released"  // Seats went back to the inventory
+	HoldExpired   HoldStatus = "expired"   // The hold was not confirmed in time, seats went back to the inventory
+)
+
+// Hold reserves seats of a flight fare for a customer, e.g. while they pay.
+type Hold struct {
+	ID          string     `json:"id"`
+	Owner       string     `json:"owner"`
+	FlightID    string     `json:"flightId"`
+	Class       CabinClass `json:"class"`
+	Seats       int        `json:"seats"`
+	Status      HoldStatus `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty"`
+	ReleasedAt  *time.Time `json:"releasedAt,omitempty"` // When the seats went back, released or expired
+	Booking     string     `json:"booking,omitempty"`    // Locator of the booking the hold belongs to
+}
+
+// Inventory is the number of seats left for sale per fare class of a flight.
+type Inventory struct {
+	FlightID string             `json:"flightId"`
+	Seats    map[CabinClass]int `json:"seats"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// PriceObservation is the price of a flight fare seen during one crawl.
+type PriceObservation struct {
+	Class        CabinClass  `json:"class"`
+	PriceUSD     money.USD   `json:"priceUSD"`
+	Price        money.Money `json:"-"`                      // Written to JSON as priceAmount and currency
+	ExchangeRate *Conversion `json:"exchangeRate,omitempty"` // Set when the price was converted for the response
+	ObservedAt   time.Time   `json:"observedAt"`
+}
+
+// MarshalJSON writes the observed price as priceAmount, in minor units, and currency.
+func (o PriceObservation) MarshalJSON() ([]byte, error) {
+	type plain PriceObservation
+	return json.Marshal(struct {
+		plain
+		PriceAmount int64  `json:"priceAmount,omitempty"`
+		Currency    string `json:"currency,omitempty"`
+	}{plain(o), o.Price.Amount, o.Price.Currency})
+}
+
+// UnmarshalJSON reads the observed price from priceAmount and currency. Observations
+// recorded before prices had one are in US dollars.
+func (o *PriceObservation) UnmarshalJSON(data []byte) error {
+	type plain PriceObservation
+	decoded := struct {
+		*plain
+		PriceAmount *int64 `json:"priceAmount"`
+		Currency    string `json:"currency"`
+	}{plain: (*plain)(o)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	switch {
+	case decoded.PriceAmount != nil:
+		o.Price = money.New(*decoded.PriceAmount, decoded.Currency)
+	case decoded.Currency == "" || strings.EqualFold(decoded.Currency, "USD"):
+		o.Price = o.PriceUSD.Money()
+	default:
+		o.Price = money.New(0, decoded.Currency)
+	}
+	return nil
+}
+
+// PriceSummary describes how the price of a fare evolved across crawls.
+type PriceSummary struct {
+	Observations    int       `json:"observations"`
+	FirstSeen       time.Time `json:"firstSeen"`
+	LastSeen        time.Time `json:"lastSeen"`
+	FirstPriceUSD   money.USD `json:"firstPriceUSD"`
+	CurrentPriceUSD money.USD `json:"currentPriceUSD"`
+	MinPriceUSD     money.USD `json:"minPriceUSD"`
+	MaxPriceUSD     money.USD `json:"maxPriceUSD"`
+	ChangeUSD       money.USD `json:"changeUSD"`     // Current price minus the first price seen
+	ChangePercent   float64   `json:"changePercent"` // ChangeUSD relative to the first price seen
+	// InCurrency is the same summary in the currency of the observations, omitted when
+	// they are not all in the same currency.
+	InCurrency *CurrencySummary `json:"inCurrency,omitempty"`
+}
+
+// CurrencySummary is a PriceSummary in minor units of one currency.
+type CurrencySummary struct {
+	Currency     string `json:"currency"`
+	FirstPrice   int64  `json:"firstPrice"`
+	CurrentPrice int64  `json:"currentPrice"`
+	MinPrice     int64  `json:"minPrice"`
+	MaxPrice     int64  `json:"maxPrice"`
+	Change       int64  `json:"change"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SavedSearch is a named flight search a user can run again.
+type SavedSearch struct {
+	ID        string            `json:"id"`
+	Owner     string            `json:"owner"` // Subject of the token that saved the search
+	Name      string            `json:"name"`
+	Query     map[string]string `json:"query"` // Filters of /api/flights/search, e.g. origin and date
+	CreatedAt time.Time         `json:"createdAt"`
+	LastRunAt *time.Time        `json:"lastRunAt,omitempty"`
+}
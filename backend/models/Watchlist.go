@@ -0,0 +1,35 @@
+package models
+
+import (
+	"FlightAPI/money"
+	"time"
+)
+
+// WatchedFlight is a flight fare on a user's watchlist, with its status and price when it
+// was added.
+type WatchedFlight struct {
+	ID               string       `json:"id"`
+	Owner            string       `json:"owner"` // Subject of the token that added the flight
+	FlightID         string       `json:"flightId"`
+	Class            CabinClass   `json:"class"`
+	FlightNumber     string       `json:"flightNumber"`
+	DepartureAirport string       `json:"departureAirport"`
+	ArrivalAirport   string       `json:"arrivalAirport"`
+	DepartureTime    string       `json:"departureTime"`
+	AddedAt          time.Time    `json:"addedAt"`
+	AddedStatus      FlightStatus `json:"addedStatus"`
+	AddedPriceUSD    money.USD    `json:"addedPriceUSD"`
+}
+
+// WatchlistEntry is a watched flight fare with its current status and how its price
+// changed since it was added.
+type WatchlistEntry struct {
+	WatchedFlight
+	Status             FlightStatus       `json:"status"`
+	StatusChanged      bool               `json:"statusChanged"`
+	PriceUSD           money.USD          `json:"priceUSD"`
+	PriceChangeUSD     money.USD          `json:"priceChangeUSD"`     // Current price minus the price when added
+	PriceChangePercent float64            `json:"priceChangePercent"` // PriceChangeUSD relative to the price when added
+	PriceChanges       []PriceObservation `json:"priceChanges"`       // Each new price seen since the flight was added
+	Gone               bool               `json:"gone,omitempty"`     // The fare is no longer stored, e.g. long after departure
+}
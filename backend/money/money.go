@@ -0,0 +1,162 @@
+// Package money represents prices as integer minor units of a currency, so sums,
+// differences and threshold comparisons are exact. Amounts in US dollars have their
+// own type, USD, which keeps the decimal JSON numbers of the priceUSD fields.
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts of different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in minor units of a currency, e.g. 45000 EUR cents for 450.00 EUR.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"` // ISO 4217 code
+}
+
+// New returns an amount in minor units of a currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// IsZero reports whether the amount is zero, whatever the currency.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares two amounts of the same currency and returns -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// Sum adds amounts of the same currency, e.g. the legs of a round trip.
+// The sum of no amounts is zero in no currency.
+func Sum(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, nil
+	}
+	total := amounts[0]
+	for _, amount := range amounts[1:] {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// USD is an amount of US dollars in cents. It is written to JSON as a decimal number of
+// dollars, e.g. 199.99, like the float64 prices it replaces, and read from such numbers
+// without going through float64.
+type USD int64
+
+// ParseUSD parses a decimal number of dollars such as "199.99", "-5" or "1.5e2".
+// Fractions of a cent are rounded half away from zero.
+func ParseUSD(value string) (USD, error) {
+	dollars, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	cents := new(big.Rat).Mul(dollars, big.NewRat(100, 1))
+
+	// Round half away from zero: truncate |cents| + 1/2
+	negative := cents.Sign() < 0
+	cents.Abs(cents)
+	cents.Add(cents, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(cents.Num(), cents.Denom())
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("amount %q out of range", value)
+	}
+	if negative {
+		return USD(-rounded.Int64()), nil
+	}
+	return USD(rounded.Int64()), nil
+}
+
+// Money returns the amount as minor units of US dollars.
+func (u USD) Money() Money {
+	return Money{Amount: int64(u), Currency: "USD"}
+}
+
+// Dollars returns the amount in dollars, for ratios and display only.
+func (u USD) Dollars() float64 {
+	return float64(u) / 100
+}
+
+// String formats the amount as a decimal number of dollars without trailing zeros,
+// e.g. "199.99", "420.5" or "-5".
+func (u USD) String() string {
+	cents := int64(u)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	text := sign + strconv.FormatInt(cents/100, 10)
+	if fraction := cents % 100; fraction != 0 {
+		text += strings.TrimRight(fmt.Sprintf(".%02d", fraction), "0")
+	}
+	return text
+}
+
+func (u USD) MarshalJSON() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *USD) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) == 0 || data[0] == '"' {
+		return fmt.Errorf("invalid amount %s: must be a number", data)
+	}
+	parsed, err := ParseUSD(string(data))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
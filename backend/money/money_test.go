@@ -0,0 +1,83 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUSD(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected USD
+		wantErr  bool
+	}{
+		{value: "199.99", expected: 19999},
+		{value: "420.5", expected: 42050},
+		{value: "0", expected: 0},
+		{value: "-5", expected: -500},
+		{value: "1.5e2", expected: 15000},
+		{value: "0.125", expected: 13},
+		{value: "-0.125", expected: -13},
+		{value: "0.1234", expected: 12},
+		{value: "abc", wantErr: true},
+		{value: "1e30", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			amount, err := ParseUSD(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestUSDJSON(t *testing.T) {
+	var prices struct {
+		Values []USD `json:"values"`
+		Empty  USD   `json:"empty"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"values":[0.1, 0.2, 199.99, 420.5, 300, -5.05],"empty":null}`), &prices))
+	assert.Equal(t, []USD{10, 20, 19999, 42050, 30000, -505}, prices.Values)
+
+	// 0.1 + 0.2 is exactly 0.3, unlike with float64
+	assert.Equal(t, USD(30), prices.Values[0]+prices.Values[1])
+
+	data, err := json.Marshal(prices)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"values":[0.1, 0.2, 199.99, 420.5, 300, -5.05],"empty":0}`, string(data))
+
+	var invalid USD
+	assert.Error(t, json.Unmarshal([]byte(`"12.50"`), &invalid))
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	outbound := New(19999, "EUR")
+	inbound := New(25001, "EUR")
+
+	total, err := Sum(outbound, inbound)
+	require.NoError(t, err)
+	assert.Equal(t, New(45000, "EUR"), total)
+
+	difference, err := inbound.Sub(outbound)
+	require.NoError(t, err)
+	assert.Equal(t, New(5002, "EUR"), difference)
+
+	cmp, err := outbound.Cmp(inbound)
+	require.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+
+	_, err = outbound.Add(New(100, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = Sum(outbound, New(100, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = outbound.Cmp(New(100, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
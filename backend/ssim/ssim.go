@@ -0,0 +1,412 @@
+// Package ssim parses schedule files in the IATA SSIM Chapter 7 fixed-width format.
+// Flight leg records describe a period of operation and days of the week; they are
+// expanded into one dated models.Flight per day the leg operates and per cabin it sells.
+package ssim
+
+import (
+	"FlightAPI/models"
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordLength is the length of every SSIM record. Shorter lines are padded, since
+// trailing spaces are often trimmed when files are edited or transferred.
+const recordLength = 200
+
+// indefinitePeriod is how far a leg whose period of operation has no end is expanded.
+const indefinitePeriod = 365 * 24 * time.Hour
+
+// MaxFlights bounds the number of flights a file may expand to.
+const MaxFlights = 200000
+
+// Record types of Chapter 7.
+const (
+	recordHeader  = '1'
+	recordCarrier = '2'
+	recordLeg     = '3'
+	recordSegment = '4'
+	recordTrailer = '5'
+	recordFiller  = '0'
+)
+
+// Leg is a flight leg record: one leg of a flight operating over a period.
+type Leg struct {
+	Line               int
+	Airline            string
+	FlightNumber       string // Airline designator, number without leading zeros and operational suffix
+	LegSequence        int
+	ServiceType        string
+	PeriodFrom         time.Time
+	PeriodTo           time.Time // Zero when the period has no end
+	Days               [7]bool   // Monday first
+	Fortnightly        bool
+	DepartureStation   string
+	DepartureTime      string // HHMM
+	DepartureVariation *time.Location
+	ArrivalStation     string
+	ArrivalTime        string // HHMM
+	ArrivalVariation   *time.Location
+	AircraftType       string
+	BookingDesignators string
+	DepartureDateShift int // Days between the period date and the departure, for later legs
+	ArrivalDateShift   int // Days between the period date and the arrival
+	UTC                bool
+}
+
+// Parse reads a SSIM file and returns its flight legs expanded into dated flights.
+// The legs of a multi-leg flight are merged into one flight from the first board point
+// to the last off point, since flights are identified by their number and date.
+// Errors report the line of the offending record.
+func Parse(r io.Reader) ([]models.Flight, error) {
+	legs, err := ParseLegs(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gather the legs of every dated flight, in the order the flights first appear
+	type datedFlight struct {
+		number string
+		date   time.Time
+	}
+	var order []datedFlight
+	itineraries := make(map[datedFlight][]Leg)
+	count := 0
+	for _, leg := range legs {
+		for _, date := range leg.dates() {
+			if count++; count > MaxFlights {
+				return nil, fmt.Errorf("line %d: schedule expands to more than %d flights", leg.Line, MaxFlights)
+			}
+			key := datedFlight{leg.FlightNumber, date}
+			if _, ok := itineraries[key]; !ok {
+				order = append(order, key)
+			}
+			itineraries[key] = append(itineraries[key], leg)
+		}
+	}
+
+	var flights []models.Flight
+	for _, key := range order {
+		itinerary := itineraries[key]
+		if err := checkItinerary(itinerary); err != nil {
+			return nil, fmt.Errorf("%w on %s", err, key.date.Format("2006-01-02"))
+		}
+		expanded := expand(key.date, itinerary)
+		if len(flights)+len(expanded) > MaxFlights {
+			return nil, fmt.Errorf("line %d: schedule expands to more than %d flights", itinerary[0].Line, MaxFlights)
+		}
+		flights = append(flights, expanded...)
+	}
+	return flights, nil
+}
+
+// checkItinerary sorts the legs of a dated flight by sequence number and checks that each
+// leg leaves from where the previous one arrived.
+func checkItinerary(legs []Leg) error {
+	sort.SliceStable(legs, func(i, j int) bool { return legs[i].LegSequence < legs[j].LegSequence })
+	for i := 1; i < len(legs); i++ {
+		previous, leg := legs[i-1], legs[i]
+		if leg.LegSequence == previous.LegSequence {
+			return fmt.Errorf("line %d: flight %s has leg %d twice", leg.Line, leg.FlightNumber, leg.LegSequence)
+		}
+		if leg.DepartureStation != previous.ArrivalStation {
+			return fmt.Errorf("line %d: leg %d of flight %s leaves from %s, not from %s where leg %d arrives",
+				leg.Line, leg.LegSequence, leg.FlightNumber, leg.DepartureStation, previous.ArrivalStation, previous.LegSequence)
+		}
+	}
+	return nil
+}
+
+// ParseLegs reads the flight leg records of a SSIM file. Header, segment data and trailer
+// records are skipped; carrier records set whether the following legs use UTC or local times.
+func ParseLegs(r io.Reader) ([]Leg, error) {
+	scanner := bufio.NewScanner(r)
+
+	var legs []Leg
+	utc := false
+	for line := 1; scanner.Scan(); line++ {
+		record := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		if len(record) > recordLength {
+			return nil, fmt.Errorf("line %d: record longer than %d characters", line, recordLength)
+		}
+		record += strings.Repeat(" ", recordLength-len(record))
+
+		switch record[0] {
+		case recordCarrier:
+			// Time mode: U for UTC, L for local times
+			utc = record[1] == 'U'
+		case recordLeg:
+			leg, err := parseLeg(record, utc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			leg.Line = line
+			legs = append(legs, leg)
+		case recordHeader, recordSegment, recordTrailer, recordFiller:
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %q", line, record[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+// field returns the trimmed characters of a record between 1-based positions, inclusive,
+// as they are numbered in the SSIM manual.
+func field(record string, from, to int) string {
+	return strings.TrimSpace(record[from-1 : to])
+}
+
+func parseLeg(record string, utc bool) (Leg, error) {
+	leg := Leg{
+		Airline:            field(record, 3, 5),
+		ServiceType:        field(record, 14, 14),
+		DepartureStation:   field(record, 37, 39),
+		DepartureTime:      field(record, 40, 43),
+		ArrivalStation:     field(record, 55, 57),
+		ArrivalTime:        field(record, 62, 65),
+		AircraftType:       field(record, 73, 75),
+		BookingDesignators: field(record, 76, 95),
+		Fortnightly:        field(record, 36, 36) == "2",
+		UTC:                utc,
+	}
+
+	if leg.Airline == "" {
+		return Leg{}, fmt.Errorf("missing airline designator")
+	}
+	number, err := strconv.Atoi(field(record, 6, 9))
+	if err != nil {
+		return Leg{}, fmt.Errorf("invalid flight number %q", field(record, 6, 9))
+	}
+	leg.FlightNumber = fmt.Sprintf("%s%d%s", leg.Airline, number, field(record, 2, 2))
+
+	if leg.LegSequence, err = strconv.Atoi(field(record, 12, 13)); err != nil {
+		return Leg{}, fmt.Errorf("invalid leg sequence number %q", field(record, 12, 13))
+	}
+
+	if leg.PeriodFrom, err = parseDate(field(record, 15, 21)); err != nil {
+		return Leg{}, fmt.Errorf("invalid period start: %w", err)
+	}
+	// 00XXX00 means the leg operates until further notice
+	if to := field(record, 22, 28); to != "00XXX00" {
+		if leg.PeriodTo, err = parseDate(to); err != nil {
+			return Leg{}, fmt.Errorf("invalid period end: %w", err)
+		}
+		if leg.PeriodTo.Before(leg.PeriodFrom) {
+			return Leg{}, fmt.Errorf("period ends before it starts")
+		}
+	}
+
+	days := record[28:35]
+	for i := 0; i < 7; i++ {
+		switch days[i] {
+		case byte('1' + i):
+			leg.Days[i] = true
+		case ' ':
+		default:
+			return Leg{}, fmt.Errorf("invalid days of operation %q", days)
+		}
+	}
+
+	for _, t := range []string{leg.DepartureTime, leg.ArrivalTime} {
+		if _, err := parseClock(t); err != nil {
+			return Leg{}, err
+		}
+	}
+	if leg.DepartureVariation, err = parseVariation(field(record, 48, 52)); err != nil {
+		return Leg{}, fmt.Errorf("invalid departure time variation: %w", err)
+	}
+	if leg.ArrivalVariation, err = parseVariation(field(record, 66, 70)); err != nil {
+		return Leg{}, fmt.Errorf("invalid arrival time variation: %w", err)
+	}
+
+	if leg.DepartureDateShift, err = parseDateVariation(record[192]); err != nil {
+		return Leg{}, fmt.Errorf("invalid departure date variation: %w", err)
+	}
+	if leg.ArrivalDateShift, err = parseDateVariation(record[193]); err != nil {
+		return Leg{}, fmt.Errorf("invalid arrival date variation: %w", err)
+	}
+
+	return leg, nil
+}
+
+// Expand returns a flight for every date the leg operates and every cabin it sells.
+// SSIM schedules don't carry fares, so the flights have no price.
+func (leg Leg) Expand() []models.Flight {
+	var flights []models.Flight
+	for _, date := range leg.dates() {
+		flights = append(flights, expand(date, []Leg{leg})...)
+	}
+	return flights
+}
+
+// dates returns the dates of the period of operation the leg operates on.
+func (leg Leg) dates() []time.Time {
+	to := leg.PeriodTo
+	if to.IsZero() {
+		to = leg.PeriodFrom.Add(indefinitePeriod)
+	}
+
+	var dates []time.Time
+	for date := leg.PeriodFrom; !date.After(to); date = date.AddDate(0, 0, 1) {
+		// Monday is the first day of the week in SSIM
+		if !leg.Days[(int(date.Weekday())+6)%7] {
+			continue
+		}
+		if leg.Fortnightly && int(date.Sub(leg.PeriodFrom).Hours()/24)/7%2 == 1 {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// expand returns a flight on a date for every cabin sold on all its legs, from the
+// departure of the first leg to the arrival of the last one. The legs are in sequence.
+func expand(date time.Time, legs []Leg) []models.Flight {
+	first, last := legs[0], legs[len(legs)-1]
+	departure := first.instant(date.AddDate(0, 0, first.DepartureDateShift), first.DepartureTime, first.DepartureVariation)
+	arrival := last.instant(date.AddDate(0, 0, last.ArrivalDateShift), last.ArrivalTime, last.ArrivalVariation)
+
+	var flights []models.Flight
+	for _, cabin := range throughCabins(legs) {
+		flights = append(flights, models.Flight{
+			FlightNumber:     first.FlightNumber,
+			Airline:          first.Airline,
+			DepartureAirport: models.Airport{Code: first.DepartureStation},
+			ArrivalAirport:   models.Airport{Code: last.ArrivalStation},
+			DepartureTime:    departure.Format(time.RFC3339),
+			ArrivalTime:      arrival.Format(time.RFC3339),
+			Class:            cabin,
+			Status:           models.StatusScheduled,
+			Duration:         formatDuration(arrival.Sub(departure)),
+			Aircraft:         first.AircraftType,
+		})
+	}
+	return flights
+}
+
+// throughCabins returns the cabins of the first leg that every other leg sells too.
+// Legs that sell no cabin in common are sold in economy.
+func throughCabins(legs []Leg) []models.CabinClass {
+	var result []models.CabinClass
+	for _, cabin := range cabins(legs[0].BookingDesignators) {
+		sold := true
+		for _, leg := range legs[1:] {
+			sold = sold && slices.Contains(cabins(leg.BookingDesignators), cabin)
+		}
+		if sold {
+			result = append(result, cabin)
+		}
+	}
+	if len(result) == 0 {
+		return []models.CabinClass{models.CabinEconomy}
+	}
+	return result
+}
+
+// instant returns the time of a leg event in the local time of its station.
+func (leg Leg) instant(date time.Time, clock string, variation *time.Location) time.Time {
+	minutes, _ := parseClock(clock)
+	if leg.UTC {
+		// The schedule is in UTC: dates and times are UTC, the variation gives the local offset
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, minutes, 0, 0, time.UTC).In(variation)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, minutes, 0, 0, variation)
+}
+
+// cabins returns the cabins sold through the booking designators of a leg, in the order
+// they appear. Legs without designators are sold in economy.
+func cabins(designators string) []models.CabinClass {
+	var result []models.CabinClass
+	seen := make(map[models.CabinClass]bool)
+	for _, designator := range designators {
+		cabin := cabinOf(designator)
+		if cabin == "" || seen[cabin] {
+			continue
+		}
+		seen[cabin] = true
+		result = append(result, cabin)
+	}
+	if len(result) == 0 {
+		return []models.CabinClass{models.CabinEconomy}
+	}
+	return result
+}
+
+// cabinOf maps a booking designator to its usual cabin.
+func cabinOf(designator rune) models.CabinClass {
+	switch designator {
+	case 'F', 'A', 'P', 'R':
+		return models.CabinFirst
+	case 'J', 'C', 'D', 'I', 'Z':
+		return models.CabinBusiness
+	case 'W', 'E':
+		return models.CabinPremiumEconomy
+	case 'Y', 'B', 'H', 'K', 'M', 'L', 'V', 'S', 'N', 'Q', 'O', 'G', 'X', 'T', 'U':
+		return models.CabinEconomy
+	}
+	return ""
+}
+
+// parseDate parses a DDMMMYY date, e.g. 28APR25.
+func parseDate(value string) (time.Time, error) {
+	return time.Parse("02Jan06", value)
+}
+
+// parseClock parses a HHMM time and returns the minutes since midnight. 2400 is accepted
+// as the end of the day.
+func parseClock(value string) (int, error) {
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hours, err1 := strconv.Atoi(value[:2])
+	minutes, err2 := strconv.Atoi(value[2:])
+	if err1 != nil || err2 != nil || hours > 24 || minutes > 59 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// parseVariation parses a +HHMM/-HHMM offset between UTC and local time.
+func parseVariation(value string) (*time.Location, error) {
+	if len(value) != 5 || (value[0] != '+' && value[0] != '-') {
+		return nil, fmt.Errorf("%q is not +HHMM or -HHMM", value)
+	}
+	minutes, err := parseClock(value[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%q is not +HHMM or -HHMM", value)
+	}
+	if value[0] == '-' {
+		minutes = -minutes
+	}
+	return time.FixedZone(value, minutes*60), nil
+}
+
+// parseDateVariation parses a date variation: a digit, or A for the day before.
+func parseDateVariation(value byte) (int, error) {
+	switch {
+	case value == ' ':
+		return 0, nil
+	case value == 'A':
+		return -1, nil
+	case value >= '0' && value <= '9':
+		return int(value - '0'), nil
+	}
+	return 0, fmt.Errorf("%q is not a digit or A", value)
+}
+
+// formatDuration formats a duration as hours and minutes, e.g. 2h15m.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
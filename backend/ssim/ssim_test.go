@@ -0,0 +1,153 @@
+package ssim
+
+import (
+	"FlightAPI/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// record builds a SSIM record from values at 1-based positions.
+func record(values map[int]string) string {
+	line := []byte(strings.Repeat(" ", recordLength))
+	for position, value := range values {
+		copy(line[position-1:], value)
+	}
+	return string(line)
+}
+
+func legRecord(overrides map[int]string) string {
+	values := map[int]string{
+		1:   "3",
+		3:   "DL",
+		6:   "0123",
+		10:  "01",
+		12:  "01",
+		14:  "J",
+		15:  "28APR25",
+		22:  "04MAY25",
+		29:  "1 3 5 7",
+		37:  "ATL",
+		40:  "2230",
+		44:  "2230",
+		48:  "-0400",
+		55:  "LHR",
+		58:  "1140",
+		62:  "1140",
+		66:  "+0100",
+		73:  "339",
+		76:  "JCYBM",
+		193: "01",
+	}
+	for position, value := range overrides {
+		values[position] = value
+	}
+	return record(values)
+}
+
+func TestParse(t *testing.T) {
+	file := strings.Join([]string{
+		record(map[int]string{1: "1AIRLINE STANDARD SCHEDULE DATA SET"}),
+		record(map[int]string{1: "2L", 3: "DL"}),
+		legRecord(nil),
+		record(map[int]string{1: "4", 3: "DL"}),
+		record(map[int]string{1: "5", 3: "DL"}),
+	}, "\n")
+
+	flights, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+
+	// Monday 28 April, Wednesday 30 April, Friday 2 May and Sunday 4 May, in business and economy
+	if assert.Len(t, flights, 8) {
+		first := flights[0]
+		assert.Equal(t, "DL123", first.FlightNumber)
+		assert.Equal(t, "ATL", first.DepartureAirport.Code)
+		assert.Equal(t, "LHR", first.ArrivalAirport.Code)
+		assert.Equal(t, "2025-04-28T22:30:00-04:00", first.DepartureTime)
+		assert.Equal(t, "2025-04-29T11:40:00+01:00", first.ArrivalTime)
+		assert.Equal(t, "8h10m", first.Duration)
+		assert.Equal(t, "339", first.Aircraft)
+		assert.Equal(t, models.CabinBusiness, first.Class)
+		assert.Equal(t, models.CabinEconomy, flights[1].Class)
+		assert.Equal(t, "2025-05-04T22:30:00-04:00", flights[7].DepartureTime)
+	}
+}
+
+func TestParseUTCSchedule(t *testing.T) {
+	file := record(map[int]string{1: "2U", 3: "DL"}) + "\n" + legRecord(map[int]string{40: "0230", 44: "0230", 58: "1040", 62: "1040", 193: "00", 76: strings.Repeat(" ", 20)})
+
+	flights, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, flights) {
+		// 02:30 UTC on 28 April is 22:30 the day before in Atlanta
+		assert.Equal(t, "2025-04-27T22:30:00-04:00", flights[0].DepartureTime)
+		assert.Equal(t, "2025-04-28T11:40:00+01:00", flights[0].ArrivalTime)
+		assert.Equal(t, models.CabinEconomy, flights[0].Class)
+	}
+}
+
+func TestParseFortnightly(t *testing.T) {
+	file := legRecord(map[int]string{22: "25MAY25", 29: "1      ", 36: "2", 76: "Y"})
+
+	flights, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+	var dates []string
+	for _, flight := range flights {
+		dates = append(dates, flight.DepartureTime[:10])
+	}
+	assert.Equal(t, []string{"2025-04-28", "2025-05-12"}, dates)
+}
+
+func TestParseMultiLegFlight(t *testing.T) {
+	// AA100 flies JFK to ORD, then on to LAX past midnight UTC, on Monday 28 April only
+	firstLeg := map[int]string{
+		3: "AA", 6: "0100", 12: "01", 22: "28APR25", 29: "1      ", 37: "JFK", 40: "1800", 44: "1800", 48: "-0400",
+		55: "ORD", 58: "1930", 62: "1930", 66: "-0500", 73: "321", 76: "JY", 193: "00",
+	}
+	secondLeg := map[int]string{
+		3: "AA", 6: "0100", 12: "02", 22: "28APR25", 29: "1      ", 37: "ORD", 40: "2030", 44: "2030", 48: "-0500",
+		55: "LAX", 58: "2245", 62: "2245", 66: "-0700", 73: "321", 76: "Y", 193: "00",
+	}
+	// Legs are merged whatever their order in the file
+	file := legRecord(secondLeg) + "\n" + legRecord(firstLeg)
+
+	flights, err := Parse(strings.NewReader(file))
+	assert.NoError(t, err)
+	// Business is only sold on the first leg
+	if assert.Len(t, flights, 1) {
+		flight := flights[0]
+		assert.Equal(t, "AA100", flight.FlightNumber)
+		assert.Equal(t, "JFK", flight.DepartureAirport.Code)
+		assert.Equal(t, "LAX", flight.ArrivalAirport.Code)
+		assert.Equal(t, "2025-04-28T18:00:00-04:00", flight.DepartureTime)
+		assert.Equal(t, "2025-04-28T22:45:00-07:00", flight.ArrivalTime)
+		assert.Equal(t, "7h45m", flight.Duration)
+		assert.Equal(t, models.CabinEconomy, flight.Class)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected string
+	}{
+		{name: "invalid date", file: legRecord(map[int]string{15: "31FEB25"}), expected: "line 1: invalid period start"},
+		{name: "period ends before it starts", file: legRecord(map[int]string{22: "01APR25"}), expected: "line 1: period ends before it starts"},
+		{name: "invalid days", file: legRecord(map[int]string{29: "1234568"}), expected: "line 1: invalid days of operation"},
+		{name: "invalid time", file: legRecord(map[int]string{40: "2590"}), expected: `line 1: invalid time "2590"`},
+		{name: "unknown record type", file: "\n" + record(map[int]string{1: "9"}), expected: "line 2: unknown record type"},
+		{name: "leg twice", file: legRecord(nil) + "\n" + legRecord(nil), expected: "line 2: flight DL123 has leg 1 twice on 2025-04-28"},
+		{name: "legs not connected", file: legRecord(nil) + "\n" + legRecord(map[int]string{12: "02", 37: "CDG"}), expected: "line 2: leg 2 of flight DL123 leaves from CDG, not from LHR where leg 1 arrives"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.file))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// alertsKey is the hash of every alert, keyed by alert ID.
+const alertsKey = "alerts"
+
+// maxDeliveries is how many deliveries are kept in the log of each alert.
+const maxDeliveries = 100
+
+// alertMatchesKey is the list of alert matches waiting for their webhook call, newest first.
+const alertMatchesKey = "alerts:matches"
+
+// ErrNotFound is returned when a record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyNotified is returned when an alert already notified a fare at a price or lower.
+var ErrAlreadyNotified = errors.New("fare already notified")
+
+func alertDeliveriesKey(alertID string) string {
+	return "alert:" + alertID + ":deliveries"
+}
+
+// alertNotifiedKey is the hash of the last price notified per flight fare, so an alert
+// only fires again when the fare gets cheaper than what the user already heard about.
+func alertNotifiedKey(alertID string) string {
+	return "alert:" + alertID + ":notified"
+}
+
+// SaveAlert creates or replaces an alert.
+func SaveAlert(ctx context.Context, rdb *redis.Client, alert models.Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.HSet(ctx, alertsKey, alert.ID, data).Err()
+}
+
+// GetAlert returns one alert, or ErrNotFound.
+func GetAlert(ctx context.Context, rdb *redis.Client, alertID string) (models.Alert, error) {
+	data, err := rdb.HGet(ctx, alertsKey, alertID).Result()
+	if err == redis.Nil {
+		return models.Alert{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Alert{}, err
+	}
+
+	var alert models.Alert
+	if err := json.Unmarshal([]byte(data), &alert); err != nil {
+		return models.Alert{}, fmt.Errorf("unmarshal alert %s: %w", alertID, err)
+	}
+	return alert, nil
+}
+
+// ListAlerts returns the alerts of an owner, or every alert when owner is empty.
+func ListAlerts(ctx context.Context, rdb *redis.Client, owner string) ([]models.Alert, error) {
+	all, err := rdb.HGetAll(ctx, alertsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []models.Alert{}
+	for id, data := range all {
+		var alert models.Alert
+		if err := json.Unmarshal([]byte(data), &alert); err != nil {
+			return nil, fmt.Errorf("unmarshal alert %s: %w", id, err)
+		}
+		if owner == "" || alert.Owner == owner {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// DeleteAlert removes an alert along with its delivery log.
+func DeleteAlert(ctx context.Context, rdb *redis.Client, alertID string) error {
+	pipe := rdb.TxPipeline()
+	pipe.HDel(ctx, alertsKey, alertID)
+	pipe.Del(ctx, alertDeliveriesKey(alertID), alertNotifiedKey(alertID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AppendDelivery adds a delivery to the alert log, newest first, keeping the last maxDeliveries.
+func AppendDelivery(ctx context.Context, rdb *redis.Client, delivery models.AlertDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	key := alertDeliveriesKey(delivery.AlertID)
+	pipe := rdb.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, maxDeliveries-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// QueueAlertMatch queues a match for its webhook to be called by an alert worker.
+func QueueAlertMatch(ctx context.Context, rdb *redis.Client, match models.AlertMatch) error {
+	data, err := json.Marshal(match)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.LPush(ctx, alertMatchesKey, data).Err()
+}
+
+// NextAlertMatch takes the oldest queued match, waiting up to timeout for one. It returns
+// ErrNotFound when none was queued in time. Each match is taken by one worker only.
+func NextAlertMatch(ctx context.Context, rdb *redis.Client, timeout time.Duration) (models.AlertMatch, error) {
+	result, err := rdb.BRPop(ctx, timeout, alertMatchesKey).Result()
+	if err == redis.Nil {
+		return models.AlertMatch{}, ErrNotFound
+	}
+	if err != nil {
+		return models.AlertMatch{}, err
+	}
+
+	// BRPOP replies with the key and the value
+	var match models.AlertMatch
+	if err := json.Unmarshal([]byte(result[1]), &match); err != nil {
+		return models.AlertMatch{}, fmt.Errorf("unmarshal alert match: %w", err)
+	}
+	return match, nil
+}
+
+// ListDeliveries returns the delivery log of an alert, newest first.
+func ListDeliveries(ctx context.Context, rdb *redis.Client, alertID string) ([]models.AlertDelivery, error) {
+	items, err := rdb.LRange(ctx, alertDeliveriesKey(alertID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.AlertDelivery, 0, len(items))
+	for _, item := range items {
+		var delivery models.AlertDelivery
+		if err := json.Unmarshal([]byte(item), &delivery); err != nil {
+			return nil, fmt.Errorf("unmarshal delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// LastNotifiedPrice returns the last price an alert notified for a flight fare.
+// The second return value is false if the fare was never notified.
+func LastNotifiedPrice(ctx context.Context, rdb *redis.Client, alertID, flightID string, class models.CabinClass) (money.USD, bool, error) {
+	value, err := rdb.HGet(ctx, alertNotifiedKey(alertID), FareKey(flightID, class)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	price, err := money.ParseUSD(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid notified price %q: %w", value, err)
+	}
+	return price, true, nil
+}
+
+// claimNotifiedPriceScript sets the notified price of the fare ARGV[1] to ARGV[2], unless it
+// was already notified at that price or lower, and returns the previous price, "" if there
+// was none. It returns nil when the fare is not claimed.
+var claimNotifiedPriceScript = redis.NewScript(`
+local previous = redis.call("HGET", KEYS[1], ARGV[1])
+if previous and tonumber(ARGV[2]) >= tonumber(previous) then
+	return nil
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+return previous or ""
+`)
+
+// restoreNotifiedPriceScript puts the previous notified price ARGV[3] of the fare ARGV[1]
+// back, or removes it if empty, as long as the price is still the claimed one ARGV[2].
+var restoreNotifiedPriceScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == "" then
+	redis.call("HDEL", KEYS[1], ARGV[1])
+else
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+end
+return 1
+`)
+
+// ClaimNotifiedPrice records the price an alert is about to notify for a flight fare, so
+// only one worker calls the webhook for it. It returns ErrAlreadyNotified when the fare was
+// notified at this price or lower. The returned function gives the claim back when the
+// delivery failed, restoring the previous price unless a cheaper fare was claimed since.
+func ClaimNotifiedPrice(ctx context.Context, rdb *redis.Client, alertID, flightID string, class models.CabinClass, price money.USD) (func(context.Context) error, error) {
+	key, field := alertNotifiedKey(alertID), FareKey(flightID, class)
+	previous, err := claimNotifiedPriceScript.Run(ctx, rdb, []string{key}, field, price.String()).Text()
+	if err == redis.Nil {
+		return nil, ErrAlreadyNotified
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	restore := func(ctx context.Context) error {
+		return restoreNotifiedPriceScript.Run(ctx, rdb, []string{key}, field, price.String(), previous).Err()
+	}
+	return restore, nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimNotifiedPrice(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	notified := func() money.USD {
+		price, _, err := LastNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy)
+		require.NoError(t, err)
+		return price
+	}
+
+	_, err := ClaimNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy, 45050)
+	require.NoError(t, err)
+	assert.Equal(t, money.USD(45050), notified())
+
+	// The same price or a higher one was already notified
+	for _, price := range []money.USD{45050, 50000} {
+		_, err = ClaimNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy, price)
+		assert.ErrorIs(t, err, ErrAlreadyNotified)
+	}
+
+	// Giving a cheaper claim back restores the previous price
+	restore, err := ClaimNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy, 40000)
+	require.NoError(t, err)
+	assert.Equal(t, money.USD(40000), notified())
+	require.NoError(t, restore(ctx))
+	assert.Equal(t, money.USD(45050), notified())
+
+	// Unless an even cheaper fare was claimed since
+	restore, err = ClaimNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy, 40000)
+	require.NoError(t, err)
+	_, err = ClaimNotifiedPrice(ctx, rdb, "alert1", testFlight, models.CabinEconomy, 35000)
+	require.NoError(t, err)
+	require.NoError(t, restore(ctx))
+	assert.Equal(t, money.USD(35000), notified())
+
+	// A first claim given back leaves the fare never notified
+	restore, err = ClaimNotifiedPrice(ctx, rdb, "alert1", "AA100-2027-04-26", models.CabinEconomy, 30000)
+	require.NoError(t, err)
+	require.NoError(t, restore(ctx))
+	_, seen, err := LastNotifiedPrice(ctx, rdb, "alert1", "AA100-2027-04-26", models.CabinEconomy)
+	require.NoError(t, err)
+	assert.False(t, seen)
+}
@@ -0,0 +1,308 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// locatorAlphabet leaves out I, O, 0 and 1, which are easily mixed up when read out.
+// Its 32 letters split a random byte evenly.
+const locatorAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// locatorLength is the length of a record locator, as on airline bookings.
+const locatorLength = 6
+
+// maxLocatorAttempts is how many locators are tried before giving up on a new booking.
+const maxLocatorAttempts = 5
+
+var (
+	// ErrHoldBooked is returned when a hold already belongs to a booking.
+	ErrHoldBooked = errors.New("hold already belongs to a booking")
+	// ErrBookingClosed is returned when confirming or cancelling a booking past that step.
+	ErrBookingClosed = errors.New("booking can't change")
+)
+
+func bookingKey(locator string) string {
+	return "booking:" + locator
+}
+
+func bookingsOwnerKey(owner string) string {
+	return "bookings:owner:" + owner
+}
+
+// createBookingScript stores a new booking and marks its holds as booked, or returns -1
+// when the locator is taken, -2 when a hold is not active and -3 when a hold is already
+// booked. Doing both in one script keeps a hold from ending up in two bookings.
+var createBookingScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return -1
+end
+for i = 3, #KEYS do
+	if redis.call("HGET", KEYS[i], "status") ~= "held" then
+		return -2
+	end
+	if redis.call("HEXISTS", KEYS[i], "booking") == 1 then
+		return -3
+	end
+end
+for i = 3, #KEYS do
+	redis.call("HSET", KEYS[i], "booking", ARGV[2])
+end
+redis.call("SET", KEYS[1], ARGV[1])
+redis.call("SADD", KEYS[2], ARGV[2])
+return 1
+`)
+
+// NewLocator returns a random six-character record locator such as K7QM2X.
+func NewLocator() string {
+	b := make([]byte, locatorLength)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = locatorAlphabet[int(b[i])%len(locatorAlphabet)]
+	}
+	return string(b)
+}
+
+// CreateBooking stores a new booking under a fresh locator and ties its holds to it.
+// The holds must be active and not booked yet: it returns ErrHoldNotActive or ErrHoldBooked
+// otherwise.
+func CreateBooking(ctx context.Context, rdb *redis.Client, booking models.Booking) (models.Booking, error) {
+	for attempt := 0; attempt < maxLocatorAttempts; attempt++ {
+		booking.Locator = NewLocator()
+		data, err := json.Marshal(booking)
+		if err != nil {
+			return models.Booking{}, fmt.Errorf("marshal error: %w", err)
+		}
+
+		keys := []string{bookingKey(booking.Locator), bookingsOwnerKey(booking.Owner)}
+		for _, segment := range booking.Segments {
+			keys = append(keys, holdKey(segment.HoldID))
+		}
+
+		result, err := createBookingScript.Run(ctx, rdb, keys, data, booking.Locator).Int()
+		if err != nil {
+			return models.Booking{}, err
+		}
+		switch result {
+		case -1:
+			continue
+		case -2:
+			return models.Booking{}, ErrHoldNotActive
+		case -3:
+			return models.Booking{}, ErrHoldBooked
+		}
+		return booking, nil
+	}
+	return models.Booking{}, fmt.Errorf("no free locator after %d attempts", maxLocatorAttempts)
+}
+
+// GetBooking returns one booking, or ErrNotFound.
+func GetBooking(ctx context.Context, rdb *redis.Client, locator string) (models.Booking, error) {
+	data, err := rdb.Get(ctx, bookingKey(locator)).Result()
+	if err == redis.Nil {
+		return models.Booking{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	var booking models.Booking
+	if err := json.Unmarshal([]byte(data), &booking); err != nil {
+		return models.Booking{}, fmt.Errorf("unmarshal booking %s: %w", locator, err)
+	}
+	return booking, nil
+}
+
+// ListBookings returns the bookings of an owner, newest first.
+func ListBookings(ctx context.Context, rdb *redis.Client, owner string) ([]models.Booking, error) {
+	locators, err := rdb.SMembers(ctx, bookingsOwnerKey(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := []models.Booking{}
+	for _, locator := range locators {
+		booking, err := GetBooking(ctx, rdb, locator)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].CreatedAt.After(bookings[j].CreatedAt) })
+	return bookings, nil
+}
+
+// confirmBookingScript confirms a pending booking and every one of its holds at once.
+// KEYS are the booking, the expiring holds and a hold and inventory key per segment; ARGV
+// are the confirmed booking, now in Unix milliseconds and RFC 3339, then the hold IDs.
+// It returns {1, ""} when confirmed, {0, status} when the booking is not pending, with an
+// empty status if it does not exist, and {-1, hold ID} when a hold is no longer held.
+// Checking the status in the same script as the change keeps a concurrent cancel from
+// interleaving with it.
+var confirmBookingScript = redis.NewScript(`
+local booking = redis.call("GET", KEYS[1])
+if not booking then
+	return {0, ""}
+end
+local status = cjson.decode(booking)["status"]
+if status ~= "pending" then
+	return {0, status}
+end
+for i = 3, #KEYS, 2 do
+	local id = ARGV[4 + (i - 3) / 2]
+	local expiresAt = redis.call("ZSCORE", KEYS[2], id)
+	if redis.call("HGET", KEYS[i], "status") ~= "held" or not expiresAt or tonumber(expiresAt) <= tonumber(ARGV[2]) then
+		return {-1, id}
+	end
+end
+for i = 3, #KEYS, 2 do
+	redis.call("HSET", KEYS[i], "status", "confirmed", "confirmedAt", ARGV[3])
+	redis.call("ZREM", KEYS[2], ARGV[4 + (i - 3) / 2])
+end
+redis.call("SET", KEYS[1], ARGV[1])
+return {1, ""}
+`)
+
+// cancelBookingScript cancels a pending or confirmed booking and gives the seats of its
+// active and confirmed holds back to the inventory at once. It takes the KEYS and ARGV of
+// confirmBookingScript, with the cancelled booking, and returns {1, ""} when cancelled and
+// {0, status} when the booking can't be cancelled.
+var cancelBookingScript = redis.NewScript(`
+local booking = redis.call("GET", KEYS[1])
+if not booking then
+	return {0, ""}
+end
+local status = cjson.decode(booking)["status"]
+if status ~= "pending" and status ~= "confirmed" then
+	return {0, status}
+end
+for i = 3, #KEYS, 2 do
+	local holdStatus = redis.call("HGET", KEYS[i], "status")
+	if holdStatus == "held" or holdStatus == "confirmed" then
+		if redis.call("EXISTS", KEYS[i + 1]) == 1 then
+			redis.call("HINCRBY", KEYS[i + 1], redis.call("HGET", KEYS[i], "class"), redis.call("HGET", KEYS[i], "seats"))
+		end
+		redis.call("HSET", KEYS[i], "status", "released", "releasedAt", ARGV[3])
+	end
+	redis.call("ZREM", KEYS[2], ARGV[4 + (i - 3) / 2])
+end
+redis.call("SET", KEYS[1], ARGV[1])
+return {1, ""}
+`)
+
+// ConfirmBooking confirms every hold of a pending booking, so its seats are sold.
+// If a hold expired in the meantime, the booking is cancelled and its other seats go back
+// to the inventory; the cancelled booking is returned with ErrHoldNotActive. A booking
+// that is no longer pending, for instance because it was cancelled concurrently, is
+// returned as stored with ErrBookingClosed.
+func ConfirmBooking(ctx context.Context, rdb *redis.Client, booking models.Booking, now time.Time) (models.Booking, error) {
+	confirmedAt := now.UTC()
+	confirmed := booking
+	confirmed.Status = models.BookingConfirmed
+	confirmed.ConfirmedAt = &confirmedAt
+
+	result, err := runBookingScript(ctx, rdb, confirmBookingScript, confirmed, now)
+	if err != nil {
+		return booking, err
+	}
+	applied, _ := result[0].(int64)
+	detail, _ := result[1].(string)
+	switch applied {
+	case 1:
+		return confirmed, nil
+	case -1:
+		cancelled, err := CancelBooking(ctx, rdb, booking, now)
+		if err != nil {
+			return cancelled, err
+		}
+		return cancelled, fmt.Errorf("%w: hold %s is no longer held", ErrHoldNotActive, detail)
+	}
+	return bookingNotChanged(ctx, rdb, booking, detail)
+}
+
+// CancelBooking cancels a pending or confirmed booking and gives the seats of its holds
+// back to the inventory. Holds that already expired have nothing to give back. A booking
+// that was already cancelled is returned as stored with ErrBookingClosed.
+func CancelBooking(ctx context.Context, rdb *redis.Client, booking models.Booking, now time.Time) (models.Booking, error) {
+	cancelledAt := now.UTC()
+	cancelled := booking
+	cancelled.Status = models.BookingCancelled
+	cancelled.CancelledAt = &cancelledAt
+
+	result, err := runBookingScript(ctx, rdb, cancelBookingScript, cancelled, now)
+	if err != nil {
+		return booking, err
+	}
+	if applied, _ := result[0].(int64); applied == 1 {
+		return cancelled, nil
+	}
+	status, _ := result[1].(string)
+	return bookingNotChanged(ctx, rdb, booking, status)
+}
+
+// runBookingScript runs the confirm or cancel script for a booking with its new state,
+// and returns its {result, detail} reply.
+func runBookingScript(ctx context.Context, rdb *redis.Client, script *redis.Script, booking models.Booking, now time.Time) ([]interface{}, error) {
+	data, err := json.Marshal(booking)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+
+	keys := []string{bookingKey(booking.Locator), holdsExpiringKey}
+	args := []interface{}{data, now.UnixMilli(), now.UTC().Format(time.RFC3339Nano)}
+	for _, segment := range booking.Segments {
+		keys = append(keys, holdKey(segment.HoldID), InventoryKey(segment.FlightID))
+		args = append(args, segment.HoldID)
+	}
+
+	result, err := script.Run(ctx, rdb, keys, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 2 {
+		return nil, fmt.Errorf("unexpected script reply %v", result)
+	}
+	return result, nil
+}
+
+// bookingNotChanged returns the booking as stored after a script left it alone because of
+// its status, with ErrNotFound or ErrBookingClosed.
+func bookingNotChanged(ctx context.Context, rdb *redis.Client, booking models.Booking, status string) (models.Booking, error) {
+	if status == "" {
+		return booking, ErrNotFound
+	}
+	stored, err := GetBooking(ctx, rdb, booking.Locator)
+	if err != nil {
+		return booking, err
+	}
+	return stored, fmt.Errorf("%w: it is %s", ErrBookingClosed, status)
+}
+
+// expireBooking cancels a pending booking once one of its holds expired, so the seats of
+// its other holds go back too.
+func expireBooking(ctx context.Context, rdb *redis.Client, locator string, now time.Time) error {
+	booking, err := GetBooking(ctx, rdb, locator)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if booking.Status != models.BookingPending {
+		return nil
+	}
+	_, err = CancelBooking(ctx, rdb, booking, now)
+	return err
+}
@@ -0,0 +1,279 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBooking(owner string, now time.Time, holds ...models.Hold) models.Booking {
+	booking := models.Booking{
+		Owner:      owner,
+		Status:     models.BookingPending,
+		Passengers: []models.Passenger{{FirstName: "Ada", LastName: "Lovelace"}},
+		Contact:    models.Contact{Email: "ada@example.com"},
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(15 * time.Minute),
+	}
+	for _, hold := range holds {
+		booking.Segments = append(booking.Segments, models.Segment{HoldID: hold.ID, FlightID: hold.FlightID, Class: hold.Class, Seats: hold.Seats})
+	}
+	return booking
+}
+
+func TestNewLocator(t *testing.T) {
+	format := regexp.MustCompile(`^[A-HJ-NP-Z2-9]{6}$`)
+	for i := 0; i < 100; i++ {
+		assert.Regexp(t, format, NewLocator())
+	}
+}
+
+func TestBookingLifecycle(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	hold := newHold("alice", 1, now)
+	_, err := CreateHold(ctx, rdb, hold)
+	require.NoError(t, err)
+
+	booking, err := CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+	require.NoError(t, err)
+	assert.Len(t, booking.Locator, locatorLength)
+
+	// A hold can't be in two bookings
+	_, err = CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+	assert.ErrorIs(t, err, ErrHoldBooked)
+
+	stored, err := GetHold(ctx, rdb, hold.ID)
+	require.NoError(t, err)
+	assert.Equal(t, booking.Locator, stored.Booking)
+
+	confirmed, err := ConfirmBooking(ctx, rdb, booking, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, models.BookingConfirmed, confirmed.Status)
+	assert.Equal(t, 4, seatsLeft(t, rdb))
+
+	_, err = ConfirmBooking(ctx, rdb, confirmed, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrBookingClosed)
+
+	// Cancelling gives the seats back
+	cancelled, err := CancelBooking(ctx, rdb, confirmed, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, models.BookingCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.CancelledAt)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+
+	bookings, err := ListBookings(ctx, rdb, "alice")
+	require.NoError(t, err)
+	if assert.Len(t, bookings, 1) {
+		assert.Equal(t, models.BookingCancelled, bookings[0].Status)
+	}
+	bookings, err = ListBookings(ctx, rdb, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, bookings)
+
+	_, err = GetBooking(ctx, rdb, "AAAAAA")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBookingOfReleasedHold(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	hold := newHold("alice", 1, now)
+	_, err := CreateHold(ctx, rdb, hold)
+	require.NoError(t, err)
+	_, err = ReleaseHold(ctx, rdb, hold.ID, now)
+	require.NoError(t, err)
+
+	_, err = CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	bookings, err := ListBookings(ctx, rdb, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, bookings)
+}
+
+func TestExpiredBooking(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	first := newHold("alice", 1, now)
+	second := newHold("alice", 1, now)
+	second.ExpiresAt = now.Add(30 * time.Minute)
+	for _, hold := range []models.Hold{first, second} {
+		_, err := CreateHold(ctx, rdb, hold)
+		require.NoError(t, err)
+	}
+	booking, err := CreateBooking(ctx, rdb, newBooking("alice", now, first, second))
+	require.NoError(t, err)
+	assert.Equal(t, 3, seatsLeft(t, rdb))
+
+	// Confirming after the first hold expired cancels the whole booking
+	cancelled, err := ConfirmBooking(ctx, rdb, booking, now.Add(20*time.Minute))
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	assert.Equal(t, models.BookingCancelled, cancelled.Status)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+
+	// The expiry sweep cancels pending bookings too
+	third := newHold("alice", 2, now)
+	_, err = CreateHold(ctx, rdb, third)
+	require.NoError(t, err)
+	booking, err = CreateBooking(ctx, rdb, newBooking("alice", now, third))
+	require.NoError(t, err)
+
+	_, err = ExpireHolds(ctx, rdb, now.Add(20*time.Minute))
+	require.NoError(t, err)
+	booking, err = GetBooking(ctx, rdb, booking.Locator)
+	require.NoError(t, err)
+	assert.Equal(t, models.BookingCancelled, booking.Status)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+}
+
+func TestConflictingBookingTransitions(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	hold := newHold("alice", 1, now)
+	_, err := CreateHold(ctx, rdb, hold)
+	require.NoError(t, err)
+	pending, err := CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+	require.NoError(t, err)
+
+	_, err = CancelBooking(ctx, rdb, pending, now.Add(time.Minute))
+	require.NoError(t, err)
+
+	// Confirming the copy read before the cancellation fails and leaves the seats returned
+	stored, err := ConfirmBooking(ctx, rdb, pending, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrBookingClosed)
+	assert.Equal(t, models.BookingCancelled, stored.Status)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+	released, err := GetHold(ctx, rdb, hold.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldReleased, released.Status)
+
+	// So does cancelling it twice, which would give the seats back twice
+	_, err = CancelBooking(ctx, rdb, pending, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrBookingClosed)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+}
+
+func TestConcurrentConfirmAndCancel(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		first, second := newHold("alice", 1, now), newHold("alice", 1, now)
+		for _, hold := range []models.Hold{first, second} {
+			_, err := CreateHold(ctx, rdb, hold)
+			require.NoError(t, err)
+		}
+		pending, err := CreateBooking(ctx, rdb, newBooking("alice", now, first, second))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ConfirmBooking(ctx, rdb, pending, now.Add(time.Minute))
+		}()
+		go func() {
+			defer wg.Done()
+			CancelBooking(ctx, rdb, pending, now.Add(time.Minute))
+		}()
+		wg.Wait()
+
+		// Whichever ran first, the cancellation wins and every seat is back
+		booking, err := GetBooking(ctx, rdb, pending.Locator)
+		require.NoError(t, err)
+		assert.Equal(t, models.BookingCancelled, booking.Status)
+		assert.Equal(t, 5, seatsLeft(t, rdb))
+	}
+}
+
+func TestHoldBookedBeforeConfirmOrRelease(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	hold := newHold("alice", 2, now)
+	_, err := CreateHold(ctx, rdb, hold)
+	require.NoError(t, err)
+
+	// The hold is read before it gets booked, as the confirm and release handlers do
+	read, err := GetHold(ctx, rdb, hold.ID)
+	require.NoError(t, err)
+	assert.Empty(t, read.Booking)
+	pending, err := CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+	require.NoError(t, err)
+
+	booked, err := ConfirmHold(ctx, rdb, read.ID, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrHoldBooked)
+	assert.Equal(t, pending.Locator, booked.Booking)
+	assert.Equal(t, models.HoldActive, booked.Status)
+
+	_, err = ReleaseHold(ctx, rdb, read.ID, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrHoldBooked)
+	assert.Equal(t, 3, seatsLeft(t, rdb))
+
+	// The booking still owns the seats
+	confirmed, err := ConfirmBooking(ctx, rdb, pending, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, models.BookingConfirmed, confirmed.Status)
+	assert.Equal(t, 3, seatsLeft(t, rdb))
+}
+
+func TestConcurrentBookingAndHoldTransitions(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 50}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		hold := newHold("alice", 1, now)
+		_, err := CreateHold(ctx, rdb, hold)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var bookingErr, holdErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, bookingErr = CreateBooking(ctx, rdb, newBooking("alice", now, hold))
+		}()
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, holdErr = ConfirmHold(ctx, rdb, hold.ID, now.Add(time.Minute))
+			} else {
+				_, holdErr = ReleaseHold(ctx, rdb, hold.ID, now.Add(time.Minute))
+			}
+		}()
+		wg.Wait()
+
+		// Either the hold was booked first and keeps its seat for the booking, or it was
+		// confirmed or released first and can't be booked any more
+		if bookingErr == nil {
+			assert.ErrorIs(t, holdErr, ErrHoldBooked)
+		} else {
+			assert.ErrorIs(t, bookingErr, ErrHoldNotActive)
+			assert.NoError(t, holdErr)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// calendarFeedKey maps the token of a calendar feed to the user whose flights it shows.
+func calendarFeedKey(token string) string {
+	return "calendarfeed:" + token
+}
+
+// userCalendarFeedKey holds the token of the calendar feed of a user, so it can be revoked.
+func userCalendarFeedKey(owner string) string {
+	return "user:" + owner + ":calendarfeed"
+}
+
+// swapCalendarFeedScript replaces the feed token of an owner and revokes the previous one.
+// The previous token is only known once read, so its key is built from the prefix in ARGV[3].
+// Doing it in one script keeps two concurrent swaps from both leaving their token valid.
+var swapCalendarFeedScript = redis.NewScript(`
+local previous = redis.call("GET", KEYS[1])
+if previous then
+	redis.call("DEL", ARGV[3] .. previous)
+end
+redis.call("SET", KEYS[1], ARGV[1])
+redis.call("SET", KEYS[2], ARGV[2])
+return 1
+`)
+
+// CreateCalendarFeed gives an owner a new calendar feed token, revoking the previous one.
+// Calendar apps can't send an Authorization header, so the token in the feed URL is what
+// grants access to it.
+func CreateCalendarFeed(ctx context.Context, rdb *redis.Client, owner string) (string, error) {
+	b := make([]byte, 32)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	keys := []string{userCalendarFeedKey(owner), calendarFeedKey(token)}
+	if err := swapCalendarFeedScript.Run(ctx, rdb, keys, token, owner, calendarFeedKey("")).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CalendarFeedOwner returns the user a calendar feed token belongs to, or ErrNotFound.
+func CalendarFeedOwner(ctx context.Context, rdb *redis.Client, token string) (string, error) {
+	owner, err := rdb.Get(ctx, calendarFeedKey(token)).Result()
+	if err == redis.Nil {
+		return "", ErrNotFound
+	}
+	return owner, err
+}
+
+// DeleteCalendarFeed revokes the calendar feed of an owner, or returns ErrNotFound.
+func DeleteCalendarFeed(ctx context.Context, rdb *redis.Client, owner string) error {
+	token, err := rdb.GetDel(ctx, userCalendarFeedKey(owner)).Result()
+	if err == redis.Nil {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return rdb.Del(ctx, calendarFeedKey(token)).Err()
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+
+	first, err := CreateCalendarFeed(ctx, rdb, "alice")
+	require.NoError(t, err)
+	owner, err := CalendarFeedOwner(ctx, rdb, first)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", owner)
+
+	// A new token revokes the previous one
+	second, err := CreateCalendarFeed(ctx, rdb, "alice")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	_, err = CalendarFeedOwner(ctx, rdb, first)
+	assert.ErrorIs(t, err, ErrNotFound)
+	owner, err = CalendarFeedOwner(ctx, rdb, second)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", owner)
+
+	require.NoError(t, DeleteCalendarFeed(ctx, rdb, "alice"))
+	_, err = CalendarFeedOwner(ctx, rdb, second)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, DeleteCalendarFeed(ctx, rdb, "alice"), ErrNotFound)
+}
+
+func TestConcurrentCalendarFeeds(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+
+	tokens := make([]string, 20)
+	var wg sync.WaitGroup
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := CreateCalendarFeed(ctx, rdb, "alice")
+			assert.NoError(t, err)
+			tokens[i] = token
+		}()
+	}
+	wg.Wait()
+
+	// Only the token the owner ends up with is still valid
+	keys, err := rdb.Keys(ctx, calendarFeedKey("*")).Result()
+	require.NoError(t, err)
+	current, err := rdb.Get(ctx, userCalendarFeedKey("alice")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{calendarFeedKey(current)}, keys)
+	assert.Contains(t, tokens, current)
+}
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reportRetention is how long crawl reports are kept for auditing.
+const reportRetention = 30 * 24 * time.Hour
+
+func crawlReportKey(crawlID string) string {
+	return "crawl:" + crawlID + ":report"
+}
+
+// snapshotKey is the hash of the fares returned by the last crawl of a provider, keyed by FareKey.
+func snapshotKey(provider string) string {
+	return "snapshot:" + provider
+}
+
+// snapshotCrawlKey holds the ID of the crawl that produced the snapshot of a provider.
+func snapshotCrawlKey(provider string) string {
+	return "snapshot:" + provider + ":crawl"
+}
+
+// FareKey identifies one fare class of a flight.
+func FareKey(flightID string, class models.CabinClass) string {
+	return flightID + "|" + string(class)
+}
+
+// SaveCrawlReport stores the report of a crawl run.
+func SaveCrawlReport(ctx context.Context, rdb *redis.Client, report models.CrawlReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.Set(ctx, crawlReportKey(report.ID), data, reportRetention).Err()
+}
+
+// GetCrawlReport returns the report of a crawl run, or ErrNotFound.
+func GetCrawlReport(ctx context.Context, rdb *redis.Client, crawlID string) (models.CrawlReport, error) {
+	data, err := rdb.Get(ctx, crawlReportKey(crawlID)).Result()
+	if err == redis.Nil {
+		return models.CrawlReport{}, ErrNotFound
+	}
+	if err != nil {
+		return models.CrawlReport{}, err
+	}
+
+	var report models.CrawlReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return models.CrawlReport{}, fmt.Errorf("unmarshal crawl report %s: %w", crawlID, err)
+	}
+	return report, nil
+}
+
+// LastSnapshot returns the fares returned by the last crawl of a provider, keyed by FareKey,
+// and the ID of that crawl. The ID is empty if the provider was never crawled.
+func LastSnapshot(ctx context.Context, rdb *redis.Client, provider string) (string, map[string]models.Flight, error) {
+	crawlID, err := rdb.Get(ctx, snapshotCrawlKey(provider)).Result()
+	if err != nil && err != redis.Nil {
+		return "", nil, err
+	}
+
+	items, err := rdb.HGetAll(ctx, snapshotKey(provider)).Result()
+	if err != nil {
+		return "", nil, err
+	}
+
+	fares := make(map[string]models.Flight, len(items))
+	for fare, data := range items {
+		var flight models.Flight
+		if err := json.Unmarshal([]byte(data), &flight); err != nil {
+			return "", nil, fmt.Errorf("unmarshal snapshot fare %s: %w", fare, err)
+		}
+		fares[fare] = flight
+	}
+	return crawlID, fares, nil
+}
+
+// ReplaceSnapshot makes the fares of a crawl the snapshot the next crawl of the provider is compared to.
+func ReplaceSnapshot(ctx context.Context, rdb *redis.Client, provider, crawlID string, flights []models.Flight) error {
+	values := make(map[string]interface{}, len(flights))
+	for _, flight := range flights {
+		data, err := json.Marshal(flight)
+		if err != nil {
+			return fmt.Errorf("marshal error: %w", err)
+		}
+		values[FareKey(flight.ID, flight.Class)] = data
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, snapshotKey(provider))
+	if len(values) > 0 {
+		pipe.HSet(ctx, snapshotKey(provider), values)
+	}
+	pipe.Set(ctx, snapshotCrawlKey(provider), crawlID, 0)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// crawlRunsKey is the list of crawl run IDs, newest first.
+const crawlRunsKey = "crawls"
+
+// maxCrawlRuns is how many runs are kept in the crawl history.
+const maxCrawlRuns = 500
+
+func crawlRunKey(crawlID string) string {
+	return "crawl:" + crawlID
+}
+
+func crawlLockKey(provider string) string {
+	return "crawl:lock:" + provider
+}
+
+// releaseLockScript deletes a lock only if it is still held by the given owner,
+// so a run that outlived its lock can't release the lock of the next run.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AddCrawlRun stores a new crawl run at the head of the crawl history.
+func AddCrawlRun(ctx context.Context, rdb *redis.Client, run models.CrawlRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, crawlRunKey(run.ID), data, reportRetention)
+	pipe.LPush(ctx, crawlRunsKey, run.ID)
+	pipe.LTrim(ctx, crawlRunsKey, 0, maxCrawlRuns-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SaveCrawlRun replaces a crawl run added with AddCrawlRun.
+func SaveCrawlRun(ctx context.Context, rdb *redis.Client, run models.CrawlRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.Set(ctx, crawlRunKey(run.ID), data, reportRetention).Err()
+}
+
+// GetCrawlRun returns one crawl run, or ErrNotFound.
+func GetCrawlRun(ctx context.Context, rdb *redis.Client, crawlID string) (models.CrawlRun, error) {
+	data, err := rdb.Get(ctx, crawlRunKey(crawlID)).Result()
+	if err == redis.Nil {
+		return models.CrawlRun{}, ErrNotFound
+	}
+	if err != nil {
+		return models.CrawlRun{}, err
+	}
+
+	var run models.CrawlRun
+	if err := json.Unmarshal([]byte(data), &run); err != nil {
+		return models.CrawlRun{}, fmt.Errorf("unmarshal crawl run %s: %w", crawlID, err)
+	}
+	return run, nil
+}
+
+// ListCrawlRuns returns up to limit crawl runs, newest first.
+func ListCrawlRuns(ctx context.Context, rdb *redis.Client, limit int) ([]models.CrawlRun, error) {
+	ids, err := rdb.LRange(ctx, crawlRunsKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []models.CrawlRun{}
+	if len(ids) == 0 {
+		return runs, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = crawlRunKey(id)
+	}
+	items, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range items {
+		// Runs expire before they leave the history
+		data, ok := item.(string)
+		if !ok {
+			continue
+		}
+		var run models.CrawlRun
+		if err := json.Unmarshal([]byte(data), &run); err != nil {
+			return nil, fmt.Errorf("unmarshal crawl run %s: %w", ids[i], err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// AcquireCrawlLock takes the lock that prevents concurrent runs of a provider.
+// It returns false if another run holds it. The lock expires after ttl in case the
+// owner dies without releasing it.
+func AcquireCrawlLock(ctx context.Context, rdb *redis.Client, provider, owner string, ttl time.Duration) (bool, error) {
+	return rdb.SetNX(ctx, crawlLockKey(provider), owner, ttl).Result()
+}
+
+// ReleaseCrawlLock releases the lock of a provider if owner still holds it.
+func ReleaseCrawlLock(ctx context.Context, rdb *redis.Client, provider, owner string) error {
+	return releaseLockScript.Run(ctx, rdb, []string{crawlLockKey(provider)}, owner).Err()
+}
+
+func validatorsKey(provider string) string {
+	return "validators:" + provider
+}
+
+// PayloadValidators returns the validators of the last payload fetched from a provider.
+func PayloadValidators(ctx context.Context, rdb *redis.Client, provider string) (models.PayloadValidators, error) {
+	values, err := rdb.HGetAll(ctx, validatorsKey(provider)).Result()
+	if err != nil {
+		return models.PayloadValidators{}, err
+	}
+	return models.PayloadValidators{
+		ETag:         values["etag"],
+		LastModified: values["lastModified"],
+		Checksum:     values["checksum"],
+	}, nil
+}
+
+// SavePayloadValidators replaces the validators of the last payload fetched from a provider.
+func SavePayloadValidators(ctx context.Context, rdb *redis.Client, provider string, validators models.PayloadValidators) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, validatorsKey(provider))
+	pipe.HSet(ctx, validatorsKey(provider), "etag", validators.ETag, "lastModified", validators.LastModified, "checksum", validators.Checksum)
+	_, err := pipe.Exec(ctx)
+	return err
+}
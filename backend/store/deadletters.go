@@ -0,0 +1,117 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// deadLettersKey is the sorted set of dead letter IDs scored by creation time.
+const deadLettersKey = "deadletters"
+
+// deadLetterRetention is how long rejected records are kept.
+const deadLetterRetention = 30 * 24 * time.Hour
+
+func deadLetterKey(id string) string {
+	return "deadletter:" + id
+}
+
+// AddDeadLetter stores a rejected record and drops the expired ones from the index.
+func AddDeadLetter(ctx context.Context, rdb *redis.Client, letter models.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	expired := letter.CreatedAt.Add(-deadLetterRetention).UnixMilli()
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, deadLetterKey(letter.ID), data, deadLetterRetention)
+	pipe.ZAdd(ctx, deadLettersKey, redis.Z{Score: float64(letter.CreatedAt.UnixMilli()), Member: letter.ID})
+	pipe.ZRemRangeByScore(ctx, deadLettersKey, "-inf", strconv.FormatInt(expired, 10))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SaveDeadLetter replaces a dead letter added with AddDeadLetter, keeping its expiry.
+func SaveDeadLetter(ctx context.Context, rdb *redis.Client, letter models.DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.SetArgs(ctx, deadLetterKey(letter.ID), data, redis.SetArgs{KeepTTL: true}).Err()
+}
+
+// GetDeadLetter returns one dead letter, or ErrNotFound.
+func GetDeadLetter(ctx context.Context, rdb *redis.Client, id string) (models.DeadLetter, error) {
+	data, err := rdb.Get(ctx, deadLetterKey(id)).Result()
+	if err == redis.Nil {
+		return models.DeadLetter{}, ErrNotFound
+	}
+	if err != nil {
+		return models.DeadLetter{}, err
+	}
+
+	var letter models.DeadLetter
+	if err := json.Unmarshal([]byte(data), &letter); err != nil {
+		return models.DeadLetter{}, fmt.Errorf("unmarshal dead letter %s: %w", id, err)
+	}
+	return letter, nil
+}
+
+// ListDeadLetters returns up to limit dead letters, newest first. Provider and crawlID
+// restrict the list when they are not empty.
+func ListDeadLetters(ctx context.Context, rdb *redis.Client, provider, crawlID string, limit int) ([]models.DeadLetter, error) {
+	ids, err := rdb.ZRevRange(ctx, deadLettersKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []models.DeadLetter{}
+	// Fetch in pages so filtering doesn't load every record at once
+	const page = 100
+	for start := 0; start < len(ids) && len(letters) < limit; start += page {
+		end := min(start+page, len(ids))
+		keys := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, deadLetterKey(id))
+		}
+
+		items, err := rdb.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			// Records expire before they leave the index
+			data, ok := item.(string)
+			if !ok {
+				continue
+			}
+			var letter models.DeadLetter
+			if err := json.Unmarshal([]byte(data), &letter); err != nil {
+				return nil, fmt.Errorf("unmarshal dead letter %s: %w", ids[start+i], err)
+			}
+			if (provider != "" && letter.Provider != provider) || (crawlID != "" && letter.CrawlID != crawlID) {
+				continue
+			}
+			letters = append(letters, letter)
+			if len(letters) == limit {
+				break
+			}
+		}
+	}
+	return letters, nil
+}
+
+// DeleteDeadLetter removes a dead letter, e.g. once it was replayed.
+func DeleteDeadLetter(ctx context.Context, rdb *redis.Client, id string) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, deadLetterKey(id))
+	pipe.ZRem(ctx, deadLettersKey, id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	now := time.Now().UTC()
+
+	letters := []models.DeadLetter{
+		{ID: "a", Provider: "mocky", CrawlID: "crawl1", Reason: "decode error", Raw: json.RawMessage(`{"flightNumber":1}`), CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "b", Provider: "mocky", CrawlID: "crawl2", Reason: "missing flight number", Raw: json.RawMessage(`{}`), CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "c", Provider: "import", CrawlID: "crawl3", Reason: "negative price", Raw: json.RawMessage(`{}`), CreatedAt: now.Add(-time.Minute)},
+	}
+	for _, letter := range letters {
+		require.NoError(t, AddDeadLetter(ctx, rdb, letter))
+	}
+
+	ids := func(letters []models.DeadLetter) []string {
+		result := []string{}
+		for _, letter := range letters {
+			result = append(result, letter.ID)
+		}
+		return result
+	}
+	tests := []struct {
+		name     string
+		provider string
+		crawlID  string
+		limit    int
+		expected []string
+	}{
+		{name: "newest first", limit: 10, expected: []string{"c", "b", "a"}},
+		{name: "limit", limit: 2, expected: []string{"c", "b"}},
+		{name: "provider", provider: "mocky", limit: 10, expected: []string{"b", "a"}},
+		{name: "crawl", crawlID: "crawl1", limit: 10, expected: []string{"a"}},
+		{name: "provider and crawl", provider: "import", crawlID: "crawl1", limit: 10, expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listed, err := ListDeadLetters(ctx, rdb, tt.provider, tt.crawlID, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids(listed))
+		})
+	}
+
+	letter, err := GetDeadLetter(ctx, rdb, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "decode error", letter.Reason)
+	assert.JSONEq(t, `{"flightNumber":1}`, string(letter.Raw))
+
+	// Saving a replayed letter keeps its expiry
+	letter.Replays++
+	letter.Reason = "still broken"
+	require.NoError(t, SaveDeadLetter(ctx, rdb, letter))
+	letter, err = GetDeadLetter(ctx, rdb, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, letter.Replays)
+	assert.Equal(t, "still broken", letter.Reason)
+	ttl, err := rdb.TTL(ctx, deadLetterKey("a")).Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, deadLetterRetention-time.Minute)
+
+	require.NoError(t, DeleteDeadLetter(ctx, rdb, "a"))
+	_, err = GetDeadLetter(ctx, rdb, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	listed, err := ListDeadLetters(ctx, rdb, "", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b"}, ids(listed))
+}
+
+func TestDeadLettersExpire(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	now := time.Now().UTC()
+
+	old := models.DeadLetter{ID: "old", Provider: "mocky", CreatedAt: now.Add(-deadLetterRetention - time.Hour)}
+	require.NoError(t, AddDeadLetter(ctx, rdb, old))
+	require.NoError(t, rdb.Del(ctx, deadLetterKey(old.ID)).Err())
+
+	// Letters whose record expired are skipped, and dropped from the index by the next one
+	listed, err := ListDeadLetters(ctx, rdb, "", "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	require.NoError(t, AddDeadLetter(ctx, rdb, models.DeadLetter{ID: "new", Provider: "mocky", CreatedAt: now}))
+	members, err := rdb.ZRange(ctx, deadLettersKey, 0, -1).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, members)
+}
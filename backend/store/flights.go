@@ -0,0 +1,204 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FlightKey is the hash holding the latest crawled state of a flight, one field per fare class.
+func FlightKey(flightID string) string {
+	return "flight:" + flightID
+}
+
+// GetFlightState returns the latest stored state of a flight fare.
+// The second return value is false if the fare was never stored.
+func GetFlightState(ctx context.Context, rdb *redis.Client, flightID string, class models.CabinClass) (models.Flight, bool, error) {
+	data, err := rdb.HGet(ctx, FlightKey(flightID), string(class)).Result()
+	if err == redis.Nil {
+		return models.Flight{}, false, nil
+	}
+	if err != nil {
+		return models.Flight{}, false, err
+	}
+
+	var flight models.Flight
+	if err := json.Unmarshal([]byte(data), &flight); err != nil {
+		return models.Flight{}, false, fmt.Errorf("unmarshal flight %s: %w", flightID, err)
+	}
+	return flight, true, nil
+}
+
+// SaveFlightState replaces the latest stored state of a flight fare.
+func SaveFlightState(ctx context.Context, rdb *redis.Client, flight models.Flight) error {
+	data, err := json.Marshal(flight)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	key := FlightKey(flight.ID)
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, key, string(flight.Class), data)
+	if departure, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		pipe.ExpireAt(ctx, key, departure.Add(historyRetention))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// FlightStates returns the latest stored state of every fare of a flight.
+func FlightStates(ctx context.Context, rdb *redis.Client, flightID string) ([]models.Flight, error) {
+	fares, err := rdb.HGetAll(ctx, FlightKey(flightID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	flights := make([]models.Flight, 0, len(fares))
+	for class, data := range fares {
+		var flight models.Flight
+		if err := json.Unmarshal([]byte(data), &flight); err != nil {
+			return nil, fmt.Errorf("unmarshal flight %s %s: %w", flightID, class, err)
+		}
+		flights = append(flights, flight)
+	}
+	return flights, nil
+}
+
+// FlightDates returns the dates that have a flight list.
+func FlightDates(ctx context.Context, rdb *redis.Client) ([]string, error) {
+	var cursor uint64
+	var dates []string
+	for {
+		keys, newCursor, err := rdb.Scan(ctx, cursor, FlightKeyPattern, 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, keys...)
+		cursor = newCursor
+		if cursor == 0 {
+			break
+		}
+	}
+	return dates, nil
+}
+
+// FlightsByDate returns the flights departing on a date. Every crawl pushes the fares
+// again, so only the most recent entry of each fare is kept.
+func FlightsByDate(ctx context.Context, rdb *redis.Client, date string) ([]models.Flight, error) {
+	items, err := rdb.LRange(ctx, date, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	flights := make([]models.Flight, 0, len(items))
+	for _, item := range items {
+		var flight models.Flight
+		if err := json.Unmarshal([]byte(item), &flight); err != nil {
+			return nil, fmt.Errorf("unmarshal flight on %s: %w", date, err)
+		}
+		flights = append(flights, flight)
+	}
+	return LatestFares(flights), nil
+}
+
+// LatestFares keeps the first entry of each fare of a date list. Lists are newest first,
+// so it is the latest state of the fare.
+func LatestFares(flights []models.Flight) []models.Flight {
+	seen := make(map[string]bool)
+	latest := flights[:0]
+	for _, flight := range flights {
+		fare := FareKey(models.FlightID(flight), flight.Class)
+		if seen[fare] {
+			continue
+		}
+		seen[fare] = true
+		latest = append(latest, flight)
+	}
+	return latest
+}
+
+// FlightInfoKey is the string holding the schedule, status and aircraft of a flight,
+// shared by all its fares.
+func FlightInfoKey(flightID string) string {
+	return "flightinfo:" + flightID
+}
+
+// SaveFlightInfo replaces the schedule, status and aircraft of a flight on a transaction,
+// so they are stored together with the fare that reported them. Fares are stored with
+// SaveFlightState.
+func SaveFlightInfo(ctx context.Context, pipe redis.Pipeliner, flight models.FlightFares) error {
+	flight.Fares = nil
+	data, err := json.Marshal(flight)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	expiration := time.Duration(0)
+	if departure, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		expiration = time.Until(departure.Add(historyRetention))
+		if expiration <= 0 {
+			return nil
+		}
+	}
+	pipe.Set(ctx, FlightInfoKey(flight.ID), data, expiration)
+	return nil
+}
+
+// FlightInfos returns the schedule, status and aircraft of flights, without fares.
+// Flights stored before the flight part was kept separately are missing from the map.
+func FlightInfos(ctx context.Context, rdb *redis.Client, flightIDs []string) (map[string]models.FlightFares, error) {
+	infos := make(map[string]models.FlightFares)
+	if len(flightIDs) == 0 {
+		return infos, nil
+	}
+
+	keys := make([]string, len(flightIDs))
+	for i, id := range flightIDs {
+		keys[i] = FlightInfoKey(id)
+	}
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var info models.FlightFares
+		if err := json.Unmarshal([]byte(data), &info); err != nil {
+			return nil, fmt.Errorf("unmarshal flight %s: %w", flightIDs[i], err)
+		}
+		infos[flightIDs[i]] = info
+	}
+	return infos, nil
+}
+
+// GetFlight returns a flight with the latest state of each of its fares.
+// The second return value is false if no fare of the flight is stored.
+func GetFlight(ctx context.Context, rdb *redis.Client, flightID string) (models.FlightFares, bool, error) {
+	fares, err := FlightStates(ctx, rdb, flightID)
+	if err != nil {
+		return models.FlightFares{}, false, err
+	}
+	if len(fares) == 0 {
+		return models.FlightFares{}, false, nil
+	}
+
+	infos, err := FlightInfos(ctx, rdb, []string{flightID})
+	if err != nil {
+		return models.FlightFares{}, false, err
+	}
+
+	flight := models.GroupFares(fares)[0]
+	if info, ok := infos[flightID]; ok {
+		info.Fares = flight.Fares
+		flight = info
+	}
+	return flight, true, nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlightsByDateKeepsLatestFare(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+	const date = "2025-04-28"
+
+	fare := func(class models.CabinClass, price money.USD) models.Flight {
+		return models.Flight{FlightNumber: "DL123", DepartureTime: "2025-04-28T10:00:00-04:00", Class: class, PriceUSD: price}
+	}
+	// Each crawl pushes every fare again, newest first
+	for _, flight := range []models.Flight{
+		fare(models.CabinEconomy, 30000),
+		fare(models.CabinBusiness, 90000),
+		fare(models.CabinEconomy, 25000),
+	} {
+		data, err := json.Marshal(flight)
+		require.NoError(t, err)
+		require.NoError(t, rdb.LPush(ctx, date, data).Err())
+	}
+
+	flights, err := FlightsByDate(ctx, rdb, date)
+	require.NoError(t, err)
+	if assert.Len(t, flights, 2) {
+		assert.Equal(t, models.CabinEconomy, flights[0].Class)
+		assert.Equal(t, money.USD(25000), flights[0].PriceUSD)
+		assert.Equal(t, models.CabinBusiness, flights[1].Class)
+	}
+}
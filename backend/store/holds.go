@@ -0,0 +1,366 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// holdsExpiringKey is the sorted set of active hold IDs scored by their expiry in Unix
+// milliseconds, which the expiry sweep reads.
+const holdsExpiringKey = "holds:expiring"
+
+// holdRetention is how long a hold is kept after it was created, whatever its status.
+const holdRetention = 30 * 24 * time.Hour
+
+var (
+	// ErrNoInventory is returned when a fare has no seat inventory to hold from.
+	ErrNoInventory = errors.New("no seat inventory for this fare")
+	// ErrNotEnoughSeats is returned when a fare has fewer seats left than requested.
+	ErrNotEnoughSeats = errors.New("not enough seats available")
+	// ErrHoldNotActive is returned when confirming or releasing a hold that is past that step.
+	ErrHoldNotActive = errors.New("hold is not active")
+)
+
+// InventoryKey is the hash holding the seats left for sale of a flight, one field per fare class.
+func InventoryKey(flightID string) string {
+	return "inventory:" + flightID
+}
+
+func holdKey(holdID string) string {
+	return "hold:" + holdID
+}
+
+func holdsOwnerKey(owner string) string {
+	return "holds:owner:" + owner
+}
+
+// createHoldScript takes the seats of a hold from the inventory and stores the hold, or
+// returns -1 when the fare has no inventory and -2 when it has too few seats left.
+// Checking and decrementing in one script is what keeps concurrent holds from overselling.
+var createHoldScript = redis.NewScript(`
+local available = redis.call("HGET", KEYS[1], ARGV[1])
+if not available then
+	return -1
+end
+local seats = tonumber(ARGV[2])
+if tonumber(available) < seats then
+	return -2
+end
+local left = redis.call("HINCRBY", KEYS[1], ARGV[1], -seats)
+redis.call("HSET", KEYS[2], unpack(ARGV, 6))
+redis.call("PEXPIRE", KEYS[2], ARGV[5])
+redis.call("ZADD", KEYS[3], ARGV[3], ARGV[4])
+redis.call("SADD", KEYS[4], ARGV[4])
+return left
+`)
+
+// confirmHoldScript confirms an active hold. A hold past its expiry is expired instead and
+// its seats go back to the inventory. It returns {1, previous status} when confirmed,
+// {-1, status} when the hold belongs to a booking and {0, status} otherwise, with an empty
+// status if the hold does not exist. The seats of a booked hold follow its booking.
+var confirmHoldScript = redis.NewScript(`
+local status = redis.call("HGET", KEYS[1], "status")
+if not status then
+	return {0, ""}
+end
+if redis.call("HEXISTS", KEYS[1], "booking") == 1 then
+	return {-1, status}
+end
+if status ~= "held" then
+	return {0, status}
+end
+local expiresAt = redis.call("ZSCORE", KEYS[3], ARGV[3])
+if not expiresAt or tonumber(expiresAt) <= tonumber(ARGV[1]) then
+	if redis.call("EXISTS", KEYS[2]) == 1 then
+		redis.call("HINCRBY", KEYS[2], redis.call("HGET", KEYS[1], "class"), redis.call("HGET", KEYS[1], "seats"))
+	end
+	redis.call("HSET", KEYS[1], "status", "expired", "releasedAt", ARGV[2])
+	redis.call("ZREM", KEYS[3], ARGV[3])
+	return {0, "expired"}
+end
+redis.call("HSET", KEYS[1], "status", "confirmed", "confirmedAt", ARGV[2])
+redis.call("ZREM", KEYS[3], ARGV[3])
+return {1, status}
+`)
+
+// releaseHoldScript gives the seats of a hold back to the inventory. ARGV[1] is the new
+// status: "released" applies to active and confirmed holds that don't belong to a booking,
+// "expired" only to active holds past their expiry. It returns {1, previous status} when
+// the seats went back, {-1, status} when releasing a booked hold and {0, status} otherwise,
+// with an empty status if the hold does not exist.
+var releaseHoldScript = redis.NewScript(`
+local status = redis.call("HGET", KEYS[1], "status")
+if not status then
+	return {0, ""}
+end
+if ARGV[1] == "released" and redis.call("HEXISTS", KEYS[1], "booking") == 1 then
+	return {-1, status}
+end
+if ARGV[1] == "expired" then
+	local expiresAt = redis.call("ZSCORE", KEYS[3], ARGV[4])
+	if status ~= "held" or not expiresAt or tonumber(expiresAt) > tonumber(ARGV[2]) then
+		return {0, status}
+	end
+elseif status ~= "held" and status ~= "confirmed" then
+	return {0, status}
+end
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	redis.call("HINCRBY", KEYS[2], redis.call("HGET", KEYS[1], "class"), redis.call("HGET", KEYS[1], "seats"))
+end
+redis.call("HSET", KEYS[1], "status", ARGV[1], "releasedAt", ARGV[3])
+redis.call("ZREM", KEYS[3], ARGV[4])
+return {1, status}
+`)
+
+// SeedInventory sets the seats left of a fare from the seatsRemaining sent by the provider,
+// unless the fare already has an inventory: once holds are taken from it, the inventory
+// is ours to keep.
+func SeedInventory(ctx context.Context, rdb *redis.Client, flight models.Flight) error {
+	if flight.SeatsRemaining == nil {
+		return nil
+	}
+
+	key := InventoryKey(flight.ID)
+	pipe := rdb.TxPipeline()
+	pipe.HSetNX(ctx, key, string(flight.Class), *flight.SeatsRemaining)
+	if departure, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		pipe.ExpireAt(ctx, key, departure.Add(historyRetention))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SetInventory replaces the seats left of the given fare classes of a flight.
+func SetInventory(ctx context.Context, rdb *redis.Client, inventory models.Inventory) error {
+	if len(inventory.Seats) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(inventory.Seats))
+	for class, seats := range inventory.Seats {
+		values[string(class)] = seats
+	}
+	return rdb.HSet(ctx, InventoryKey(inventory.FlightID), values).Err()
+}
+
+// GetInventory returns the seats left of every fare class of a flight.
+func GetInventory(ctx context.Context, rdb *redis.Client, flightID string) (models.Inventory, error) {
+	values, err := rdb.HGetAll(ctx, InventoryKey(flightID)).Result()
+	if err != nil {
+		return models.Inventory{}, err
+	}
+
+	inventory := models.Inventory{FlightID: flightID, Seats: make(map[models.CabinClass]int, len(values))}
+	for class, value := range values {
+		seats, err := strconv.Atoi(value)
+		if err != nil {
+			return models.Inventory{}, fmt.Errorf("invalid inventory of %s %s: %q", flightID, class, value)
+		}
+		inventory.Seats[models.CabinClass(class)] = seats
+	}
+	return inventory, nil
+}
+
+// CreateHold takes the seats of a new hold from the inventory of its fare and stores the
+// hold. It returns the seats left, ErrNoInventory or ErrNotEnoughSeats.
+func CreateHold(ctx context.Context, rdb *redis.Client, hold models.Hold) (int, error) {
+	keys := []string{InventoryKey(hold.FlightID), holdKey(hold.ID), holdsExpiringKey, holdsOwnerKey(hold.Owner)}
+	args := []interface{}{string(hold.Class), hold.Seats, hold.ExpiresAt.UnixMilli(), hold.ID, holdRetention.Milliseconds()}
+	args = append(args, holdFields(hold)...)
+
+	left, err := createHoldScript.Run(ctx, rdb, keys, args...).Int()
+	if err != nil {
+		return 0, err
+	}
+	switch left {
+	case -1:
+		return 0, ErrNoInventory
+	case -2:
+		return 0, ErrNotEnoughSeats
+	}
+	return left, nil
+}
+
+// GetHold returns one hold, or ErrNotFound.
+func GetHold(ctx context.Context, rdb *redis.Client, holdID string) (models.Hold, error) {
+	values, err := rdb.HGetAll(ctx, holdKey(holdID)).Result()
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if len(values) == 0 {
+		return models.Hold{}, ErrNotFound
+	}
+	return parseHold(values)
+}
+
+// ListHolds returns the holds of an owner, newest first. Holds past their retention are
+// dropped from the owner index.
+func ListHolds(ctx context.Context, rdb *redis.Client, owner string) ([]models.Hold, error) {
+	ids, err := rdb.SMembers(ctx, holdsOwnerKey(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	holds := []models.Hold{}
+	for _, id := range ids {
+		hold, err := GetHold(ctx, rdb, id)
+		if errors.Is(err, ErrNotFound) {
+			rdb.SRem(ctx, holdsOwnerKey(owner), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	sort.Slice(holds, func(i, j int) bool { return holds[i].CreatedAt.After(holds[j].CreatedAt) })
+	return holds, nil
+}
+
+// ConfirmHold confirms an active hold, so its seats are sold. A hold past its expiry is
+// expired on the spot. It returns the updated hold, ErrNotFound, ErrHoldBooked or
+// ErrHoldNotActive.
+func ConfirmHold(ctx context.Context, rdb *redis.Client, holdID string, now time.Time) (models.Hold, error) {
+	hold, err := GetHold(ctx, rdb, holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	keys := []string{holdKey(holdID), InventoryKey(hold.FlightID), holdsExpiringKey}
+	result, err := confirmHoldScript.Run(ctx, rdb, keys, now.UnixMilli(), now.UTC().Format(time.RFC3339Nano), holdID).Slice()
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return holdScriptResult(ctx, rdb, holdID, result)
+}
+
+// ReleaseHold gives the seats of an active or confirmed hold back to the inventory.
+// It returns the updated hold, ErrNotFound, ErrHoldBooked or ErrHoldNotActive.
+func ReleaseHold(ctx context.Context, rdb *redis.Client, holdID string, now time.Time) (models.Hold, error) {
+	return releaseHold(ctx, rdb, holdID, models.HoldReleased, now)
+}
+
+// ExpireHolds gives back the seats of the active holds that expired by now and returns how
+// many were expired. Every replica may sweep: a hold is only expired once. Pending bookings
+// of an expired hold are cancelled.
+func ExpireHolds(ctx context.Context, rdb *redis.Client, now time.Time) (int, error) {
+	ids, err := rdb.ZRangeByScore(ctx, holdsExpiringKey, &redis.ZRangeBy{Min: "-inf", Max: strconv.FormatInt(now.UnixMilli(), 10)}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		hold, err := releaseHold(ctx, rdb, id, models.HoldExpired, now)
+		switch {
+		case err == nil:
+			expired++
+			if hold.Booking != "" {
+				if err := expireBooking(ctx, rdb, hold.Booking, now); err != nil {
+					return expired, fmt.Errorf("expire booking %s: %w", hold.Booking, err)
+				}
+			}
+		case errors.Is(err, ErrNotFound):
+			// The hold is past its retention: nothing to give back
+			rdb.ZRem(ctx, holdsExpiringKey, id)
+		case errors.Is(err, ErrHoldNotActive):
+			// Confirmed or released in the meantime
+		default:
+			return expired, fmt.Errorf("expire hold %s: %w", id, err)
+		}
+	}
+	return expired, nil
+}
+
+func releaseHold(ctx context.Context, rdb *redis.Client, holdID string, status models.HoldStatus, now time.Time) (models.Hold, error) {
+	hold, err := GetHold(ctx, rdb, holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+
+	keys := []string{holdKey(holdID), InventoryKey(hold.FlightID), holdsExpiringKey}
+	result, err := releaseHoldScript.Run(ctx, rdb, keys, string(status), now.UnixMilli(), now.UTC().Format(time.RFC3339Nano), holdID).Slice()
+	if err != nil {
+		return models.Hold{}, err
+	}
+	return holdScriptResult(ctx, rdb, holdID, result)
+}
+
+// holdScriptResult reads the {applied, status} reply of the confirm and release scripts
+// and returns the hold as it is now.
+func holdScriptResult(ctx context.Context, rdb *redis.Client, holdID string, result []interface{}) (models.Hold, error) {
+	if len(result) != 2 {
+		return models.Hold{}, fmt.Errorf("unexpected script reply %v", result)
+	}
+	applied, _ := result[0].(int64)
+	status, _ := result[1].(string)
+	if status == "" {
+		return models.Hold{}, ErrNotFound
+	}
+
+	hold, err := GetHold(ctx, rdb, holdID)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	switch applied {
+	case -1:
+		return hold, fmt.Errorf("%w %s", ErrHoldBooked, hold.Booking)
+	case 0:
+		return hold, fmt.Errorf("%w: it is %s", ErrHoldNotActive, hold.Status)
+	}
+	return hold, nil
+}
+
+// holdFields flattens a hold into hash fields, which the scripts read and update.
+func holdFields(hold models.Hold) []interface{} {
+	return []interface{}{
+		"id", hold.ID,
+		"owner", hold.Owner,
+		"flightId", hold.FlightID,
+		"class", string(hold.Class),
+		"seats", hold.Seats,
+		"status", string(hold.Status),
+		"createdAt", hold.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"expiresAt", hold.ExpiresAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func parseHold(values map[string]string) (models.Hold, error) {
+	hold := models.Hold{
+		ID:       values["id"],
+		Owner:    values["owner"],
+		FlightID: values["flightId"],
+		Class:    models.CabinClass(values["class"]),
+		Status:   models.HoldStatus(values["status"]),
+		Booking:  values["booking"],
+	}
+
+	var err error
+	if hold.Seats, err = strconv.Atoi(values["seats"]); err != nil {
+		return models.Hold{}, fmt.Errorf("invalid seats in hold %s: %w", hold.ID, err)
+	}
+	if hold.CreatedAt, err = time.Parse(time.RFC3339Nano, values["createdAt"]); err != nil {
+		return models.Hold{}, fmt.Errorf("invalid createdAt in hold %s: %w", hold.ID, err)
+	}
+	if hold.ExpiresAt, err = time.Parse(time.RFC3339Nano, values["expiresAt"]); err != nil {
+		return models.Hold{}, fmt.Errorf("invalid expiresAt in hold %s: %w", hold.ID, err)
+	}
+	for field, target := range map[string]**time.Time{"confirmedAt": &hold.ConfirmedAt, "releasedAt": &hold.ReleasedAt} {
+		value, ok := values[field]
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return models.Hold{}, fmt.Errorf("invalid %s in hold %s: %w", field, hold.ID, err)
+		}
+		*target = &at
+	}
+	return hold, nil
+}
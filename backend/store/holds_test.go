@@ -0,0 +1,167 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFlight = "DL123-2025-04-28"
+
+func newTestRedis(t *testing.T) *redis.Client {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+func newHold(owner string, seats int, now time.Time) models.Hold {
+	return models.Hold{
+		ID:        NewID(),
+		Owner:     owner,
+		FlightID:  testFlight,
+		Class:     models.CabinEconomy,
+		Seats:     seats,
+		Status:    models.HoldActive,
+		CreatedAt: now,
+		ExpiresAt: now.Add(15 * time.Minute),
+	}
+}
+
+func seatsLeft(t *testing.T, rdb *redis.Client) int {
+	inventory, err := GetInventory(context.Background(), rdb, testFlight)
+	require.NoError(t, err)
+	return inventory.Seats[models.CabinEconomy]
+}
+
+func TestConcurrentHoldsDoNotOversell(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 10}}))
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var held, refused int
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// A mix of one and two seat holds racing for the same ten seats
+			_, err := CreateHold(ctx, rdb, newHold(fmt.Sprintf("user%d", i), 1+i%2, now))
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				held += 1 + i%2
+			case errors.Is(err, ErrNotEnoughSeats):
+				refused++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, held+seatsLeft(t, rdb))
+	assert.GreaterOrEqual(t, seatsLeft(t, rdb), 0)
+	assert.LessOrEqual(t, held, 10)
+	assert.NotZero(t, refused)
+}
+
+func TestHoldLifecycle(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err := CreateHold(ctx, rdb, models.Hold{ID: NewID(), Owner: "alice", FlightID: testFlight, Class: models.CabinBusiness, Seats: 1, Status: models.HoldActive, CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	assert.ErrorIs(t, err, ErrNoInventory)
+
+	_, err = CreateHold(ctx, rdb, newHold("alice", 6, now))
+	assert.ErrorIs(t, err, ErrNotEnoughSeats)
+	assert.Equal(t, 5, seatsLeft(t, rdb))
+
+	// Confirmed holds keep their seats
+	confirmed := newHold("alice", 2, now)
+	left, err := CreateHold(ctx, rdb, confirmed)
+	require.NoError(t, err)
+	assert.Equal(t, 3, left)
+
+	hold, err := ConfirmHold(ctx, rdb, confirmed.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldConfirmed, hold.Status)
+	assert.NotNil(t, hold.ConfirmedAt)
+	_, err = ConfirmHold(ctx, rdb, confirmed.ID, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+
+	// Released holds give their seats back, once
+	released := newHold("alice", 2, now)
+	_, err = CreateHold(ctx, rdb, released)
+	require.NoError(t, err)
+	assert.Equal(t, 1, seatsLeft(t, rdb))
+
+	hold, err = ReleaseHold(ctx, rdb, released.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldReleased, hold.Status)
+	assert.Equal(t, 3, seatsLeft(t, rdb))
+	_, err = ReleaseHold(ctx, rdb, released.ID, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	assert.Equal(t, 3, seatsLeft(t, rdb))
+
+	holds, err := ListHolds(ctx, rdb, "alice")
+	require.NoError(t, err)
+	assert.Len(t, holds, 2)
+
+	_, err = ReleaseHold(ctx, rdb, "missing", now)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestExpireHolds(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	require.NoError(t, SetInventory(ctx, rdb, models.Inventory{FlightID: testFlight, Seats: map[models.CabinClass]int{models.CabinEconomy: 5}}))
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	expiring := newHold("alice", 2, now)
+	late := newHold("bob", 1, now)
+	confirmed := newHold("carol", 1, now)
+	for _, hold := range []models.Hold{expiring, late, confirmed} {
+		_, err := CreateHold(ctx, rdb, hold)
+		require.NoError(t, err)
+	}
+	_, err := ConfirmHold(ctx, rdb, confirmed.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, seatsLeft(t, rdb))
+
+	// Nothing expires before its time
+	expired, err := ExpireHolds(ctx, rdb, now.Add(10*time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	// Confirming too late expires the hold instead
+	hold, err := ConfirmHold(ctx, rdb, late.ID, now.Add(20*time.Minute))
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	assert.Equal(t, models.HoldExpired, hold.Status)
+	assert.Equal(t, 2, seatsLeft(t, rdb))
+
+	expired, err = ExpireHolds(ctx, rdb, now.Add(20*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, 4, seatsLeft(t, rdb))
+
+	hold, err = GetHold(ctx, rdb, expiring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldExpired, hold.Status)
+	assert.NotNil(t, hold.ReleasedAt)
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// historyRetention is how long price and status history are kept after the flight departs.
+const historyRetention = 30 * 24 * time.Hour
+
+// PriceKey is the sorted set holding the price observations of a flight, scored by observation time.
+func PriceKey(flightID string) string {
+	return "prices:" + flightID
+}
+
+// RecordPrice appends the current price of a flight fare to its price history.
+func RecordPrice(ctx context.Context, rdb *redis.Client, flight models.Flight, observedAt time.Time) error {
+	observation := models.PriceObservation{
+		Class:      flight.Class,
+		PriceUSD:   flight.PriceUSD,
+		Price:      flight.Price,
+		ObservedAt: observedAt.UTC(),
+	}
+
+	data, err := json.Marshal(observation)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	key := PriceKey(flight.ID)
+	pipe := rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(observedAt.UnixMilli()), Member: data})
+
+	// Keep the history for a while after departure, then let Redis drop it
+	if departure, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		pipe.ExpireAt(ctx, key, departure.Add(historyRetention))
+	}
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// PriceHistory returns every price observation of a flight, oldest first.
+func PriceHistory(ctx context.Context, rdb *redis.Client, flightID string) ([]models.PriceObservation, error) {
+	members, err := rdb.ZRange(ctx, PriceKey(flightID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	observations := make([]models.PriceObservation, 0, len(members))
+	for _, member := range members {
+		var observation models.PriceObservation
+		if err := json.Unmarshal([]byte(member), &observation); err != nil {
+			return nil, fmt.Errorf("unmarshal price observation: %w", err)
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, nil
+}
+
+// SummarizePrices computes min, max, current and change since first seen for
+// observations of a single fare. The observations must be sorted oldest first.
+func SummarizePrices(observations []models.PriceObservation) models.PriceSummary {
+	if len(observations) == 0 {
+		return models.PriceSummary{}
+	}
+
+	first := observations[0]
+	last := observations[len(observations)-1]
+	summary := models.PriceSummary{
+		Observations:    len(observations),
+		FirstSeen:       first.ObservedAt,
+		LastSeen:        last.ObservedAt,
+		FirstPriceUSD:   first.PriceUSD,
+		CurrentPriceUSD: last.PriceUSD,
+		MinPriceUSD:     first.PriceUSD,
+		MaxPriceUSD:     first.PriceUSD,
+	}
+
+	for _, observation := range observations {
+		summary.MinPriceUSD = min(summary.MinPriceUSD, observation.PriceUSD)
+		summary.MaxPriceUSD = max(summary.MaxPriceUSD, observation.PriceUSD)
+	}
+
+	summary.ChangeUSD = last.PriceUSD - first.PriceUSD
+	if first.PriceUSD != 0 {
+		summary.ChangePercent = math.Round(float64(summary.ChangeUSD)/float64(first.PriceUSD)*10000) / 100
+	}
+
+	summary.InCurrency = summarizeInCurrency(observations)
+	return summary
+}
+
+// summarizeInCurrency summarizes observations in minor units of their currency.
+// It returns nil if they are not all in the same currency.
+func summarizeInCurrency(observations []models.PriceObservation) *models.CurrencySummary {
+	first := observations[0].Price
+	last := observations[len(observations)-1].Price
+	lowest, highest := first, first
+
+	for _, observation := range observations {
+		lower, err := observation.Price.Cmp(lowest)
+		if err != nil {
+			return nil
+		}
+		if lower < 0 {
+			lowest = observation.Price
+		}
+		if higher, _ := observation.Price.Cmp(highest); higher > 0 {
+			highest = observation.Price
+		}
+	}
+
+	change, err := last.Sub(first)
+	if err != nil {
+		return nil
+	}
+	return &models.CurrencySummary{
+		Currency:     first.Currency,
+		FirstPrice:   first.Amount,
+		CurrentPrice: last.Amount,
+		MinPrice:     lowest.Amount,
+		MaxPrice:     highest.Amount,
+		Change:       change.Amount,
+	}
+}
+
+// GroupPricesByClass splits observations per fare class, keeping their order.
+// Classes are returned sorted so responses are stable.
+func GroupPricesByClass(observations []models.PriceObservation) ([]models.CabinClass, map[models.CabinClass][]models.PriceObservation) {
+	byClass := make(map[models.CabinClass][]models.PriceObservation)
+	for _, observation := range observations {
+		byClass[observation.Class] = append(byClass[observation.Class], observation)
+	}
+
+	classes := make([]models.CabinClass, 0, len(byClass))
+	for class := range byClass {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+
+	return classes, byClass
+}
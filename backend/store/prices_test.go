@@ -0,0 +1,75 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizePrices(t *testing.T) {
+	start := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	observations := []models.PriceObservation{
+		{Class: "Economy", PriceUSD: 50000, Price: money.New(50000, "USD"), ObservedAt: start},
+		{Class: "Economy", PriceUSD: 42050, Price: money.New(42050, "USD"), ObservedAt: start.Add(30 * time.Minute)},
+		{Class: "Economy", PriceUSD: 61000, Price: money.New(61000, "USD"), ObservedAt: start.Add(time.Hour)},
+		{Class: "Economy", PriceUSD: 45000, Price: money.New(45000, "USD"), ObservedAt: start.Add(90 * time.Minute)},
+	}
+
+	summary := SummarizePrices(observations)
+
+	assert.Equal(t, 4, summary.Observations)
+	assert.Equal(t, start, summary.FirstSeen)
+	assert.Equal(t, start.Add(90*time.Minute), summary.LastSeen)
+	assert.Equal(t, money.USD(50000), summary.FirstPriceUSD)
+	assert.Equal(t, money.USD(45000), summary.CurrentPriceUSD)
+	assert.Equal(t, money.USD(42050), summary.MinPriceUSD)
+	assert.Equal(t, money.USD(61000), summary.MaxPriceUSD)
+	assert.Equal(t, money.USD(-5000), summary.ChangeUSD)
+	assert.Equal(t, -10.0, summary.ChangePercent)
+
+	assert.Equal(t, models.PriceSummary{}, SummarizePrices(nil))
+}
+
+func TestSummarizePricesInCurrency(t *testing.T) {
+	start := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	observations := []models.PriceObservation{
+		{Class: "Economy", PriceUSD: 54000, Price: money.New(50000, "EUR"), ObservedAt: start},
+		{Class: "Economy", PriceUSD: 50000, Price: money.New(46000, "EUR"), ObservedAt: start.Add(time.Hour)},
+		{Class: "Economy", PriceUSD: 56000, Price: money.New(52000, "EUR"), ObservedAt: start.Add(2 * time.Hour)},
+	}
+
+	summary := SummarizePrices(observations)
+	assert.Equal(t, &models.CurrencySummary{
+		Currency:     "EUR",
+		FirstPrice:   50000,
+		CurrentPrice: 52000,
+		MinPrice:     46000,
+		MaxPrice:     52000,
+		Change:       2000,
+	}, summary.InCurrency)
+
+	mixed := append(observations, models.PriceObservation{Class: "Economy", PriceUSD: 53000, Price: money.New(53000, "USD"), ObservedAt: start.Add(3 * time.Hour)})
+	assert.Nil(t, SummarizePrices(mixed).InCurrency)
+}
+
+func TestPriceObservationJSON(t *testing.T) {
+	// Observations recorded before prices had a currency only have priceUSD
+	var legacy models.PriceObservation
+	require.NoError(t, json.Unmarshal([]byte(`{"class":"Economy","priceUSD":420.5,"observedAt":"2025-04-01T10:00:00Z"}`), &legacy))
+	assert.Equal(t, money.USD(42050), legacy.PriceUSD)
+	assert.Equal(t, money.New(42050, "USD"), legacy.Price)
+
+	observation := models.PriceObservation{Class: "Economy", PriceUSD: 46802, Price: money.New(45000, "EUR"), ObservedAt: time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)}
+	data, err := json.Marshal(observation)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"class":"Economy","priceUSD":468.02,"priceAmount":45000,"currency":"EUR","observedAt":"2025-04-01T10:00:00Z"}`, string(data))
+
+	var decoded models.PriceObservation
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, observation, decoded)
+}
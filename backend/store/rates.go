@@ -0,0 +1,45 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// exchangeRatesKey is the hash of the exchange rates loaded by the admins, keyed by
+// currency and effective date, e.g. "EUR|2025-01-01".
+const exchangeRatesKey = "exchangerates"
+
+// ExchangeRates returns the exchange rates loaded by the admins.
+func ExchangeRates(ctx context.Context, rdb *redis.Client) ([]models.ExchangeRate, error) {
+	values, err := rdb.HGetAll(ctx, exchangeRatesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(values))
+	for field, value := range values {
+		code, date, ok := strings.Cut(field, "|")
+		perUSD, err := strconv.ParseFloat(value, 64)
+		if !ok || err != nil {
+			continue
+		}
+		rates = append(rates, models.ExchangeRate{Currency: code, EffectiveDate: date, PerUSD: perUSD})
+	}
+	return rates, nil
+}
+
+// SaveExchangeRates adds exchange rates, replacing the rates of the same currency and effective date.
+func SaveExchangeRates(ctx context.Context, rdb *redis.Client, rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(rates))
+	for _, rate := range rates {
+		values[rate.Currency+"|"+rate.EffectiveDate] = strconv.FormatFloat(rate.PerUSD, 'f', -1, 64)
+	}
+	return rdb.HSet(ctx, exchangeRatesKey, values).Err()
+}
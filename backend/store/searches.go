@@ -0,0 +1,73 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// savedSearchesKey is the hash of the saved searches of a user, keyed by search ID.
+func savedSearchesKey(owner string) string {
+	return "user:" + owner + ":searches"
+}
+
+// SaveSearch creates or replaces a saved search of its owner.
+func SaveSearch(ctx context.Context, rdb *redis.Client, search models.SavedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.HSet(ctx, savedSearchesKey(search.Owner), search.ID, data).Err()
+}
+
+// GetSearch returns one saved search of an owner, or ErrNotFound.
+func GetSearch(ctx context.Context, rdb *redis.Client, owner, searchID string) (models.SavedSearch, error) {
+	data, err := rdb.HGet(ctx, savedSearchesKey(owner), searchID).Result()
+	if err == redis.Nil {
+		return models.SavedSearch{}, ErrNotFound
+	}
+	if err != nil {
+		return models.SavedSearch{}, err
+	}
+
+	var search models.SavedSearch
+	if err := json.Unmarshal([]byte(data), &search); err != nil {
+		return models.SavedSearch{}, fmt.Errorf("unmarshal search %s: %w", searchID, err)
+	}
+	return search, nil
+}
+
+// ListSearches returns the saved searches of an owner, newest first.
+func ListSearches(ctx context.Context, rdb *redis.Client, owner string) ([]models.SavedSearch, error) {
+	all, err := rdb.HGetAll(ctx, savedSearchesKey(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]models.SavedSearch, 0, len(all))
+	for id, data := range all {
+		var search models.SavedSearch
+		if err := json.Unmarshal([]byte(data), &search); err != nil {
+			return nil, fmt.Errorf("unmarshal search %s: %w", id, err)
+		}
+		searches = append(searches, search)
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].CreatedAt.After(searches[j].CreatedAt) })
+	return searches, nil
+}
+
+// DeleteSearch removes a saved search of an owner, or returns ErrNotFound.
+func DeleteSearch(ctx context.Context, rdb *redis.Client, owner, searchID string) error {
+	deleted, err := rdb.HDel(ctx, savedSearchesKey(owner), searchID).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
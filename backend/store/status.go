@@ -0,0 +1,69 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StatusKey is the list holding the status timeline of a flight, oldest first.
+func StatusKey(flightID string) string {
+	return "status:" + flightID
+}
+
+// CurrentStatus returns the last status recorded for a flight.
+// The second return value is false if no status was ever recorded.
+func CurrentStatus(ctx context.Context, rdb *redis.Client, flightID string) (models.FlightStatus, bool, error) {
+	last, err := rdb.LIndex(ctx, StatusKey(flightID), -1).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	var change models.StatusChange
+	if err := json.Unmarshal([]byte(last), &change); err != nil {
+		return "", false, fmt.Errorf("unmarshal status change: %w", err)
+	}
+	return change.To, true, nil
+}
+
+// RecordStatusChange appends a status change to the flight timeline on a transaction,
+// so it is stored together with the fare that reported it.
+// The timeline expires with the price history, some time after departure.
+func RecordStatusChange(ctx context.Context, pipe redis.Pipeliner, flight models.Flight, change models.StatusChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	key := StatusKey(flight.ID)
+	pipe.RPush(ctx, key, data)
+	if departure, err := time.Parse(time.RFC3339, flight.DepartureTime); err == nil {
+		pipe.ExpireAt(ctx, key, departure.Add(historyRetention))
+	}
+	return nil
+}
+
+// StatusHistory returns the status timeline of a flight, oldest first.
+func StatusHistory(ctx context.Context, rdb *redis.Client, flightID string) ([]models.StatusChange, error) {
+	items, err := rdb.LRange(ctx, StatusKey(flightID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.StatusChange, 0, len(items))
+	for _, item := range items {
+		var change models.StatusChange
+		if err := json.Unmarshal([]byte(item), &change); err != nil {
+			return nil, fmt.Errorf("unmarshal status change: %w", err)
+		}
+		history = append(history, change)
+	}
+	return history, nil
+}
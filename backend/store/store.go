@@ -0,0 +1,20 @@
+// Package store holds the Redis key layout and the helpers shared by the crawlers,
+// which write flight data, and the handlers, which read it.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// FlightKeyPattern matches the per-date lists holding the flights, e.g. 2025-04-28.
+// Scans over flights must use it so they skip the other keys the API stores.
+const FlightKeyPattern = "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]"
+
+// NewID returns a random identifier for records such as alerts and deliveries.
+func NewID() string {
+	b := make([]byte, 8)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ruleSeveritiesKey is the hash of the validation rule severities set by the admins, keyed by rule name.
+const ruleSeveritiesKey = "validation:severities"
+
+// ruleCountersKey is the hash of the number of records that broke each validation rule.
+const ruleCountersKey = "validation:violations"
+
+// RuleSeverities returns the severities set by the admins, keyed by rule name.
+func RuleSeverities(ctx context.Context, rdb *redis.Client) (map[string]string, error) {
+	return rdb.HGetAll(ctx, ruleSeveritiesKey).Result()
+}
+
+// SetRuleSeverity changes the severity of a validation rule.
+func SetRuleSeverity(ctx context.Context, rdb *redis.Client, rule, severity string) error {
+	return rdb.HSet(ctx, ruleSeveritiesKey, rule, severity).Err()
+}
+
+// IncrementRuleCounters adds violations to the counters of each validation rule.
+func IncrementRuleCounters(ctx context.Context, rdb *redis.Client, violations map[string]int) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	pipe := rdb.Pipeline()
+	for rule, count := range violations {
+		pipe.HIncrBy(ctx, ruleCountersKey, rule, int64(count))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RuleCounters returns the number of records that broke each validation rule.
+func RuleCounters(ctx context.Context, rdb *redis.Client) (map[string]int, error) {
+	values, err := rdb.HGetAll(ctx, ruleCountersKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]int, len(values))
+	for rule, value := range values {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		counters[rule] = count
+	}
+	return counters, nil
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// watchlistKey is the hash of the flights a user watches, keyed by watchlist entry ID.
+func watchlistKey(owner string) string {
+	return "user:" + owner + ":watchlist"
+}
+
+// WatchFlight adds a flight fare to the watchlist of its owner.
+func WatchFlight(ctx context.Context, rdb *redis.Client, watched models.WatchedFlight) error {
+	data, err := json.Marshal(watched)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return rdb.HSet(ctx, watchlistKey(watched.Owner), watched.ID, data).Err()
+}
+
+// UnwatchFlight removes an entry from the watchlist of an owner, or returns ErrNotFound.
+func UnwatchFlight(ctx context.Context, rdb *redis.Client, owner, entryID string) error {
+	deleted, err := rdb.HDel(ctx, watchlistKey(owner), entryID).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// WatchedFlights returns the flight fares an owner watches, in departure order.
+func WatchedFlights(ctx context.Context, rdb *redis.Client, owner string) ([]models.WatchedFlight, error) {
+	all, err := rdb.HGetAll(ctx, watchlistKey(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make([]models.WatchedFlight, 0, len(all))
+	for id, data := range all {
+		var flight models.WatchedFlight
+		if err := json.Unmarshal([]byte(data), &flight); err != nil {
+			return nil, fmt.Errorf("unmarshal watched flight %s: %w", id, err)
+		}
+		watched = append(watched, flight)
+	}
+	sort.Slice(watched, func(i, j int) bool {
+		if watched[i].DepartureTime != watched[j].DepartureTime {
+			return watched[i].DepartureTime < watched[j].DepartureTime
+		}
+		return watched[i].Class < watched[j].Class
+	})
+	return watched, nil
+}
+
+// Watchlist returns the flight fares an owner watches with their current status and the
+// price changes seen since each was added.
+func Watchlist(ctx context.Context, rdb *redis.Client, owner string) ([]models.WatchlistEntry, error) {
+	watched, err := WatchedFlights(ctx, rdb, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	flightIDs := make([]string, len(watched))
+	for i, flight := range watched {
+		flightIDs[i] = flight.FlightID
+	}
+	infos, err := FlightInfos(ctx, rdb, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.WatchlistEntry, 0, len(watched))
+	for _, flight := range watched {
+		fare, found, err := GetFlightState(ctx, rdb, flight.FlightID, flight.Class)
+		if err != nil {
+			return nil, err
+		}
+		history, err := PriceHistory(ctx, rdb, flight.FlightID)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := models.WatchlistEntry{WatchedFlight: flight, Status: flight.AddedStatus, PriceUSD: flight.AddedPriceUSD, Gone: !found}
+		if found {
+			entry.Status = fare.Status
+			entry.PriceUSD = fare.PriceUSD
+		}
+		// The flight part carries the status of every fare, kept up to date by the crawler
+		if info, ok := infos[flight.FlightID]; ok && info.Status != "" {
+			entry.Status = info.Status
+		}
+		entry.StatusChanged = entry.Status != flight.AddedStatus
+		entry.PriceChanges = priceChangesSince(flight, history)
+		entry.PriceChangeUSD = entry.PriceUSD - flight.AddedPriceUSD
+		if flight.AddedPriceUSD != 0 {
+			entry.PriceChangePercent = math.Round(float64(entry.PriceChangeUSD)/float64(flight.AddedPriceUSD)*10000) / 100
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// priceChangesSince returns the observations of the watched fare made after it was added
+// whose price differs from the one before. The history must be sorted oldest first.
+func priceChangesSince(flight models.WatchedFlight, history []models.PriceObservation) []models.PriceObservation {
+	changes := []models.PriceObservation{}
+	last := flight.AddedPriceUSD
+	for _, observation := range history {
+		if observation.Class != flight.Class || !observation.ObservedAt.After(flight.AddedAt) {
+			continue
+		}
+		if observation.PriceUSD != last {
+			changes = append(changes, observation)
+			last = observation.PriceUSD
+		}
+	}
+	return changes
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchlist(t *testing.T) {
+	// Flight data is dropped a while after departure, so the watched flight departs later
+	const flightID = "DL123-2030-04-28"
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	added := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	fare := models.Flight{ID: flightID, FlightNumber: "DL123", DepartureTime: "2030-04-28T22:30:00-04:00", Class: models.CabinEconomy, Status: models.StatusScheduled, PriceUSD: 50000}
+	require.NoError(t, SaveFlightState(ctx, rdb, fare))
+	require.NoError(t, RecordPrice(ctx, rdb, fare, added.Add(-time.Hour)))
+	require.NoError(t, WatchFlight(ctx, rdb, models.WatchedFlight{
+		ID:            NewID(),
+		Owner:         "alice",
+		FlightID:      flightID,
+		Class:         models.CabinEconomy,
+		DepartureTime: fare.DepartureTime,
+		AddedAt:       added,
+		AddedStatus:   models.StatusScheduled,
+		AddedPriceUSD: 50000,
+	}))
+	require.NoError(t, WatchFlight(ctx, rdb, models.WatchedFlight{ID: NewID(), Owner: "alice", FlightID: "AA1-2030-04-27", Class: models.CabinFirst, DepartureTime: "2030-04-27T08:00:00Z", AddedAt: added, AddedPriceUSD: 90000}))
+
+	// Crawls after the flight was added: same price, a drop, the same again, then a rise
+	for i, price := range []money.USD{50000, 45000, 45000, 47500} {
+		fare.PriceUSD = price
+		require.NoError(t, RecordPrice(ctx, rdb, fare, added.Add(time.Duration(i+1)*time.Hour)))
+	}
+	fare.Status = models.StatusDelayed
+	require.NoError(t, SaveFlightState(ctx, rdb, fare))
+
+	entries, err := Watchlist(ctx, rdb, "alice")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// In departure order, fares that are no longer stored included
+	assert.True(t, entries[0].Gone)
+	assert.Equal(t, money.USD(90000), entries[0].PriceUSD)
+	assert.Empty(t, entries[0].PriceChanges)
+
+	entry := entries[1]
+	assert.False(t, entry.Gone)
+	assert.Equal(t, models.StatusDelayed, entry.Status)
+	assert.True(t, entry.StatusChanged)
+	assert.Equal(t, money.USD(47500), entry.PriceUSD)
+	assert.Equal(t, money.USD(-2500), entry.PriceChangeUSD)
+	assert.Equal(t, -5.0, entry.PriceChangePercent)
+	if assert.Len(t, entry.PriceChanges, 2) {
+		assert.Equal(t, money.USD(45000), entry.PriceChanges[0].PriceUSD)
+		assert.Equal(t, money.USD(47500), entry.PriceChanges[1].PriceUSD)
+	}
+
+	// Watchlists are per user
+	entries, err = Watchlist(ctx, rdb, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.ErrorIs(t, UnwatchFlight(ctx, rdb, "bob", entry.ID), ErrNotFound)
+	require.NoError(t, UnwatchFlight(ctx, rdb, "alice", entry.ID))
+	watched, err := WatchedFlights(ctx, rdb, "alice")
+	require.NoError(t, err)
+	assert.Len(t, watched, 1)
+}
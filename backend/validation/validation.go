@@ -0,0 +1,296 @@
+// Package validation holds the data-quality rules applied to flights before they are stored.
+// Each rule has a severity: rejected flights are dead-lettered, warned flights are stored with
+// the warning attached. Admins can change the severity of the rules that are not required.
+package validation
+
+import (
+	"FlightAPI/airlines"
+	"FlightAPI/airports"
+	"FlightAPI/currency"
+	"FlightAPI/models"
+	"FlightAPI/store"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Severity is what happens to a flight that breaks a rule.
+type Severity string
+
+const (
+	Reject Severity = "reject" // The flight is not stored
+	Warn   Severity = "warn"   // The flight is stored with a warning
+	Off    Severity = "off"    // The rule is not checked
+)
+
+// ParseSeverity returns the severity with the given name.
+func ParseSeverity(value string) (Severity, bool) {
+	switch severity := Severity(strings.ToLower(strings.TrimSpace(value))); severity {
+	case Reject, Warn, Off:
+		return severity, true
+	}
+	return "", false
+}
+
+// Rule is one data-quality check.
+type Rule struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Severity        Severity `json:"severity"`
+	DefaultSeverity Severity `json:"defaultSeverity"`
+	Required        bool     `json:"required"` // Storage depends on it, so it always rejects
+	// check returns why the flight breaks the rule, or an empty string.
+	check func(flight models.Flight) string
+}
+
+// Violation is a rule broken by a flight.
+type Violation struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// Error lists the rejecting violations of a flight.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Rule + ": " + violation.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// RuleSet is an ordered set of rules with their configured severities.
+type RuleSet struct {
+	rules []Rule
+}
+
+// Defaults returns the rules with their default severities.
+func Defaults() RuleSet {
+	rules := []Rule{
+		{Name: "flight_number", Description: "The flight number is present", Severity: Reject, Required: true, check: checkFlightNumber},
+		{Name: "departure_time", Description: "The departure time is an RFC 3339 timestamp", Severity: Reject, Required: true, check: checkDepartureTime},
+		{Name: "arrival_time", Description: "The arrival time is an RFC 3339 timestamp", Severity: Reject, check: checkArrivalTime},
+		{Name: "arrival_after_departure", Description: "The flight arrives after it departs", Severity: Reject, check: checkArrivalAfterDeparture},
+		{Name: "departure_airport", Description: "The departure airport code is present", Severity: Reject, check: checkDepartureAirport},
+		{Name: "arrival_airport", Description: "The arrival airport code is present", Severity: Reject, check: checkArrivalAirport},
+		{Name: "distinct_airports", Description: "The departure and arrival airports differ", Severity: Reject, check: checkDistinctAirports},
+		{Name: "price_non_negative", Description: "The price is not negative", Severity: Reject, check: checkPriceNonNegative},
+		{Name: "price_present", Description: "The price is not zero", Severity: Warn, check: checkPricePresent},
+		{Name: "class_present", Description: "The fare class is present", Severity: Warn, check: checkClassPresent},
+		{Name: "known_class", Description: "The fare class is economy, premium economy, business or first", Severity: Warn, check: checkKnownClass},
+		{Name: "fare_details", Description: "Seats remaining, checked bags and fees are not negative", Severity: Reject, check: checkFareDetails},
+		{Name: "known_airports", Description: "Both airports are in the reference data", Severity: Warn, check: checkKnownAirports},
+		{Name: "known_airline", Description: "The carrier is in the airline registry", Severity: Warn, check: checkKnownAirline},
+	}
+	for i := range rules {
+		rules[i].DefaultSeverity = rules[i].Severity
+	}
+	return RuleSet{rules: rules}
+}
+
+// Load returns the default rules with the severities configured by the admins.
+func Load(ctx context.Context, rdb *redis.Client) (RuleSet, error) {
+	overrides, err := store.RuleSeverities(ctx, rdb)
+	if err != nil {
+		return RuleSet{}, err
+	}
+
+	set := Defaults()
+	for i, rule := range set.rules {
+		severity, ok := ParseSeverity(overrides[rule.Name])
+		if ok && !rule.Required {
+			set.rules[i].Severity = severity
+		}
+	}
+	return set, nil
+}
+
+// Rules returns the rules of the set.
+func (s RuleSet) Rules() []Rule {
+	return append([]Rule(nil), s.rules...)
+}
+
+// Lookup returns the rule with the given name.
+func (s RuleSet) Lookup(name string) (Rule, bool) {
+	for _, rule := range s.rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Check returns the rules a flight breaks, skipping the rules that are off.
+func (s RuleSet) Check(flight models.Flight) []Violation {
+	var violations []Violation
+	for _, rule := range s.rules {
+		if rule.Severity == Off {
+			continue
+		}
+		if message := rule.check(flight); message != "" {
+			violations = append(violations, Violation{Rule: rule.Name, Severity: rule.Severity, Message: message})
+		}
+	}
+	return violations
+}
+
+// Apply checks a flight and attaches the warnings to it. It returns an *Error listing
+// the rejecting violations if the flight must not be stored.
+func (s RuleSet) Apply(flight *models.Flight) ([]Violation, error) {
+	violations := s.Check(*flight)
+
+	var rejected []Violation
+	flight.Warnings = nil
+	for _, violation := range violations {
+		if violation.Severity == Reject {
+			rejected = append(rejected, violation)
+			continue
+		}
+		flight.Warnings = append(flight.Warnings, models.QualityWarning{Rule: violation.Rule, Message: violation.Message})
+	}
+
+	if len(rejected) > 0 {
+		return violations, &Error{Violations: rejected}
+	}
+	return violations, nil
+}
+
+func checkFlightNumber(flight models.Flight) string {
+	if strings.TrimSpace(flight.FlightNumber) == "" {
+		return "missing flight number"
+	}
+	return ""
+}
+
+func checkDepartureTime(flight models.Flight) string {
+	if _, err := time.Parse(time.RFC3339, flight.DepartureTime); err != nil {
+		return fmt.Sprintf("invalid departure time %q", flight.DepartureTime)
+	}
+	return ""
+}
+
+func checkArrivalTime(flight models.Flight) string {
+	if _, err := time.Parse(time.RFC3339, flight.ArrivalTime); err != nil {
+		return fmt.Sprintf("invalid arrival time %q", flight.ArrivalTime)
+	}
+	return ""
+}
+
+func checkArrivalAfterDeparture(flight models.Flight) string {
+	departure, err := time.Parse(time.RFC3339, flight.DepartureTime)
+	if err != nil {
+		return ""
+	}
+	arrival, err := time.Parse(time.RFC3339, flight.ArrivalTime)
+	if err != nil {
+		return ""
+	}
+	if !arrival.After(departure) {
+		return fmt.Sprintf("arrival %s is not after departure %s", flight.ArrivalTime, flight.DepartureTime)
+	}
+	return ""
+}
+
+func checkDepartureAirport(flight models.Flight) string {
+	if strings.TrimSpace(flight.DepartureAirport.Code) == "" {
+		return "missing departure airport code"
+	}
+	return ""
+}
+
+func checkArrivalAirport(flight models.Flight) string {
+	if strings.TrimSpace(flight.ArrivalAirport.Code) == "" {
+		return "missing arrival airport code"
+	}
+	return ""
+}
+
+func checkDistinctAirports(flight models.Flight) string {
+	if flight.DepartureAirport.Code != "" && strings.EqualFold(flight.DepartureAirport.Code, flight.ArrivalAirport.Code) {
+		return fmt.Sprintf("departure and arrival airports are both %s", flight.DepartureAirport.Code)
+	}
+	return ""
+}
+
+func checkPriceNonNegative(flight models.Flight) string {
+	if flight.Price.IsNegative() {
+		return "negative price " + currency.Format(flight.Price)
+	}
+	return ""
+}
+
+func checkPricePresent(flight models.Flight) string {
+	if flight.Price.IsZero() {
+		return "price is zero"
+	}
+	return ""
+}
+
+func checkClassPresent(flight models.Flight) string {
+	if strings.TrimSpace(string(flight.Class)) == "" {
+		return "missing fare class"
+	}
+	return ""
+}
+
+func checkKnownClass(flight models.Flight) string {
+	if flight.Class == "" {
+		return ""
+	}
+	if _, ok := models.ParseCabinClass(string(flight.Class)); !ok {
+		return fmt.Sprintf("unknown fare class %q", flight.Class)
+	}
+	return ""
+}
+
+func checkFareDetails(flight models.Flight) string {
+	var problems []string
+	if flight.SeatsRemaining != nil && *flight.SeatsRemaining < 0 {
+		problems = append(problems, fmt.Sprintf("%d seats remaining", *flight.SeatsRemaining))
+	}
+	if flight.Baggage != nil && (flight.Baggage.CheckedBags < 0 || flight.Baggage.CheckedWeightKg < 0) {
+		problems = append(problems, "negative baggage allowance")
+	}
+	if rules := flight.FareRules; rules != nil {
+		if rules.ChangeFee != nil && *rules.ChangeFee < 0 {
+			problems = append(problems, "negative change fee")
+		}
+		if rules.RefundFee != nil && *rules.RefundFee < 0 {
+			problems = append(problems, "negative refund fee")
+		}
+	}
+	return strings.Join(problems, ", ")
+}
+
+func checkKnownAirports(flight models.Flight) string {
+	var unknown []string
+	for _, code := range []string{flight.DepartureAirport.Code, flight.ArrivalAirport.Code} {
+		if code == "" {
+			continue
+		}
+		if _, ok := airports.Default().Lookup(code); !ok {
+			unknown = append(unknown, code)
+		}
+	}
+	if len(unknown) > 0 {
+		return "unknown airports " + strings.Join(unknown, ", ")
+	}
+	return ""
+}
+
+func checkKnownAirline(flight models.Flight) string {
+	if flight.CarrierCode == "" {
+		return fmt.Sprintf("no carrier code in flight number %q", flight.FlightNumber)
+	}
+	if _, ok := airlines.Default().Lookup(flight.CarrierCode); !ok {
+		return "unknown carrier " + flight.CarrierCode
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"FlightAPI/models"
+	"FlightAPI/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validFlight() models.Flight {
+	return models.Flight{
+		FlightNumber:     "DL123",
+		CarrierCode:      "DL",
+		DepartureAirport: models.Airport{Code: "ATL"},
+		ArrivalAirport:   models.Airport{Code: "JFK"},
+		DepartureTime:    "2025-04-28T10:00:00Z",
+		ArrivalTime:      "2025-04-28T12:15:00Z",
+		Class:            "Economy",
+		PriceUSD:         25000,
+		Price:            money.New(25000, "USD"),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(flight *models.Flight)
+		expectedRules []string
+	}{
+		{name: "valid flight", modify: func(flight *models.Flight) {}},
+		{name: "negative price", modify: func(flight *models.Flight) { flight.Price = money.New(-1000, "USD") }, expectedRules: []string{"price_non_negative"}},
+		{name: "zero price", modify: func(flight *models.Flight) { flight.Price = money.New(0, "USD") }, expectedRules: []string{"price_present"}},
+		{
+			name:          "arrival before departure",
+			modify:        func(flight *models.Flight) { flight.ArrivalTime = "2025-04-28T09:00:00Z" },
+			expectedRules: []string{"arrival_after_departure"},
+		},
+		{
+			name:          "malformed departure time",
+			modify:        func(flight *models.Flight) { flight.DepartureTime = "28/04/2025 10:00" },
+			expectedRules: []string{"departure_time"},
+		},
+		{
+			name:          "empty airport code",
+			modify:        func(flight *models.Flight) { flight.ArrivalAirport.Code = "" },
+			expectedRules: []string{"arrival_airport"},
+		},
+		{
+			name:          "identical airports",
+			modify:        func(flight *models.Flight) { flight.ArrivalAirport.Code = "ATL" },
+			expectedRules: []string{"distinct_airports"},
+		},
+		{
+			name:          "unknown airport and carrier",
+			modify:        func(flight *models.Flight) { flight.DepartureAirport.Code = "XYZ"; flight.CarrierCode = "Q9" },
+			expectedRules: []string{"known_airports", "known_airline"},
+		},
+		{
+			name:          "unknown class",
+			modify:        func(flight *models.Flight) { flight.Class = "Sleeper" },
+			expectedRules: []string{"known_class"},
+		},
+		{
+			name: "negative fare details",
+			modify: func(flight *models.Flight) {
+				seats, fee := -1, int64(-5000)
+				flight.SeatsRemaining = &seats
+				flight.FareRules = &models.FareRules{Changeable: true, ChangeFee: &fee}
+			},
+			expectedRules: []string{"fare_details"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flight := validFlight()
+			tt.modify(&flight)
+
+			var rules []string
+			for _, violation := range Defaults().Check(flight) {
+				rules = append(rules, violation.Rule)
+			}
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	rules := Defaults()
+
+	warned := validFlight()
+	warned.Price = money.New(0, "EUR")
+	_, err := rules.Apply(&warned)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.QualityWarning{{Rule: "price_present", Message: "price is zero"}}, warned.Warnings)
+
+	rejected := validFlight()
+	rejected.Price = money.New(-1, "USD")
+	violations, err := rules.Apply(&rejected)
+	assert.Len(t, violations, 1)
+	var validationErr *Error
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "price_non_negative", validationErr.Violations[0].Rule)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, ok := ParseSeverity(" Warn ")
+	assert.True(t, ok)
+	assert.Equal(t, Warn, severity)
+
+	_, ok = ParseSeverity("ignore")
+	assert.False(t, ok)
+}